@@ -18,6 +18,7 @@ func newAuthCmd(flags *rootFlags) *cobra.Command {
 	var follow bool
 	var idleExit time.Duration
 	var downloadMedia bool
+	var skipViewOnce bool
 
 	cmd := &cobra.Command{
 		Use:   "auth",
@@ -42,6 +43,7 @@ func newAuthCmd(flags *rootFlags) *cobra.Command {
 				Mode:            mode,
 				AllowQR:         true,
 				DownloadMedia:   downloadMedia,
+				SkipViewOnce:    skipViewOnce,
 				RefreshContacts: true,
 				RefreshGroups:   true,
 				IdleExit:        idleExit,
@@ -70,6 +72,7 @@ func newAuthCmd(flags *rootFlags) *cobra.Command {
 	cmd.Flags().BoolVar(&follow, "follow", false, "keep syncing after auth")
 	cmd.Flags().DurationVar(&idleExit, "idle-exit", 30*time.Second, "exit after being idle (bootstrap/once modes)")
 	cmd.Flags().BoolVar(&downloadMedia, "download-media", false, "download media in the background during sync")
+	cmd.Flags().BoolVar(&skipViewOnce, "skip-view-once", false, "never download view-once media (with --download-media)")
 
 	cmd.AddCommand(newAuthStatusCmd(flags))
 	cmd.AddCommand(newAuthLogoutCmd(flags))
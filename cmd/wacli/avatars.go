@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	appPkg "github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// newAvatarCmd builds `contacts avatar` (group=false) and `groups avatar`
+// (group=true). The contact variant also refreshes the "about" text.
+func newAvatarCmd(flags *rootFlags, group bool) *cobra.Command {
+	var jidStr string
+	var output string
+	var force bool
+	short := "Download a contact's profile picture and about text"
+	if group {
+		short = "Download a group's photo"
+	}
+	cmd := &cobra.Command{
+		Use:   "avatar",
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(jidStr) == "" {
+				return fmt.Errorf("--jid is required")
+			}
+			var jid types.JID
+			var err error
+			if group {
+				jid, err = types.ParseJID(jidStr)
+			} else {
+				jid, err = wa.ParseUserOrJID(jidStr)
+			}
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+
+			res, err := a.FetchAvatar(ctx, jid, force)
+			if err != nil {
+				return err
+			}
+			about := ""
+			if !group {
+				abouts, err := a.RefreshAbout(ctx, []types.JID{jid})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to fetch about text: %v\n", err)
+				}
+				about = abouts[jid]
+			}
+
+			if strings.TrimSpace(output) != "" && res.Path != "" {
+				dst, err := copyAvatar(res.Path, output)
+				if err != nil {
+					return err
+				}
+				res.Path = dst
+			}
+
+			if flags.asJSON {
+				if group {
+					return out.WriteJSON(os.Stdout, res)
+				}
+				return out.WriteJSON(os.Stdout, struct {
+					appPkg.AvatarResult
+					About string `json:"about"`
+				}{res, about})
+			}
+
+			switch res.Status {
+			case "not_set":
+				fmt.Fprintln(os.Stdout, "No profile picture set.")
+			case "hidden":
+				fmt.Fprintln(os.Stdout, "Profile picture is hidden by privacy settings.")
+			default:
+				fmt.Fprintf(os.Stdout, "Avatar: %s (%s)\n", res.Path, res.Status)
+			}
+			if about != "" {
+				fmt.Fprintf(os.Stdout, "About: %s\n", about)
+			}
+			return nil
+		},
+	}
+	if group {
+		cmd.Flags().StringVar(&jidStr, "jid", "", "group JID (…@g.us)")
+	} else {
+		cmd.Flags().StringVar(&jidStr, "jid", "", "contact JID or phone number")
+	}
+	cmd.Flags().StringVar(&output, "output", "", "also copy the picture to this file or directory")
+	cmd.Flags().BoolVar(&force, "force", false, "download even if the cached picture is current")
+	return cmd
+}
+
+func copyAvatar(src, output string) (string, error) {
+	dst := output
+	if st, err := os.Stat(dst); (err == nil && st.IsDir()) || strings.HasSuffix(dst, string(os.PathSeparator)) {
+		dst = filepath.Join(dst, filepath.Base(src))
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return "", err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, in); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if abs, err := filepath.Abs(dst); err == nil {
+		dst = abs
+	}
+	return dst, nil
+}
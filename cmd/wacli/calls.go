@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+)
+
+func newCallsCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "calls",
+		Short: "Call log (recorded during sync)",
+	}
+	cmd.AddCommand(newCallsListCmd(flags))
+	return cmd
+}
+
+func newCallsListCmd(flags *rootFlags) *cobra.Command {
+	var peer string
+	var direction string
+	var outcome string
+	var callType string
+	var limit int
+	var afterStr string
+	var beforeStr string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List voice and video calls",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p := store.ListCallsParams{Limit: limit}
+			switch direction = strings.ToLower(strings.TrimSpace(direction)); direction {
+			case "", "incoming", "outgoing":
+				p.Direction = direction
+			default:
+				return fmt.Errorf("invalid --direction %q (incoming|outgoing)", direction)
+			}
+			switch outcome = strings.ToLower(strings.TrimSpace(outcome)); outcome {
+			case "", store.CallRinging, store.CallAnswered, store.CallMissed, store.CallRejected, store.CallAutoRejected:
+				p.Outcome = outcome
+			default:
+				return fmt.Errorf("invalid --outcome %q (answered|missed|rejected|auto-rejected|ringing)", outcome)
+			}
+			switch strings.ToLower(strings.TrimSpace(callType)) {
+			case "":
+			case "voice", "audio":
+				video := false
+				p.Video = &video
+			case "video":
+				video := true
+				p.Video = &video
+			default:
+				return fmt.Errorf("invalid --type %q (voice|video)", callType)
+			}
+			if afterStr != "" {
+				t, err := parseTime(afterStr)
+				if err != nil {
+					return err
+				}
+				p.After = &t
+			}
+			if beforeStr != "" {
+				t, err := parseTime(beforeStr)
+				if err != nil {
+					return err
+				}
+				p.Before = &t
+			}
+			if peer != "" {
+				jid, err := wa.ParseUserOrJID(peer)
+				if err != nil {
+					return err
+				}
+				p.Peer = jid.String()
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			calls, err := a.DB().ListCalls(p)
+			if err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"calls": calls})
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tPEER\tDIRECTION\tTYPE\tOUTCOME\tDURATION")
+			for _, c := range calls {
+				label := c.PeerName
+				if label == "" {
+					label = c.PeerJID
+				}
+				if c.GroupJID != "" {
+					label += " (group)"
+				}
+				kind := "voice"
+				if c.Video {
+					kind = "video"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					c.StartedAt.Local().Format("2006-01-02 15:04:05"),
+					truncate(label, 28),
+					c.Direction,
+					kind,
+					c.Outcome,
+					callDuration(c),
+				)
+			}
+			_ = w.Flush()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&peer, "peer", "", "only calls with this phone number or JID")
+	cmd.Flags().StringVar(&direction, "direction", "", "incoming|outgoing")
+	cmd.Flags().StringVar(&outcome, "outcome", "", "answered|missed|rejected|auto-rejected|ringing")
+	cmd.Flags().StringVar(&callType, "type", "", "voice|video")
+	cmd.Flags().IntVar(&limit, "limit", 50, "limit results")
+	cmd.Flags().StringVar(&afterStr, "after", "", "only calls after time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&beforeStr, "before", "", "only calls before time (RFC3339 or YYYY-MM-DD)")
+	return cmd
+}
+
+// callDuration is the talk time of an answered call that has ended.
+func callDuration(c store.Call) string {
+	if c.AcceptedAt.IsZero() || c.EndedAt.IsZero() || c.EndedAt.Before(c.AcceptedAt) {
+		return ""
+	}
+	return c.EndedAt.Sub(c.AcceptedAt).Round(time.Second).String()
+}
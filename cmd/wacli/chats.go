@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
 	"github.com/steipete/wacli/internal/out"
 )
 
@@ -89,6 +90,9 @@ func newChatsShowCmd(flags *rootFlags) *cobra.Command {
 				return out.WriteJSON(os.Stdout, c)
 			}
 			fmt.Fprintf(os.Stdout, "JID: %s\nKind: %s\nName: %s\nLast: %s\n", c.JID, c.Kind, c.Name, c.LastMessageTS.Local().Format(time.RFC3339))
+			if c.EphemeralExpiration > 0 {
+				fmt.Fprintf(os.Stdout, "Disappearing messages: %s\n", app.FormatTimer(c.EphemeralExpiration))
+			}
 			return nil
 		},
 	}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func newCommunitiesCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "communities",
+		Short: "Community (parent group) management",
+	}
+	cmd.AddCommand(newCommunitiesListCmd(flags))
+	cmd.AddCommand(newCommunitiesShowCmd(flags))
+	cmd.AddCommand(newCommunitiesLinkCmd(flags, true))
+	cmd.AddCommand(newCommunitiesLinkCmd(flags, false))
+	cmd.AddCommand(newCommunitiesCreateCmd(flags))
+	return cmd
+}
+
+func newCommunitiesListCmd(flags *rootFlags) *cobra.Command {
+	var query string
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List known communities (from local DB; run groups refresh to populate)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			cs, err := a.DB().ListCommunities(query, limit)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, cs)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tJID\tGROUPS\tCREATED")
+			for _, c := range cs {
+				name := c.Name
+				if name == "" {
+					name = c.JID
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", truncate(name, 40), c.JID, c.SubGroups, c.CreatedAt.Local().Format("2006-01-02"))
+			}
+			_ = w.Flush()
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&query, "query", "", "search query")
+	cmd.Flags().IntVar(&limit, "limit", 50, "limit")
+	return cmd
+}
+
+func newCommunitiesShowCmd(flags *rootFlags) *cobra.Command {
+	var jidStr string
+	var live bool
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show a community and its linked groups",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(jidStr) == "" {
+				return fmt.Errorf("--jid is required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, live, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			pjid, err := types.ParseJID(jidStr)
+			if err != nil {
+				return err
+			}
+
+			if live {
+				if err := a.EnsureAuthed(); err != nil {
+					return err
+				}
+				if err := a.Connect(ctx, false, nil); err != nil {
+					return err
+				}
+				if info, err := a.WA().GetGroupInfo(ctx, pjid); err == nil && info != nil {
+					_ = persistGroupInfo(ctx, a, info)
+				}
+				subs, err := a.WA().GetSubGroups(ctx, pjid)
+				if err != nil {
+					return err
+				}
+				if err := persistSubGroups(a.DB(), pjid, subs); err != nil {
+					return err
+				}
+			}
+
+			parent, err := a.DB().GetGroup(pjid.String())
+			if store.IsNotFound(err) {
+				return fmt.Errorf("community %s not found (try --live)", pjid)
+			}
+			if err != nil {
+				return err
+			}
+			subs, err := a.DB().ListSubGroups(pjid.String())
+			if err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{
+					"community": parent,
+					"groups":    subs,
+				})
+			}
+
+			fmt.Fprintf(os.Stdout, "JID: %s\nName: %s\nOwner: %s\nCreated: %s\nGroups: %d\n",
+				parent.JID,
+				parent.Name,
+				parent.OwnerJID,
+				parent.CreatedAt.Local().Format(time.RFC3339),
+				len(subs),
+			)
+			if len(subs) == 0 {
+				return nil
+			}
+			fmt.Fprintln(os.Stdout)
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tJID")
+			for _, g := range subs {
+				name := g.Name
+				if name == "" {
+					name = g.JID
+				}
+				fmt.Fprintf(w, "%s\t%s\n", truncate(name, 40), g.JID)
+			}
+			_ = w.Flush()
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&jidStr, "jid", "", "community JID (…@g.us)")
+	cmd.Flags().BoolVar(&live, "live", false, "fetch linked groups from WhatsApp before showing")
+	return cmd
+}
+
+func newCommunitiesLinkCmd(flags *rootFlags, link bool) *cobra.Command {
+	var parentStr string
+	var groupStr string
+	use, short := "link", "Link an existing group into a community"
+	if !link {
+		use, short = "unlink", "Unlink a group from a community"
+	}
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(parentStr) == "" || strings.TrimSpace(groupStr) == "" {
+				return fmt.Errorf("--parent and --group are required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+
+			pjid, err := types.ParseJID(parentStr)
+			if err != nil {
+				return err
+			}
+			gjid, err := types.ParseJID(groupStr)
+			if err != nil {
+				return err
+			}
+
+			if link {
+				err = a.WA().LinkGroup(ctx, pjid, gjid)
+			} else {
+				err = a.WA().UnlinkGroup(ctx, pjid, gjid)
+			}
+			if err != nil {
+				return err
+			}
+
+			newParent := ""
+			if link {
+				newParent = pjid.String()
+			}
+			_ = a.DB().SetGroupParent(gjid.String(), newParent)
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{
+					"parent": pjid.String(),
+					"group":  gjid.String(),
+					"linked": link,
+				})
+			}
+			fmt.Fprintln(os.Stdout, "OK")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&parentStr, "parent", "", "community JID (…@g.us)")
+	cmd.Flags().StringVar(&groupStr, "group", "", "group JID (…@g.us)")
+	return cmd
+}
+
+func newCommunitiesCreateCmd(flags *rootFlags) *cobra.Command {
+	var name string
+	var users []string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a new community",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("--name is required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+
+			var jids []types.JID
+			for _, u := range users {
+				j, err := wa.ParseUserOrJID(u)
+				if err != nil {
+					return err
+				}
+				jids = append(jids, j)
+			}
+
+			req := whatsmeow.ReqCreateGroup{Name: name, Participants: jids}
+			req.IsParent = true
+			info, err := a.WA().CreateGroup(ctx, req)
+			if err != nil {
+				return err
+			}
+			if info == nil {
+				return fmt.Errorf("create community: WhatsApp returned no group info")
+			}
+			_ = persistGroupInfo(ctx, a, info)
+			_ = a.DB().UpsertChat(info.JID.String(), "group", info.GroupName.Name, time.Now())
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, info)
+			}
+			fmt.Fprintf(os.Stdout, "Created: %s\n", info.JID.String())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "community name")
+	cmd.Flags().StringSliceVar(&users, "user", nil, "initial member phone number or JID (repeatable)")
+	return cmd
+}
+
+func persistSubGroups(db *store.DB, parent types.JID, subs []*types.GroupLinkTarget) error {
+	for _, sg := range subs {
+		if sg == nil {
+			continue
+		}
+		if err := db.UpsertGroup(sg.JID.String(), sg.GroupName.Name, "", time.Time{}); err != nil {
+			return err
+		}
+		if err := db.SetGroupParent(sg.JID.String(), parent.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/store"
 )
 
 func newContactsCmd(flags *rootFlags) *cobra.Command {
@@ -19,17 +20,27 @@ func newContactsCmd(flags *rootFlags) *cobra.Command {
 	cmd.AddCommand(newContactsSearchCmd(flags))
 	cmd.AddCommand(newContactsShowCmd(flags))
 	cmd.AddCommand(newContactsRefreshCmd(flags))
+	cmd.AddCommand(newContactsCheckCmd(flags))
 	cmd.AddCommand(newContactsAliasCmd(flags))
 	cmd.AddCommand(newContactsTagsCmd(flags))
+	cmd.AddCommand(newContactsNotesCmd(flags))
+	cmd.AddCommand(newContactsExportCmd(flags))
+	cmd.AddCommand(newContactsImportCmd(flags))
+	cmd.AddCommand(newAvatarCmd(flags, false))
+	cmd.AddCommand(newContactsBlockCmd(flags, true))
+	cmd.AddCommand(newContactsBlockCmd(flags, false))
+	cmd.AddCommand(newContactsBlocklistCmd(flags))
+	cmd.AddCommand(newContactsBusinessCmd(flags))
 	return cmd
 }
 
 func newContactsSearchCmd(flags *rootFlags) *cobra.Command {
 	var limit int
+	var business bool
 	cmd := &cobra.Command{
-		Use:   "search <query>",
+		Use:   "search [query]",
 		Short: "Search contacts (from synced metadata)",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, cancel := withTimeout(context.Background(), flags)
 			defer cancel()
@@ -40,7 +51,14 @@ func newContactsSearchCmd(flags *rootFlags) *cobra.Command {
 			}
 			defer closeApp(a, lk)
 
-			cs, err := a.DB().SearchContacts(args[0], limit)
+			query := ""
+			if len(args) > 0 {
+				query = args[0]
+			}
+			if query == "" && !business {
+				return fmt.Errorf("query is required (or use --business)")
+			}
+			cs, err := a.DB().SearchContacts(store.SearchContactsParams{Query: query, Limit: limit, BusinessOnly: business})
 			if err != nil {
 				return err
 			}
@@ -50,13 +68,18 @@ func newContactsSearchCmd(flags *rootFlags) *cobra.Command {
 			}
 
 			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
-			fmt.Fprintln(w, "ALIAS\tNAME\tPHONE\tJID")
+			fmt.Fprintln(w, "ALIAS\tNAME\tPHONE\tJID\tBLOCKED")
 			for _, c := range cs {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				blocked := ""
+				if c.Blocked {
+					blocked = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 					truncate(c.Alias, 18),
 					truncate(c.Name, 24),
 					truncate(c.Phone, 14),
 					c.JID,
+					blocked,
 				)
 			}
 			_ = w.Flush()
@@ -64,6 +87,7 @@ func newContactsSearchCmd(flags *rootFlags) *cobra.Command {
 		},
 	}
 	cmd.Flags().IntVar(&limit, "limit", 50, "limit results")
+	cmd.Flags().BoolVar(&business, "business", false, "only business accounts")
 	return cmd
 }
 
@@ -101,12 +125,36 @@ func newContactsShowCmd(flags *rootFlags) *cobra.Command {
 			if c.Name != "" {
 				fmt.Fprintf(os.Stdout, "Name: %s\n", c.Name)
 			}
+			if c.FullName != "" && c.FullName != c.Name {
+				fmt.Fprintf(os.Stdout, "Full name: %s\n", c.FullName)
+			}
+			if c.FirstName != "" {
+				fmt.Fprintf(os.Stdout, "First name: %s\n", c.FirstName)
+			}
+			if c.PushName != "" {
+				fmt.Fprintf(os.Stdout, "Push name: %s\n", c.PushName)
+			}
+			if c.BusinessName != "" {
+				fmt.Fprintf(os.Stdout, "Business name: %s\n", c.BusinessName)
+			}
 			if c.Alias != "" {
 				fmt.Fprintf(os.Stdout, "Alias: %s\n", c.Alias)
 			}
+			if c.Blocked {
+				fmt.Fprintln(os.Stdout, "Blocked: yes")
+			}
 			if len(c.Tags) > 0 {
 				fmt.Fprintf(os.Stdout, "Tags: %s\n", strings.Join(c.Tags, ", "))
 			}
+			if c.About != "" {
+				fmt.Fprintf(os.Stdout, "About: %s\n", c.About)
+			}
+			if c.AvatarPath != "" {
+				fmt.Fprintf(os.Stdout, "Avatar: %s\n", c.AvatarPath)
+			}
+			if c.Notes != "" {
+				fmt.Fprintf(os.Stdout, "Notes:\n%s\n", c.Notes)
+			}
 			return nil
 		},
 	}
@@ -283,3 +331,93 @@ func newContactsTagsCmd(flags *rootFlags) *cobra.Command {
 	_ = cmd.PersistentFlags().String("tag", "", "tag")
 	return cmd
 }
+
+func newContactsNotesCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notes",
+		Short: "Manage local contact notes",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set",
+		Short: "Set notes (replaces existing notes)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jid, _ := cmd.Flags().GetString("jid")
+			notes, _ := cmd.Flags().GetString("notes")
+			if strings.TrimSpace(jid) == "" || strings.TrimSpace(notes) == "" {
+				return fmt.Errorf("--jid and --notes are required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+			if err := a.DB().SetNotes(jid, notes); err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"jid": jid, "notes": strings.TrimSpace(notes)})
+			}
+			fmt.Fprintln(os.Stdout, "OK")
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Show notes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jid, _ := cmd.Flags().GetString("jid")
+			if strings.TrimSpace(jid) == "" {
+				return fmt.Errorf("--jid is required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+			notes, err := a.DB().GetNotes(jid)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"jid": jid, "notes": notes})
+			}
+			if notes != "" {
+				fmt.Fprintln(os.Stdout, notes)
+			}
+			return nil
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Clear notes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jid, _ := cmd.Flags().GetString("jid")
+			if strings.TrimSpace(jid) == "" {
+				return fmt.Errorf("--jid is required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+			if err := a.DB().ClearNotes(jid); err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"jid": jid, "cleared": true})
+			}
+			fmt.Fprintln(os.Stdout, "OK")
+			return nil
+		},
+	})
+
+	_ = cmd.PersistentFlags().String("jid", "", "contact JID")
+	_ = cmd.PersistentFlags().String("notes", "", "notes text")
+	return cmd
+}
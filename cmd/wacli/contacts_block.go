@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/wa"
+)
+
+func newContactsBlockCmd(flags *rootFlags, block bool) *cobra.Command {
+	var jidStr string
+	use, short := "block", "Block a contact on WhatsApp"
+	if !block {
+		use, short = "unblock", "Unblock a contact on WhatsApp"
+	}
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(jidStr) == "" {
+				return fmt.Errorf("--jid is required")
+			}
+			jid, err := wa.ParseUserOrJID(jidStr)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+			if err := a.SetBlocked(ctx, jid, block); err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"jid": jid.String(), "blocked": block})
+			}
+			fmt.Fprintln(os.Stdout, "OK")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&jidStr, "jid", "", "contact JID or phone number")
+	return cmd
+}
+
+func newContactsBlocklistCmd(flags *rootFlags) *cobra.Command {
+	var local bool
+	cmd := &cobra.Command{
+		Use:   "blocklist",
+		Short: "List blocked contacts (fetched from WhatsApp and stored locally)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, !local, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if !local {
+				if err := a.EnsureAuthed(); err != nil {
+					return err
+				}
+				if err := a.Connect(ctx, false, nil); err != nil {
+					return err
+				}
+				if _, err := a.RefreshBlocklist(ctx); err != nil {
+					return err
+				}
+			}
+
+			list, err := a.DB().ListBlocked()
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, list)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ALIAS\tNAME\tPHONE\tJID\tSINCE")
+			for _, b := range list {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					truncate(b.Alias, 18),
+					truncate(b.Name, 24),
+					truncate(b.Phone, 14),
+					b.JID,
+					b.BlockedAt.Local().Format("2006-01-02"),
+				)
+			}
+			_ = w.Flush()
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&local, "local", false, "show the locally stored blocklist without connecting")
+	return cmd
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+)
+
+func newContactsBusinessCmd(flags *rootFlags) *cobra.Command {
+	var jidStr string
+	var maxAge time.Duration
+	cmd := &cobra.Command{
+		Use:   "business",
+		Short: "Show a contact's WhatsApp Business profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(jidStr) == "" {
+				return fmt.Errorf("--jid is required")
+			}
+			jid, err := wa.ParseUserOrJID(jidStr)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			p, cached, err := a.FetchBusinessProfile(ctx, jid, maxAge)
+			if err != nil {
+				return err
+			}
+			return printBusinessProfile(flags, p, cached)
+		},
+	}
+	cmd.Flags().StringVar(&jidStr, "jid", "", "contact JID or phone number")
+	cmd.Flags().DurationVar(&maxAge, "max-age", 24*time.Hour, "reuse the cached profile if newer than this (0 to always fetch)")
+	return cmd
+}
+
+func printBusinessProfile(flags *rootFlags, p store.BusinessProfile, cached bool) error {
+	if flags.asJSON {
+		return out.WriteJSON(os.Stdout, map[string]any{"profile": p, "cached": cached})
+	}
+	fmt.Fprintf(os.Stdout, "JID: %s\n", p.JID)
+	for _, f := range []struct{ label, value string }{
+		{"Name", p.Name},
+		{"Description", p.Description},
+		{"Category", strings.Join(p.Categories, ", ")},
+		{"Address", p.Address},
+		{"Email", p.Email},
+		{"Website", p.Website},
+	} {
+		if f.value != "" {
+			fmt.Fprintf(os.Stdout, "%s: %s\n", f.label, f.value)
+		}
+	}
+	if len(p.Hours) > 0 {
+		tz := ""
+		if p.HoursTimeZone != "" {
+			tz = " (" + p.HoursTimeZone + ")"
+		}
+		fmt.Fprintf(os.Stdout, "Hours%s:\n", tz)
+		for _, h := range p.Hours {
+			fmt.Fprintf(os.Stdout, "  %s  %s\n", h.Day, formatBusinessHours(h))
+		}
+	}
+	fmt.Fprintf(os.Stdout, "Fetched: %s\n", p.FetchedAt.Local().Format(time.RFC3339))
+	return nil
+}
+
+func formatBusinessHours(h store.BusinessHours) string {
+	switch h.Mode {
+	case "open_24h":
+		return "open 24h"
+	case "appointment_only":
+		return "by appointment"
+	case "specific_hours":
+		return minutesOfDay(h.Open) + "–" + minutesOfDay(h.Close)
+	}
+	return h.Mode
+}
+
+// minutesOfDay renders WhatsApp's minutes-after-midnight values as HH:MM.
+func minutesOfDay(s string) string {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return s
+	}
+	return fmt.Sprintf("%02d:%02d", n/60, n%60)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	appPkg "github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/out"
+)
+
+func newContactsCheckCmd(flags *rootFlags) *cobra.Command {
+	var file string
+	var maxAge time.Duration
+	cmd := &cobra.Command{
+		Use:   "check [number...]",
+		Short: "Check whether phone numbers are registered on WhatsApp",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			numbers := append([]string{}, args...)
+			if strings.TrimSpace(file) != "" {
+				fromFile, err := readListFile(file)
+				if err != nil {
+					return err
+				}
+				numbers = append(numbers, fromFile...)
+			}
+			if len(numbers) == 0 {
+				return fmt.Errorf("at least one number (or --file) is required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			results, err := a.CheckNumbers(ctx, numbers, appPkg.NumberCheckOptions{
+				MaxAge: maxAge,
+				Connect: func(ctx context.Context) error {
+					if err := a.EnsureAuthed(); err != nil {
+						return err
+					}
+					return a.Connect(ctx, false, nil)
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, results)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "NUMBER\tREGISTERED\tJID\tBUSINESS")
+			for _, r := range results {
+				if r.Error != "" {
+					fmt.Fprintf(w, "%s\terror\t%s\t\n", r.Input, truncate(r.Error, 40))
+					continue
+				}
+				business := ""
+				if r.Business {
+					business = "yes"
+					if r.BusinessName != "" {
+						business = truncate(r.BusinessName, 24)
+					}
+				}
+				fmt.Fprintf(w, "+%s\t%s\t%s\t%s\n", r.Phone, yesNo(r.Registered), r.JID, business)
+			}
+			_ = w.Flush()
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "file with one phone number per line (or CSV, first column)")
+	cmd.Flags().DurationVar(&maxAge, "max-age", 24*time.Hour, "reuse cached results newer than this (0 to always query WhatsApp)")
+	return cmd
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
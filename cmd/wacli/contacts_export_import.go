@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	appPkg "github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/vcard"
+)
+
+func newContactsExportCmd(flags *rootFlags) *cobra.Command {
+	var format string
+	var output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export local contacts with aliases, notes and tags",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(strings.TrimSpace(format))
+			switch format {
+			case "vcf", "csv", "json":
+			default:
+				return fmt.Errorf("unsupported --format %q (use vcf, csv or json)", format)
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			cs, err := a.DB().ListContacts()
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			toFile := strings.TrimSpace(output) != "" && output != "-"
+			if toFile {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			switch format {
+			case "json":
+				if err := out.WriteJSON(w, cs); err != nil {
+					return err
+				}
+			case "csv":
+				cw := csv.NewWriter(w)
+				_ = cw.Write([]string{"jid", "phone", "name", "alias", "notes", "tags"})
+				for _, c := range cs {
+					_ = cw.Write([]string{c.JID, c.Phone, c.Name, c.Alias, c.Notes, strings.Join(c.Tags, ";")})
+				}
+				cw.Flush()
+				if err := cw.Error(); err != nil {
+					return err
+				}
+			case "vcf":
+				for _, c := range cs {
+					if err := vcard.Write(w, appPkg.ContactCard(c)); err != nil {
+						return err
+					}
+				}
+			}
+
+			if toFile {
+				fmt.Fprintf(os.Stderr, "Exported %d contacts to %s\n", len(cs), output)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "vcf", "output format: vcf|csv|json")
+	cmd.Flags().StringVar(&output, "output", "", "write to file instead of stdout")
+	return cmd
+}
+
+func newContactsImportCmd(flags *rootFlags) *cobra.Command {
+	var format string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "import <file.vcf|file.csv>",
+		Short: "Set aliases, notes and tags from a vCard or CSV file (matched by phone number)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+			format = strings.ToLower(strings.TrimSpace(format))
+			if format == "" {
+				format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			var entries []appPkg.ContactImportEntry
+			switch format {
+			case "vcf", "vcard":
+				cards, err := vcard.Parse(f)
+				if err != nil {
+					return err
+				}
+				entries = appPkg.ContactEntriesFromVCards(cards)
+			case "csv":
+				entries, err = appPkg.ParseContactsCSV(f)
+				if err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("cannot detect file format; use --format vcf|csv")
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			results, err := a.ImportContacts(entries, dryRun)
+			if err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, results)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "STATUS\tPHONE\tALIAS\tTAGS\tJID")
+			matched := 0
+			for _, r := range results {
+				if r.JID != "" {
+					matched++
+				}
+				status := r.Status
+				if r.Error != "" {
+					status += " (" + truncate(r.Error, 30) + ")"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					status,
+					truncate(r.Input, 24),
+					truncate(r.Alias, 24),
+					truncate(strings.Join(r.Tags, ","), 24),
+					r.JID,
+				)
+			}
+			_ = w.Flush()
+			fmt.Fprintf(os.Stdout, "\nMatched %d of %d entries.\n", matched, len(results))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "input format: vcf|csv (default: from file extension)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "only report matches; do not write anything")
+	return cmd
+}
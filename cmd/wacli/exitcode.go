@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow"
+)
+
+// Process exit codes, so scripts can tell whether repeating a command is safe.
+const (
+	exitError     = 1 // any other failure
+	exitNotAuthed = 3 // no linked session; run `wacli auth`
+	exitTransient = 4 // disconnected or timed out; retry (with the same --idempotency-key for sends)
+	exitRejected  = 5 // WhatsApp refused the request; retrying won't help
+	exitConflict  = 6 // idempotency key already used for a different recipient
+)
+
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, wa.ErrNotAuthenticated):
+		return exitNotAuthed
+	case errors.Is(err, app.ErrIdempotencyConflict):
+		return exitConflict
+	case wa.IsTransient(err):
+		return exitTransient
+	case errors.Is(err, whatsmeow.ErrServerReturnedError):
+		return exitRejected
+	default:
+		return exitError
+	}
+}
@@ -46,7 +46,7 @@ func newGroupsInfoCmd(flags *rootFlags) *cobra.Command {
 				return err
 			}
 			if info != nil {
-				_ = persistGroupInfo(a.DB(), info)
+				_ = persistGroupInfo(ctx, a, info)
 			}
 
 			if flags.asJSON {
@@ -101,7 +101,7 @@ func newGroupsRenameCmd(flags *rootFlags) *cobra.Command {
 				return err
 			}
 			if info, err := a.WA().GetGroupInfo(ctx, gjid); err == nil && info != nil {
-				_ = persistGroupInfo(a.DB(), info)
+				_ = persistGroupInfo(ctx, a, info)
 			}
 			if flags.asJSON {
 				return out.WriteJSON(os.Stdout, map[string]any{"jid": gjid.String(), "name": name})
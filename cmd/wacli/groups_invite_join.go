@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/wa"
 	"go.mau.fi/whatsmeow/types"
 )
 
@@ -17,6 +19,7 @@ func newGroupsInviteCmd(flags *rootFlags) *cobra.Command {
 		Short: "Manage group invite links",
 	}
 	cmd.AddCommand(newGroupsInviteLinkCmd(flags))
+	cmd.AddCommand(newGroupsInviteInspectCmd(flags))
 	return cmd
 }
 
@@ -116,14 +119,91 @@ func newGroupsInviteLinkRevokeCmd(flags *rootFlags) *cobra.Command {
 	return cmd
 }
 
+func newGroupsInviteInspectCmd(flags *rootFlags) *cobra.Command {
+	var code string
+	var link string
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Preview a group from its invite link without joining",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inviteCode, err := inviteCodeFromFlags(code, link)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+			info, err := a.WA().GetGroupInfoFromLink(ctx, inviteCode)
+			if err != nil {
+				return err
+			}
+
+			size := info.ParticipantCount
+			if size == 0 {
+				size = len(info.Participants)
+			}
+			owner := info.OwnerJID
+			if owner.IsEmpty() {
+				owner = info.OwnerPN
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{
+					"code":        inviteCode,
+					"jid":         info.JID.String(),
+					"name":        info.GroupName.Name,
+					"size":        size,
+					"owner":       owner.String(),
+					"created":     info.GroupCreated,
+					"description": info.GroupTopic.Topic,
+					"community":   info.IsParent,
+					"parent_jid":  info.LinkedParentJID.String(),
+				})
+			}
+
+			fmt.Fprintf(os.Stdout, "JID: %s\nName: %s\nSize: %d\nOwner: %s\nCreated: %s\n",
+				info.JID.String(),
+				info.GroupName.Name,
+				size,
+				owner.String(),
+				info.GroupCreated.Local().Format(time.RFC3339),
+			)
+			if !info.LinkedParentJID.IsEmpty() {
+				fmt.Fprintf(os.Stdout, "Community: %s\n", info.LinkedParentJID.String())
+			}
+			if desc := strings.TrimSpace(info.GroupTopic.Topic); desc != "" {
+				fmt.Fprintf(os.Stdout, "\n%s\n", desc)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&code, "code", "", "invite code")
+	cmd.Flags().StringVar(&link, "link", "", "invite link (https://chat.whatsapp.com/...)")
+	return cmd
+}
+
 func newGroupsJoinCmd(flags *rootFlags) *cobra.Command {
 	var code string
+	var link string
 	cmd := &cobra.Command{
 		Use:   "join",
-		Short: "Join group by invite code",
+		Short: "Join group by invite code or link",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if strings.TrimSpace(code) == "" {
-				return fmt.Errorf("--code is required")
+			inviteCode, err := inviteCodeFromFlags(code, link)
+			if err != nil {
+				return err
 			}
 			ctx, cancel := withTimeout(context.Background(), flags)
 			defer cancel()
@@ -140,12 +220,12 @@ func newGroupsJoinCmd(flags *rootFlags) *cobra.Command {
 			if err := a.Connect(ctx, false, nil); err != nil {
 				return err
 			}
-			jid, err := a.WA().JoinGroupWithLink(ctx, code)
+			jid, err := a.WA().JoinGroupWithLink(ctx, inviteCode)
 			if err != nil {
 				return err
 			}
 			if info, err := a.WA().GetGroupInfo(ctx, jid); err == nil && info != nil {
-				_ = persistGroupInfo(a.DB(), info)
+				_ = persistGroupInfo(ctx, a, info)
 			}
 			if flags.asJSON {
 				return out.WriteJSON(os.Stdout, map[string]any{"jid": jid.String(), "joined": true})
@@ -154,6 +234,22 @@ func newGroupsJoinCmd(flags *rootFlags) *cobra.Command {
 			return nil
 		},
 	}
-	cmd.Flags().StringVar(&code, "code", "", "invite code (from link)")
+	cmd.Flags().StringVar(&code, "code", "", "invite code or full invite link")
+	cmd.Flags().StringVar(&link, "link", "", "invite link (https://chat.whatsapp.com/...)")
 	return cmd
 }
+
+func inviteCodeFromFlags(code, link string) (string, error) {
+	code = strings.TrimSpace(code)
+	link = strings.TrimSpace(link)
+	if code == "" && link == "" {
+		return "", fmt.Errorf("--code or --link is required")
+	}
+	if code != "" && link != "" {
+		return "", fmt.Errorf("use either --code or --link, not both")
+	}
+	if code == "" {
+		code = link
+	}
+	return wa.ParseInviteCode(code)
+}
@@ -2,11 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	appPkg "github.com/steipete/wacli/internal/app"
 	"github.com/steipete/wacli/internal/out"
 	"github.com/steipete/wacli/internal/wa"
 	"go.mau.fi/whatsmeow/types"
@@ -17,6 +21,7 @@ func newGroupsParticipantsCmd(flags *rootFlags) *cobra.Command {
 		Use:   "participants",
 		Short: "Manage group participants",
 	}
+	cmd.AddCommand(newGroupsParticipantsListCmd(flags))
 	cmd.AddCommand(newGroupsParticipantsActionCmd(flags, "add"))
 	cmd.AddCommand(newGroupsParticipantsActionCmd(flags, "remove"))
 	cmd.AddCommand(newGroupsParticipantsActionCmd(flags, "promote"))
@@ -24,15 +29,117 @@ func newGroupsParticipantsCmd(flags *rootFlags) *cobra.Command {
 	return cmd
 }
 
+func newGroupsParticipantsListCmd(flags *rootFlags) *cobra.Command {
+	var group string
+	var format string
+	var live bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List group participants with roles and local contact names",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(group) == "" {
+				return fmt.Errorf("--jid is required")
+			}
+			format = strings.ToLower(strings.TrimSpace(format))
+			switch format {
+			case "", "table", "csv", "json":
+			default:
+				return fmt.Errorf("unsupported --format %q (use table, csv or json)", format)
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, live, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			gjid, err := types.ParseJID(group)
+			if err != nil {
+				return err
+			}
+
+			if live {
+				if err := a.EnsureAuthed(); err != nil {
+					return err
+				}
+				if err := a.Connect(ctx, false, nil); err != nil {
+					return err
+				}
+				info, err := a.WA().GetGroupInfo(ctx, gjid)
+				if err != nil {
+					return err
+				}
+				if err := persistGroupInfo(ctx, a, info); err != nil {
+					return err
+				}
+			}
+
+			members, err := a.DB().ListGroupMembers(gjid.String())
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case format == "json" || (format == "" && flags.asJSON):
+				return out.WriteJSON(os.Stdout, members)
+			case format == "csv":
+				w := csv.NewWriter(os.Stdout)
+				_ = w.Write([]string{"jid", "role", "phone", "name", "alias", "tags"})
+				for _, m := range members {
+					_ = w.Write([]string{m.UserJID, m.Role, m.Phone, m.Name, m.Alias, strings.Join(m.Tags, ";")})
+				}
+				w.Flush()
+				return w.Error()
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "ROLE\tPHONE\tNAME\tALIAS\tTAGS\tJID")
+			for _, m := range members {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					m.Role,
+					truncate(m.Phone, 16),
+					truncate(m.Name, 24),
+					truncate(m.Alias, 18),
+					truncate(strings.Join(m.Tags, ","), 24),
+					m.UserJID,
+				)
+			}
+			_ = tw.Flush()
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&group, "jid", "", "group JID (…@g.us)")
+	cmd.Flags().StringVar(&format, "format", "", "output format: table|csv|json (default: table, or json with --json)")
+	cmd.Flags().BoolVar(&live, "live", false, "refresh participants from WhatsApp before listing")
+	return cmd
+}
+
 func newGroupsParticipantsActionCmd(flags *rootFlags, action string) *cobra.Command {
 	var group string
 	var users []string
+	var usersFile string
+	var chunkSize int
+	var chunkDelay time.Duration
+	var sendInvite bool
+	var inviteMessage string
 	cmd := &cobra.Command{
 		Use:   action,
 		Short: action + " participants",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(usersFile) != "" {
+				fromFile, err := readListFile(usersFile)
+				if err != nil {
+					return err
+				}
+				users = append(users, fromFile...)
+			}
 			if strings.TrimSpace(group) == "" || len(users) == 0 {
-				return fmt.Errorf("--jid and at least one --user are required")
+				return fmt.Errorf("--jid and at least one --user (or --users-file) are required")
+			}
+			if sendInvite && action != "add" {
+				return fmt.Errorf("--send-invite-to-failed only applies to add")
 			}
 			ctx, cancel := withTimeout(context.Background(), flags)
 			defer cancel()
@@ -55,30 +162,69 @@ func newGroupsParticipantsActionCmd(flags *rootFlags, action string) *cobra.Comm
 				return err
 			}
 			var jids []types.JID
+			seen := map[types.JID]bool{}
 			for _, u := range users {
 				j, err := wa.ParseUserOrJID(u)
 				if err != nil {
 					return err
 				}
+				if seen[j] {
+					continue
+				}
+				seen[j] = true
 				jids = append(jids, j)
 			}
 
-			updated, err := a.WA().UpdateGroupParticipants(ctx, gjid, jids, wa.GroupParticipantAction(action))
+			results, err := a.UpdateGroupParticipants(ctx, gjid, jids, appPkg.ParticipantUpdateOptions{
+				Action:     wa.GroupParticipantAction(action),
+				ChunkSize:  chunkSize,
+				ChunkDelay: chunkDelay,
+			})
 			if err != nil {
 				return err
 			}
+			if sendInvite {
+				if err := a.SendGroupInvites(ctx, gjid, results, inviteMessage); err != nil {
+					return err
+				}
+			}
 			if info, err := a.WA().GetGroupInfo(ctx, gjid); err == nil && info != nil {
-				_ = persistGroupInfo(a.DB(), info)
+				_ = persistGroupInfo(ctx, a, info)
 			}
 
+			var runErr error
+			if !appPkg.AnyParticipantUpdated(results) {
+				runErr = fmt.Errorf("%s failed for all %d users", action, len(results))
+			}
 			if flags.asJSON {
-				return out.WriteJSON(os.Stdout, updated)
+				if err := out.WriteJSON(os.Stdout, results); err != nil {
+					return err
+				}
+				return runErr
 			}
-			fmt.Fprintln(os.Stdout, "OK")
-			return nil
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "USER\tSTATUS\tINVITE\tJID")
+			for _, r := range results {
+				invite := ""
+				if r.InviteSent {
+					invite = "sent"
+				} else if sendInvite && r.Status == "privacy_blocked" {
+					invite = "failed"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.User, r.Status, invite, r.JID)
+			}
+			_ = w.Flush()
+			return runErr
 		},
 	}
 	cmd.Flags().StringVar(&group, "jid", "", "group JID (…@g.us)")
 	cmd.Flags().StringSliceVar(&users, "user", nil, "user phone number or JID (repeatable)")
+	cmd.Flags().StringVar(&usersFile, "users-file", "", "file with one phone number or JID per line (or CSV, first column)")
+	cmd.Flags().IntVar(&chunkSize, "chunk-size", appPkg.DefaultParticipantChunkSize, "users per WhatsApp request")
+	cmd.Flags().DurationVar(&chunkDelay, "chunk-delay", 2*time.Second, "pause between chunks")
+	if action == "add" {
+		cmd.Flags().BoolVar(&sendInvite, "send-invite-to-failed", false, "DM the invite link to users whose privacy settings blocked the add")
+		cmd.Flags().StringVar(&inviteMessage, "invite-message", "You're invited to join our WhatsApp group:", "text sent before the invite link")
+	}
 	return cmd
 }
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"context"
+
+	"github.com/steipete/wacli/internal/app"
 	"github.com/steipete/wacli/internal/store"
 	"go.mau.fi/whatsmeow/types"
 )
 
-func persistGroupInfo(db *store.DB, info *types.GroupInfo) error {
+func persistGroupInfo(ctx context.Context, a *app.App, info *types.GroupInfo) error {
+	db := a.DB()
 	if info == nil {
 		return nil
 	}
 	if err := db.UpsertGroup(info.JID.String(), info.GroupName.Name, info.OwnerJID.String(), info.GroupCreated); err != nil {
 		return err
 	}
+	if err := db.SetGroupCommunity(info.JID.String(), info.IsParent, info.LinkedParentJID.String()); err != nil {
+		return err
+	}
 	var ps []store.GroupParticipant
 	for _, p := range info.Participants {
 		role := "member"
@@ -22,7 +29,7 @@ func persistGroupInfo(db *store.DB, info *types.GroupInfo) error {
 		}
 		ps = append(ps, store.GroupParticipant{
 			GroupJID: info.JID.String(),
-			UserJID:  p.JID.String(),
+			UserJID:  a.ParticipantJID(ctx, p).String(),
 			Role:     role,
 		})
 	}
@@ -40,7 +40,7 @@ func newGroupsRefreshCmd(flags *rootFlags) *cobra.Command {
 				if g == nil {
 					continue
 				}
-				_ = persistGroupInfo(a.DB(), g)
+				_ = persistGroupInfo(ctx, a, g)
 				_ = a.DB().UpsertChat(g.JID.String(), "group", g.GroupName.Name, time.Now())
 			}
 
@@ -79,13 +79,20 @@ func newGroupsListCmd(flags *rootFlags) *cobra.Command {
 			}
 
 			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
-			fmt.Fprintln(w, "NAME\tJID\tCREATED")
+			fmt.Fprintln(w, "NAME\tJID\tCOMMUNITY\tCREATED")
 			for _, g := range gs {
 				name := g.Name
 				if name == "" {
 					name = g.JID
 				}
-				fmt.Fprintf(w, "%s\t%s\t%s\n", truncate(name, 40), g.JID, g.CreatedAt.Local().Format("2006-01-02"))
+				community := g.ParentName
+				if community == "" {
+					community = g.ParentJID
+				}
+				if g.IsParent {
+					community = "(community)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", truncate(name, 40), g.JID, truncate(community, 28), g.CreatedAt.Local().Format("2006-01-02"))
 			}
 			_ = w.Flush()
 			return nil
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bufio"
+	"encoding/csv"
 	"fmt"
 	"os"
 	"strings"
@@ -27,6 +29,21 @@ func parseTime(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unsupported time format %q (use RFC3339 or YYYY-MM-DD)", s)
 }
 
+// parseScheduleTime parses a send time given as RFC3339 or as a local
+// "YYYY-MM-DDTHH:MM[:SS]" / "YYYY-MM-DD HH:MM[:SS]".
+func parseScheduleTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time %q (use YYYY-MM-DDTHH:MM in local time, or RFC3339)", s)
+}
+
 func truncate(s string, max int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	s = strings.TrimSpace(s)
@@ -38,3 +55,54 @@ func truncate(s string, max int) string {
 	}
 	return s[:max-1] + "…"
 }
+
+// readListFile reads phone numbers or JIDs from a newline-separated list or a
+// CSV file (first column). Blank lines, "#" comments and a header row are
+// skipped.
+func readListFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []string
+	sc := bufio.NewScanner(f)
+	first := true
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.IndexAny(line, ",;\t"); i >= 0 {
+			r := csv.NewReader(strings.NewReader(line))
+			r.Comma = rune(line[i])
+			fields, err := r.Read()
+			if err != nil || len(fields) == 0 {
+				return nil, fmt.Errorf("%s: parse line %q", path, line)
+			}
+			line = strings.TrimSpace(fields[0])
+		}
+		if first {
+			first = false
+			if isListHeader(line) {
+				continue
+			}
+		}
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func isListHeader(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "phone", "number", "jid", "user", "to", "recipient", "msisdn":
+		return true
+	}
+	return false
+}
@@ -12,7 +12,7 @@ import (
 func main() {
 	applyDeviceLabel()
 	if err := execute(os.Args[1:]); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -32,58 +32,7 @@ func newMediaDownloadCmd(flags *rootFlags) *cobra.Command {
 				return fmt.Errorf("--chat and --id are required")
 			}
 
-			ctx, cancel := withTimeout(context.Background(), flags)
-			defer cancel()
-
-			a, lk, err := newApp(ctx, flags, true, false)
-			if err != nil {
-				return err
-			}
-			defer closeApp(a, lk)
-
-			if err := a.EnsureAuthed(); err != nil {
-				return err
-			}
-
-			info, err := a.DB().GetMediaDownloadInfo(chat, id)
-			if err != nil {
-				return err
-			}
-			if info.MediaType == "" || info.DirectPath == "" || len(info.MediaKey) == 0 {
-				return fmt.Errorf("message has no downloadable media metadata (run `wacli sync` first)")
-			}
-
-			target, err := a.ResolveMediaOutputPath(info, outputPath)
-			if err != nil {
-				return err
-			}
-
-			if err := a.Connect(ctx, false, nil); err != nil {
-				return err
-			}
-
-			bytes, err := a.WA().DownloadMediaToFile(ctx, info.DirectPath, info.FileEncSHA256, info.FileSHA256, info.MediaKey, info.FileLength, info.MediaType, "", target)
-			if err != nil {
-				return err
-			}
-			now := time.Now().UTC()
-			_ = a.DB().MarkMediaDownloaded(info.ChatJID, info.MsgID, target, now)
-
-			resp := map[string]any{
-				"chat":          info.ChatJID,
-				"id":            info.MsgID,
-				"path":          target,
-				"bytes":         bytes,
-				"media_type":    info.MediaType,
-				"mime_type":     info.MimeType,
-				"downloaded":    true,
-				"downloaded_at": now.Format(time.RFC3339Nano),
-			}
-			if flags.asJSON {
-				return out.WriteJSON(os.Stdout, resp)
-			}
-			fmt.Fprintf(os.Stdout, "%s (%d bytes)\n", target, bytes)
-			return nil
+			return downloadMedia(flags, chat, id, outputPath)
 		},
 	}
 
@@ -94,3 +43,59 @@ func newMediaDownloadCmd(flags *rootFlags) *cobra.Command {
 	_ = cmd.MarkFlagRequired("id")
 	return cmd
 }
+
+// downloadMedia downloads a stored message's media and prints where it went.
+func downloadMedia(flags *rootFlags, chat, id, outputPath string) error {
+	ctx, cancel := withTimeout(context.Background(), flags)
+	defer cancel()
+
+	a, lk, err := newApp(ctx, flags, true, false)
+	if err != nil {
+		return err
+	}
+	defer closeApp(a, lk)
+
+	if err := a.EnsureAuthed(); err != nil {
+		return err
+	}
+
+	info, err := a.DB().GetMediaDownloadInfo(chat, id)
+	if err != nil {
+		return err
+	}
+	if info.MediaType == "" || info.DirectPath == "" || len(info.MediaKey) == 0 {
+		return fmt.Errorf("message has no downloadable media metadata (run `wacli sync` first)")
+	}
+
+	target, err := a.ResolveMediaOutputPath(info, outputPath)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Connect(ctx, false, nil); err != nil {
+		return err
+	}
+
+	bytes, err := a.WA().DownloadMediaToFile(ctx, info.DirectPath, info.FileEncSHA256, info.FileSHA256, info.MediaKey, info.FileLength, info.MediaType, "", target)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	_ = a.DB().MarkMediaDownloaded(info.ChatJID, info.MsgID, target, now)
+
+	resp := map[string]any{
+		"chat":          info.ChatJID,
+		"id":            info.MsgID,
+		"path":          target,
+		"bytes":         bytes,
+		"media_type":    info.MediaType,
+		"mime_type":     info.MimeType,
+		"downloaded":    true,
+		"downloaded_at": now.Format(time.RFC3339Nano),
+	}
+	if flags.asJSON {
+		return out.WriteJSON(os.Stdout, resp)
+	}
+	fmt.Fprintf(os.Stdout, "%s (%d bytes)\n", target, bytes)
+	return nil
+}
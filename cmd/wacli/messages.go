@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
 	"github.com/steipete/wacli/internal/out"
 	"github.com/steipete/wacli/internal/store"
 )
@@ -22,11 +23,14 @@ func newMessagesCmd(flags *rootFlags) *cobra.Command {
 	cmd.AddCommand(newMessagesSearchCmd(flags))
 	cmd.AddCommand(newMessagesShowCmd(flags))
 	cmd.AddCommand(newMessagesContextCmd(flags))
+	cmd.AddCommand(newMessagesForwardCmd(flags))
 	return cmd
 }
 
 func newMessagesListCmd(flags *rootFlags) *cobra.Command {
 	var chat string
+	var msgType string
+	var excludeForwarded bool
 	var limit int
 	var afterStr string
 	var beforeStr string
@@ -62,10 +66,12 @@ func newMessagesListCmd(flags *rootFlags) *cobra.Command {
 			}
 
 			msgs, err := a.DB().ListMessages(store.ListMessagesParams{
-				ChatJID: chat,
-				Limit:   limit,
-				After:   after,
-				Before:  before,
+				ChatJID:          chat,
+				Type:             msgType,
+				ExcludeForwarded: excludeForwarded,
+				Limit:            limit,
+				After:            after,
+				Before:           before,
 			})
 			if err != nil {
 				return err
@@ -110,6 +116,8 @@ func newMessagesListCmd(flags *rootFlags) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&chat, "chat", "", "chat JID")
+	cmd.Flags().StringVar(&msgType, "type", "", "media type filter (image|video|audio|voice|document)")
+	cmd.Flags().BoolVar(&excludeForwarded, "exclude-forwarded", false, "skip messages forwarded from other chats")
 	cmd.Flags().IntVar(&limit, "limit", 50, "limit results")
 	cmd.Flags().StringVar(&afterStr, "after", "", "only messages after time (RFC3339 or YYYY-MM-DD)")
 	cmd.Flags().StringVar(&beforeStr, "before", "", "only messages before time (RFC3339 or YYYY-MM-DD)")
@@ -123,6 +131,7 @@ func newMessagesSearchCmd(flags *rootFlags) *cobra.Command {
 	var afterStr string
 	var beforeStr string
 	var msgType string
+	var excludeForwarded bool
 
 	cmd := &cobra.Command{
 		Use:   "search <query>",
@@ -156,13 +165,14 @@ func newMessagesSearchCmd(flags *rootFlags) *cobra.Command {
 			}
 
 			msgs, err := a.DB().SearchMessages(store.SearchMessagesParams{
-				Query:   args[0],
-				ChatJID: chat,
-				From:    from,
-				Limit:   limit,
-				After:   after,
-				Before:  before,
-				Type:    msgType,
+				Query:            args[0],
+				ChatJID:          chat,
+				From:             from,
+				Limit:            limit,
+				After:            after,
+				Before:           before,
+				Type:             msgType,
+				ExcludeForwarded: excludeForwarded,
 			})
 			if err != nil {
 				return err
@@ -214,7 +224,8 @@ func newMessagesSearchCmd(flags *rootFlags) *cobra.Command {
 	cmd.Flags().IntVar(&limit, "limit", 50, "limit results")
 	cmd.Flags().StringVar(&afterStr, "after", "", "only messages after time (RFC3339 or YYYY-MM-DD)")
 	cmd.Flags().StringVar(&beforeStr, "before", "", "only messages before time (RFC3339 or YYYY-MM-DD)")
-	cmd.Flags().StringVar(&msgType, "type", "", "media type filter (image|video|audio|document)")
+	cmd.Flags().StringVar(&msgType, "type", "", "media type filter (image|video|audio|voice|document)")
+	cmd.Flags().BoolVar(&excludeForwarded, "exclude-forwarded", false, "skip messages forwarded from other chats")
 	return cmd
 }
 
@@ -259,9 +270,40 @@ func newMessagesShowCmd(flags *rootFlags) *cobra.Command {
 			} else {
 				fmt.Fprintf(os.Stdout, "From: %s\n", m.SenderJID)
 			}
+			if m.Forwarded {
+				if m.ForwardingScore >= 5 {
+					fmt.Fprintf(os.Stdout, "Forwarded: many times (%d)\n", m.ForwardingScore)
+				} else {
+					fmt.Fprintf(os.Stdout, "Forwarded: yes\n")
+				}
+			}
 			if m.MediaType != "" {
 				fmt.Fprintf(os.Stdout, "Media: %s\n", m.MediaType)
 			}
+			if m.ViewOnce {
+				fmt.Fprintf(os.Stdout, "View once: yes\n")
+			}
+			if m.EphemeralExpiration > 0 {
+				fmt.Fprintf(os.Stdout, "Disappears: after %s\n", app.FormatTimer(m.EphemeralExpiration))
+			}
+			if !m.EditedAt.IsZero() {
+				fmt.Fprintf(os.Stdout, "Edited: %s\n", m.EditedAt.Local().Format(time.RFC3339))
+			}
+			if m.Width > 0 && m.Height > 0 {
+				fmt.Fprintf(os.Stdout, "Dimensions: %dx%d\n", m.Width, m.Height)
+			}
+			if m.DurationSeconds > 0 {
+				fmt.Fprintf(os.Stdout, "Duration: %s\n", time.Duration(m.DurationSeconds)*time.Second)
+			}
+			if m.LinkTitle != "" || m.LinkDescription != "" {
+				fmt.Fprintf(os.Stdout, "Link: %s\n", m.LinkURL)
+				if m.LinkTitle != "" {
+					fmt.Fprintf(os.Stdout, "Link title: %s\n", m.LinkTitle)
+				}
+				if m.LinkDescription != "" {
+					fmt.Fprintf(os.Stdout, "Link description: %s\n", m.LinkDescription)
+				}
+			}
 			fmt.Fprintf(os.Stdout, "\n%s\n", m.Text)
 			return nil
 		},
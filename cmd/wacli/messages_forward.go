@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func newMessagesForwardCmd(flags *rootFlags) *cobra.Command {
+	var chat string
+	var id string
+	var to string
+	var idemKey string
+
+	cmd := &cobra.Command{
+		Use:   "forward",
+		Short: "Forward a stored message to another chat",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if chat == "" || id == "" || to == "" {
+				return fmt.Errorf("--chat, --id and --to are required")
+			}
+			toJID, err := wa.ParseUserOrJID(to)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			return runSend(ctx, flags, idemKey, toJID, func(a *app.App, msgID types.MessageID) (sendResult, error) {
+				sentID, err := a.ForwardMessage(ctx, chat, id, toJID, msgID)
+				return sendResult{ID: sentID}, err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&chat, "chat", "", "chat JID the message is in")
+	cmd.Flags().StringVar(&id, "id", "", "message ID")
+	cmd.Flags().StringVar(&to, "to", "", "recipient phone number or JID")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "send at most once per key; repeating a key returns the original result")
+	return cmd
+}
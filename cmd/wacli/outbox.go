@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/out"
+)
+
+func newOutboxCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Manage scheduled messages (send --at)",
+	}
+	cmd.AddCommand(newOutboxListCmd(flags))
+	cmd.AddCommand(newOutboxCancelCmd(flags))
+	cmd.AddCommand(newOutboxRescheduleCmd(flags))
+	cmd.AddCommand(newOutboxRunCmd(flags))
+	return cmd
+}
+
+func newOutboxListCmd(flags *rootFlags) *cobra.Command {
+	var status string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List scheduled messages",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			items, err := a.DB().ListOutbox(status, limit)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, items)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tSEND AT\tSTATUS\tTRIES\tTO\tMESSAGE\tERROR")
+			for _, it := range items {
+				msg := it.Text
+				if it.Kind == "file" {
+					msg = "[file] " + it.FilePath
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\n",
+					it.ID,
+					it.SendAt.Local().Format("2006-01-02 15:04"),
+					it.Status,
+					it.Attempts,
+					it.ChatJID,
+					truncate(msg, 40),
+					truncate(it.LastError, 30),
+				)
+			}
+			_ = w.Flush()
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&status, "status", "", "only show items with this status (pending|sending|sent|failed|canceled)")
+	cmd.Flags().IntVar(&limit, "limit", 100, "max items")
+	return cmd
+}
+
+func newOutboxCancelCmd(flags *rootFlags) *cobra.Command {
+	var id int64
+
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel a scheduled message",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if id <= 0 {
+				return fmt.Errorf("--id is required")
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.DB().CancelOutbox(id); err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"id": id, "canceled": true})
+			}
+			fmt.Fprintln(os.Stdout, "OK")
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&id, "id", 0, "outbox item ID")
+	return cmd
+}
+
+func newOutboxRescheduleCmd(flags *rootFlags) *cobra.Command {
+	var id int64
+	var at string
+
+	cmd := &cobra.Command{
+		Use:   "reschedule",
+		Short: "Move a scheduled message to a new time (also re-queues failed or canceled items)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if id <= 0 || at == "" {
+				return fmt.Errorf("--id and --at are required")
+			}
+			sendAt, err := parseScheduleTime(at)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.DB().RescheduleOutbox(id, sendAt); err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"id": id, "send_at": sendAt.UTC()})
+			}
+			fmt.Fprintf(os.Stdout, "Rescheduled #%d for %s\n", id, sendAt.Local().Format("2006-01-02 15:04"))
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&id, "id", 0, "outbox item ID")
+	cmd.Flags().StringVar(&at, "at", "", "new send time (e.g. 2026-11-01T09:00, local time)")
+	return cmd
+}
+
+func newOutboxRunCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Send all due scheduled messages and exit",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+
+			res, err := a.DeliverOutbox(ctx)
+			if err != nil {
+				return err
+			}
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, res)
+			}
+			fmt.Fprintf(os.Stdout, "Sent %d, retrying %d, failed %d\n", res.Sent, res.Retried, res.Failed)
+			return nil
+		},
+	}
+	return cmd
+}
@@ -44,10 +44,14 @@ func execute(args []string) error {
 	rootCmd.AddCommand(newSyncCmd(&flags))
 	rootCmd.AddCommand(newMessagesCmd(&flags))
 	rootCmd.AddCommand(newSendCmd(&flags))
+	rootCmd.AddCommand(newOutboxCmd(&flags))
 	rootCmd.AddCommand(newMediaCmd(&flags))
+	rootCmd.AddCommand(newStatusCmd(&flags))
+	rootCmd.AddCommand(newCallsCmd(&flags))
 	rootCmd.AddCommand(newContactsCmd(&flags))
 	rootCmd.AddCommand(newChatsCmd(&flags))
 	rootCmd.AddCommand(newGroupsCmd(&flags))
+	rootCmd.AddCommand(newCommunitiesCmd(&flags))
 	rootCmd.AddCommand(newHistoryCmd(&flags))
 
 	rootCmd.SetArgs(args)
@@ -2,14 +2,21 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/linkpreview"
 	"github.com/steipete/wacli/internal/out"
 	"github.com/steipete/wacli/internal/store"
 	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
 )
 
 func newSendCmd(flags *rootFlags) *cobra.Command {
@@ -19,76 +26,294 @@ func newSendCmd(flags *rootFlags) *cobra.Command {
 	}
 	cmd.AddCommand(newSendTextCmd(flags))
 	cmd.AddCommand(newSendFileCmd(flags))
+	cmd.AddCommand(newSendVoiceCmd(flags))
+	cmd.AddCommand(newSendStickerCmd(flags))
+	cmd.AddCommand(newSendLocationCmd(flags))
+	cmd.AddCommand(newSendContactCmd(flags))
+	cmd.AddCommand(newSendBulkCmd(flags))
 	return cmd
 }
 
 func newSendTextCmd(flags *rootFlags) *cobra.Command {
-	var to string
+	var rcpt recipientFlags
 	var message string
+	var messageFile string
+	var markdown bool
+	var linkPreview bool
+	var at string
+	var idemKey string
 
 	cmd := &cobra.Command{
 		Use:   "text",
 		Short: "Send a text message",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if to == "" || message == "" {
-				return fmt.Errorf("--to and --message are required")
+			if err := rcpt.validate(); err != nil {
+				return err
+			}
+			message, err := readMessageText(message, messageFile, markdown)
+			if err != nil {
+				return err
 			}
 
 			ctx, cancel := withTimeout(context.Background(), flags)
 			defer cancel()
 
-			a, lk, err := newApp(ctx, flags, true, false)
-			if err != nil {
-				return err
+			if at != "" {
+				if idemKey != "" {
+					return fmt.Errorf("--idempotency-key cannot be combined with --at")
+				}
+				kind := "text"
+				if linkPreview {
+					// The page is fetched at delivery time.
+					kind = "link"
+				}
+				return scheduleSend(ctx, flags, at, rcpt, store.OutboxItem{
+					Kind: kind,
+					Text: message,
+				})
 			}
-			defer closeApp(a, lk)
 
-			if err := a.EnsureAuthed(); err != nil {
-				return err
-			}
-			if err := a.Connect(ctx, false, nil); err != nil {
-				return err
-			}
+			// With several recipients the page is fetched once.
+			var preview *linkpreview.Preview
+			var previewErr error
+			return runSendTo(ctx, flags, idemKey, rcpt, func(a *app.App, to types.JID, id types.MessageID) (sendResult, error) {
+				if !linkPreview {
+					msgID, err := a.SendText(ctx, to, message, id)
+					return sendResult{ID: msgID}, err
+				}
+				if preview == nil && previewErr == nil {
+					p, err := a.LinkPreview(ctx, message)
+					preview, previewErr = p, err
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "warning: sending without link preview: %v\n", err)
+					}
+				}
+				msgID, err := a.SendTextWithLink(ctx, to, message, preview, id)
+				return sendResult{ID: msgID, LinkPreview: preview}, err
+			})
+		},
+	}
 
-			toJID, err := wa.ParseUserOrJID(to)
-			if err != nil {
-				return err
-			}
+	rcpt.register(cmd)
+	cmd.Flags().StringVar(&message, "message", "", "message text (\"-\" reads stdin)")
+	cmd.Flags().StringVar(&messageFile, "message-file", "", "read the message text from a file")
+	cmd.Flags().BoolVar(&linkPreview, "link-preview", false, "attach a preview (title, description, thumbnail) of the first link, fetched from the page's OpenGraph tags")
+	cmd.Flags().BoolVar(&markdown, "markdown", false, "convert Markdown (**bold**, *italic*, ~~strike~~, `code`) to WhatsApp formatting")
+	cmd.Flags().StringVar(&at, "at", "", "schedule for later delivery via the outbox (e.g. 2026-11-01T09:00, local time)")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "send at most once per key; repeating a key returns the original result")
+	return cmd
+}
 
-			msgID, err := a.WA().SendText(ctx, toJID, message)
-			if err != nil {
-				return err
-			}
+// readMessageText returns the message from --message or --message-file, where
+// "-" means stdin, optionally converting Markdown formatting.
+func readMessageText(message, messageFile string, markdown bool) (string, error) {
+	if (message == "") == (messageFile == "") {
+		return "", fmt.Errorf("exactly one of --message or --message-file is required")
+	}
+	path := messageFile
+	if message == "-" {
+		path = "-"
+	}
+	text := message
+	if path != "" {
+		var b []byte
+		var err error
+		if path == "-" {
+			b, err = io.ReadAll(os.Stdin)
+		} else {
+			b, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return "", fmt.Errorf("read message: %w", err)
+		}
+		text = strings.TrimRight(string(b), "\r\n")
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("message is empty")
+	}
+	if markdown {
+		text = wa.MarkdownToWhatsApp(text)
+	}
+	if err := wa.CheckTextLength(text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
 
-			now := time.Now().UTC()
-			chat := toJID
-			chatName := a.WA().ResolveChatName(ctx, chat, "")
-			kind := chatKindFromJID(chat)
-			_ = a.DB().UpsertChat(chat.String(), kind, chatName, now)
-			_ = a.DB().UpsertMessage(store.UpsertMessageParams{
-				ChatJID:    chat.String(),
-				ChatName:   chatName,
-				MsgID:      string(msgID),
-				SenderJID:  "",
-				SenderName: "me",
-				Timestamp:  now,
-				FromMe:     true,
-				Text:       message,
-			})
+type sendResult struct {
+	Sent           bool                 `json:"sent"`
+	To             string               `json:"to"`
+	ID             types.MessageID      `json:"id"`
+	File           *sentFileInfo        `json:"file,omitempty"`
+	LinkPreview    *linkpreview.Preview `json:"link_preview,omitempty"`
+	Audience       *app.StatusAudience  `json:"audience,omitempty"`
+	Seconds        uint32               `json:"seconds,omitempty"`
+	IdempotencyKey string               `json:"idempotency_key,omitempty"`
+	Duplicate      bool                 `json:"duplicate,omitempty"`
+	Error          string               `json:"error,omitempty"`
+}
 
-			if flags.asJSON {
-				return out.WriteJSON(os.Stdout, map[string]any{
-					"sent": true,
-					"to":   chat.String(),
-					"id":   msgID,
-				})
+type sentFileInfo struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type"`
+	Media    string `json:"media"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+	Seconds  uint32 `json:"seconds,omitempty"`
+	Animated bool   `json:"animated,omitempty"`
+}
+
+// runSend connects and calls send. With an idempotency key, a key that
+// already succeeded prints the stored result without connecting, and an
+// unfinished one is retried with its reserved message ID so WhatsApp drops
+// the duplicate if the earlier attempt did go through.
+func runSend(ctx context.Context, flags *rootFlags, key string, to types.JID, send func(a *app.App, id types.MessageID) (sendResult, error)) error {
+	a, lk, err := newApp(ctx, flags, true, false)
+	if err != nil {
+		return err
+	}
+	defer closeApp(a, lk)
+	return sendOne(ctx, flags, a, key, to, send)
+}
+
+func sendOne(ctx context.Context, flags *rootFlags, a *app.App, key string, to types.JID, send func(a *app.App, id types.MessageID) (sendResult, error)) error {
+	key = strings.TrimSpace(key)
+	if key != "" {
+		prev, err := a.DB().GetSendKey(key)
+		switch {
+		case err == nil && prev.ChatJID != to.String():
+			return app.ErrIdempotencyConflict
+		case err == nil && !prev.SentAt.IsZero():
+			var res sendResult
+			if err := json.Unmarshal([]byte(prev.Result), &res); err != nil {
+				return fmt.Errorf("decode stored result for key %q: %w", key, err)
 			}
-			fmt.Fprintf(os.Stdout, "Sent to %s (id %s)\n", chat.String(), msgID)
-			return nil
-		},
+			res.Duplicate = true
+			return writeSendResult(flags, res)
+		case err != nil && !store.IsNotFound(err):
+			return err
+		}
 	}
 
-	cmd.Flags().StringVar(&to, "to", "", "recipient phone number or JID")
-	cmd.Flags().StringVar(&message, "message", "", "message text")
-	return cmd
+	if err := a.EnsureAuthed(); err != nil {
+		return err
+	}
+	if err := a.Connect(ctx, false, nil); err != nil {
+		return err
+	}
+
+	var id types.MessageID
+	if key != "" {
+		var err error
+		if id, err = a.ReserveSendKey(key, to); err != nil {
+			return err
+		}
+	}
+	res, err := send(a, id)
+	if err != nil {
+		return err
+	}
+	res.Sent = true
+	res.To = to.String()
+	if key != "" {
+		res.IdempotencyKey = key
+		b, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if err := a.DB().CompleteSendKey(key, string(res.ID), string(b)); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: sent, but failed to record idempotency key: %v\n", err)
+		}
+	}
+	return writeSendResult(flags, res)
+}
+
+func writeSendResult(flags *rootFlags, res sendResult) error {
+	if flags.asJSON {
+		return out.WriteJSON(os.Stdout, res)
+	}
+	verb := "Sent"
+	if res.Duplicate {
+		verb = "Already sent"
+	}
+	switch {
+	case res.File != nil:
+		fmt.Fprintf(os.Stdout, "%s %s to %s (id %s)\n", verb, res.File.Name, res.To, res.ID)
+	case res.Seconds > 0:
+		fmt.Fprintf(os.Stdout, "%s %ds voice note to %s (id %s)\n", verb, res.Seconds, res.To, res.ID)
+	default:
+		fmt.Fprintf(os.Stdout, "%s to %s (id %s)\n", verb, res.To, res.ID)
+		if res.LinkPreview != nil {
+			fmt.Fprintf(os.Stdout, "Link preview: %s\n", res.LinkPreview.Title)
+		}
+	}
+	if res.Audience != nil {
+		fmt.Fprintf(os.Stdout, "Audience: %s\n", res.Audience.Type)
+	}
+	return nil
+}
+
+// scheduleSend queues it in the outbox for delivery at the given time, once
+// per recipient.
+func scheduleSend(ctx context.Context, flags *rootFlags, at string, rcpt recipientFlags, it store.OutboxItem) error {
+	sendAt, err := parseScheduleTime(at)
+	if err != nil {
+		return err
+	}
+	if sendAt.Before(time.Now()) {
+		return fmt.Errorf("--at %s is in the past", at)
+	}
+	it.SendAt = sendAt
+
+	a, lk, err := newApp(ctx, flags, true, false)
+	if err != nil {
+		return err
+	}
+	defer closeApp(a, lk)
+
+	targets, err := a.ResolveRecipients(rcpt.options())
+	if err != nil {
+		return err
+	}
+	type scheduled struct {
+		OutboxID int64  `json:"outbox_id"`
+		To       string `json:"to"`
+	}
+	var items []scheduled
+	for _, to := range targets {
+		it.ChatJID = to.String()
+		id, err := a.DB().EnqueueOutbox(it)
+		if err != nil {
+			return err
+		}
+		items = append(items, scheduled{OutboxID: id, To: it.ChatJID})
+	}
+
+	if !rcpt.many() {
+		if flags.asJSON {
+			return out.WriteJSON(os.Stdout, map[string]any{
+				"scheduled": true,
+				"outbox_id": items[0].OutboxID,
+				"to":        items[0].To,
+				"send_at":   sendAt.UTC(),
+			})
+		}
+		fmt.Fprintf(os.Stdout, "Scheduled #%d to %s at %s\n", items[0].OutboxID, items[0].To, sendAt.Local().Format("2006-01-02 15:04"))
+		return nil
+	}
+	if flags.asJSON {
+		return out.WriteJSON(os.Stdout, map[string]any{
+			"scheduled": true,
+			"send_at":   sendAt.UTC(),
+			"items":     items,
+		})
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "OUTBOX\tTO")
+	for _, s := range items {
+		fmt.Fprintf(w, "#%d\t%s\n", s.OutboxID, s.To)
+	}
+	_ = w.Flush()
+	fmt.Fprintf(os.Stdout, "Scheduled %d messages at %s\n", len(items), sendAt.Local().Format("2006-01-02 15:04"))
+	return nil
 }
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/out"
+)
+
+func newSendBulkCmd(flags *rootFlags) *cobra.Command {
+	var csvPath string
+	var tmplPath string
+	var toColumn string
+	var attachColumn string
+	var progressPath string
+	var rate float64
+	var jitter time.Duration
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "bulk",
+		Short: "Send a templated message to every row of a CSV (resumable)",
+		Long: "Renders --template (Go text/template, columns as {{.column}}) for each CSV row and sends it to the\n" +
+			"row's --to-column. Sent rows are logged to --progress; re-running the same command skips them.\n" +
+			"Runs until done or Ctrl+C; --timeout does not apply.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if csvPath == "" || tmplPath == "" {
+				return fmt.Errorf("--csv and --template are required")
+			}
+			if rate <= 0 {
+				return fmt.Errorf("--rate must be positive")
+			}
+			tmpl, err := os.ReadFile(tmplPath)
+			if err != nil {
+				return err
+			}
+			msgs, err := app.LoadBulkMessages(csvPath, string(tmpl), app.BulkLoadOptions{
+				ToColumn:         toColumn,
+				AttachmentColumn: attachColumn,
+			})
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				if flags.asJSON {
+					return out.WriteJSON(os.Stdout, msgs)
+				}
+				for _, m := range msgs {
+					fmt.Fprintf(os.Stdout, "--- row %d -> %s\n", m.Row, m.To)
+					if m.Attachment != "" {
+						fmt.Fprintf(os.Stdout, "[attachment: %s]\n", m.Attachment)
+					}
+					fmt.Fprintln(os.Stdout, m.Text)
+				}
+				fmt.Fprintf(os.Stdout, "--- %d messages rendered (dry run, nothing sent)\n", len(msgs))
+				return nil
+			}
+
+			if progressPath == "" {
+				progressPath = csvPath + ".progress.jsonl"
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+
+			results, runErr := a.SendBulk(ctx, msgs, app.BulkSendOptions{
+				Interval:     time.Duration(float64(time.Minute) / rate),
+				Jitter:       jitter,
+				ProgressPath: progressPath,
+				OnResult: func(r app.BulkResult) {
+					fmt.Fprintf(os.Stderr, "[%d/%d] %s %s %s\n", r.Row, len(msgs), r.Status, r.To, r.Error)
+				},
+			})
+
+			sent, skipped, failed := 0, 0, 0
+			for _, r := range results {
+				switch r.Status {
+				case "sent":
+					sent++
+				case "skipped":
+					skipped++
+				default:
+					failed++
+				}
+			}
+			if runErr != nil {
+				fmt.Fprintf(os.Stderr, "Stopped after %d of %d rows; re-run the same command to continue.\n", len(results), len(msgs))
+			} else if failed > 0 {
+				runErr = fmt.Errorf("%d of %d rows failed; re-run the same command to retry them", failed, len(msgs))
+			}
+
+			if flags.asJSON {
+				if err := out.WriteJSON(os.Stdout, map[string]any{
+					"sent":     sent,
+					"skipped":  skipped,
+					"failed":   failed,
+					"progress": progressPath,
+					"results":  results,
+				}); err != nil {
+					return err
+				}
+				return runErr
+			}
+			if failed > 0 {
+				w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+				fmt.Fprintln(w, "ROW\tTO\tERROR")
+				for _, r := range results {
+					if r.Status == "failed" {
+						fmt.Fprintf(w, "%d\t%s\t%s\n", r.Row, r.To, truncate(r.Error, 60))
+					}
+				}
+				_ = w.Flush()
+			}
+			fmt.Fprintf(os.Stdout, "Sent %d, skipped %d (already sent), failed %d. Progress: %s\n", sent, skipped, failed, progressPath)
+			return runErr
+		},
+	}
+
+	cmd.Flags().StringVar(&csvPath, "csv", "", "recipients CSV with a header row")
+	cmd.Flags().StringVar(&tmplPath, "template", "", "message template file (Go text/template)")
+	cmd.Flags().StringVar(&toColumn, "to-column", "phone", "CSV column with the recipient phone number or JID")
+	cmd.Flags().StringVar(&attachColumn, "attachment-column", "", "CSV column with an optional file to send per row (message becomes the caption)")
+	cmd.Flags().StringVar(&progressPath, "progress", "", "progress log path (default: <csv>.progress.jsonl)")
+	cmd.Flags().Float64Var(&rate, "rate", 20, "messages per minute")
+	cmd.Flags().DurationVar(&jitter, "jitter", 2*time.Second, "random extra delay added between messages, up to this much")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "render all messages and exit without connecting")
+	return cmd
+}
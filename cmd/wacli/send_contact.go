@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func newSendContactCmd(flags *rootFlags) *cobra.Command {
+	var to string
+	var vcardPath string
+	var contactJID string
+	var name string
+	var idemKey string
+
+	cmd := &cobra.Command{
+		Use:   "contact",
+		Short: "Send a contact card from a vCard file or a known contact",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" {
+				return fmt.Errorf("--to is required")
+			}
+			if (vcardPath == "") == (contactJID == "") {
+				return fmt.Errorf("exactly one of --vcard or --jid is required")
+			}
+			toJID, err := wa.ParseUserOrJID(to)
+			if err != nil {
+				return err
+			}
+			var card string
+			if vcardPath != "" {
+				b, err := os.ReadFile(vcardPath)
+				if err != nil {
+					return err
+				}
+				card = string(b)
+			} else {
+				jid, err := wa.ParseUserOrJID(contactJID)
+				if err != nil {
+					return err
+				}
+				contactJID = jid.String()
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			return runSend(ctx, flags, idemKey, toJID, func(a *app.App, id types.MessageID) (sendResult, error) {
+				if card == "" {
+					var err error
+					if card, err = a.ContactVCard(contactJID); err != nil {
+						return sendResult{}, err
+					}
+				}
+				msgID, err := a.SendContact(ctx, toJID, card, name, id)
+				return sendResult{ID: msgID}, err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "recipient phone number or JID")
+	cmd.Flags().StringVar(&vcardPath, "vcard", "", "vCard file with one contact")
+	cmd.Flags().StringVar(&contactJID, "jid", "", "known contact (phone number or JID) to share from the local store")
+	cmd.Flags().StringVar(&name, "name", "", "display name (defaults to the card's full name)")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "send at most once per key; repeating a key returns the original result")
+	return cmd
+}
@@ -4,70 +4,96 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
-	"github.com/steipete/wacli/internal/out"
-	"github.com/steipete/wacli/internal/wa"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/types"
 )
 
 func newSendFileCmd(flags *rootFlags) *cobra.Command {
-	var to string
+	var rcpt recipientFlags
 	var filePath string
 	var filename string
 	var caption string
 	var mimeOverride string
+	var at string
+	var idemKey string
 
 	cmd := &cobra.Command{
 		Use:   "file",
 		Short: "Send a file (image/video/audio/document)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if to == "" || filePath == "" {
-				return fmt.Errorf("--to and --file are required")
+			if filePath == "" {
+				return fmt.Errorf("--file is required")
 			}
-
-			ctx, cancel := withTimeout(context.Background(), flags)
-			defer cancel()
-
-			a, lk, err := newApp(ctx, flags, true, false)
-			if err != nil {
-				return err
-			}
-			defer closeApp(a, lk)
-
-			if err := a.EnsureAuthed(); err != nil {
-				return err
-			}
-			if err := a.Connect(ctx, false, nil); err != nil {
+			if err := rcpt.validate(); err != nil {
 				return err
 			}
 
-			toJID, err := wa.ParseUserOrJID(to)
-			if err != nil {
-				return err
-			}
-
-			msgID, meta, err := sendFile(ctx, a, toJID, filePath, filename, caption, mimeOverride)
-			if err != nil {
-				return err
-			}
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
 
-			if flags.asJSON {
-				return out.WriteJSON(os.Stdout, map[string]any{
-					"sent": true,
-					"to":   toJID.String(),
-					"id":   msgID,
-					"file": meta,
+			if at != "" {
+				if idemKey != "" {
+					return fmt.Errorf("--idempotency-key cannot be combined with --at")
+				}
+				// Resolve now: delivery may run from another working directory.
+				abs, err := filepath.Abs(filePath)
+				if err != nil {
+					return err
+				}
+				if _, err := os.Stat(abs); err != nil {
+					return err
+				}
+				return scheduleSend(ctx, flags, at, rcpt, store.OutboxItem{
+					Kind:     "file",
+					Text:     caption,
+					FilePath: abs,
+					Filename: filename,
+					MimeType: mimeOverride,
 				})
 			}
-			fmt.Fprintf(os.Stdout, "Sent %s to %s (id %s)\n", meta["name"], toJID.String(), msgID)
-			return nil
+
+			// The file is uploaded once, on the first send, and the same
+			// upload is sent to every recipient.
+			var prepared *app.PreparedFile
+			return runSendTo(ctx, flags, idemKey, rcpt, func(a *app.App, to types.JID, id types.MessageID) (sendResult, error) {
+				if prepared == nil {
+					p, err := a.PrepareFile(ctx, app.SendFileOptions{
+						Path:     filePath,
+						Filename: filename,
+						Caption:  caption,
+						MimeType: mimeOverride,
+					})
+					if err != nil {
+						return sendResult{}, err
+					}
+					prepared = p
+				}
+				sent, err := a.SendPreparedFile(ctx, to, prepared, id)
+				return sendResult{
+					ID: sent.ID,
+					File: &sentFileInfo{
+						Name:     sent.Name,
+						MimeType: sent.MimeType,
+						Media:    sent.MediaType,
+						Width:    sent.Width,
+						Height:   sent.Height,
+						Seconds:  sent.Seconds,
+					},
+				}, err
+			})
 		},
 	}
 
-	cmd.Flags().StringVar(&to, "to", "", "recipient phone number or JID")
+	rcpt.register(cmd)
 	cmd.Flags().StringVar(&filePath, "file", "", "path to file")
 	cmd.Flags().StringVar(&filename, "filename", "", "display name for the file (defaults to basename of --file)")
 	cmd.Flags().StringVar(&caption, "caption", "", "caption (images/videos/documents)")
 	cmd.Flags().StringVar(&mimeOverride, "mime", "", "override detected mime type")
+	cmd.Flags().StringVar(&at, "at", "", "schedule for later delivery via the outbox (e.g. 2026-11-01T09:00, local time)")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "send at most once per key; repeating a key returns the original result")
 	return cmd
 }
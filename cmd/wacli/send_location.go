@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func newSendLocationCmd(flags *rootFlags) *cobra.Command {
+	var to string
+	var loc app.Location
+	var idemKey string
+
+	cmd := &cobra.Command{
+		Use:   "location",
+		Short: "Send a location pin",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" || !cmd.Flags().Changed("lat") || !cmd.Flags().Changed("lng") {
+				return fmt.Errorf("--to, --lat and --lng are required")
+			}
+			toJID, err := wa.ParseUserOrJID(to)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			return runSend(ctx, flags, idemKey, toJID, func(a *app.App, id types.MessageID) (sendResult, error) {
+				msgID, err := a.SendLocation(ctx, toJID, loc, id)
+				return sendResult{ID: msgID}, err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "recipient phone number or JID")
+	cmd.Flags().Float64Var(&loc.Latitude, "lat", 0, "latitude in degrees")
+	cmd.Flags().Float64Var(&loc.Longitude, "lng", 0, "longitude in degrees")
+	cmd.Flags().StringVar(&loc.Name, "name", "", "place name")
+	cmd.Flags().StringVar(&loc.Address, "address", "", "place address")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "send at most once per key; repeating a key returns the original result")
+	return cmd
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// recipientFlags are the --to, --to-tag and --to-file flags of send commands
+// that accept several recipients.
+type recipientFlags struct {
+	to   []string
+	tag  string
+	file string
+}
+
+func (r *recipientFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&r.to, "to", nil, "recipient phone number or JID (repeatable; a broadcast-list JID sends to each of its known members)")
+	cmd.Flags().StringVar(&r.tag, "to-tag", "", "send to every contact with this tag")
+	cmd.Flags().StringVar(&r.file, "to-file", "", "send to every phone number or JID in this file (one per line, # comments)")
+}
+
+func (r *recipientFlags) validate() error {
+	if len(r.to) == 0 && r.tag == "" && r.file == "" {
+		return fmt.Errorf("--to, --to-tag or --to-file is required")
+	}
+	return nil
+}
+
+// many reports whether the recipients are a list, which switches output to a
+// per-recipient table.
+func (r *recipientFlags) many() bool {
+	return len(r.to) > 1 || r.tag != "" || r.file != ""
+}
+
+func (r *recipientFlags) options() app.RecipientOptions {
+	return app.RecipientOptions{To: r.to, Tag: r.tag, File: r.file}
+}
+
+// runSendTo is runSend for commands that take recipientFlags. With several
+// recipients it connects once, sends to each in turn and prints a result per
+// recipient. An idempotency key applies to each recipient separately.
+func runSendTo(ctx context.Context, flags *rootFlags, key string, rcpt recipientFlags, send func(a *app.App, to types.JID, id types.MessageID) (sendResult, error)) error {
+	a, lk, err := newApp(ctx, flags, true, false)
+	if err != nil {
+		return err
+	}
+	defer closeApp(a, lk)
+
+	targets, err := a.ResolveRecipients(rcpt.options())
+	if err != nil {
+		return err
+	}
+	key = strings.TrimSpace(key)
+	if !rcpt.many() {
+		to := targets[0]
+		if key != "" {
+			key = app.RecipientSendKey(key, to)
+		}
+		return sendOne(ctx, flags, a, key, to, func(a *app.App, id types.MessageID) (sendResult, error) {
+			return send(a, to, id)
+		})
+	}
+
+	if err := a.EnsureAuthed(); err != nil {
+		return err
+	}
+	if err := a.Connect(ctx, false, nil); err != nil {
+		return err
+	}
+
+	results := make([]sendResult, 0, len(targets))
+	failed := 0
+	for _, to := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		res := sendToRecipient(ctx, a, key, to, send)
+		if res.Error != "" {
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	var runErr error
+	if failed > 0 {
+		runErr = fmt.Errorf("%d of %d sends failed", failed, len(targets))
+	} else if len(results) < len(targets) {
+		runErr = fmt.Errorf("stopped after %d of %d recipients: %w", len(results), len(targets), ctx.Err())
+	}
+
+	if flags.asJSON {
+		if err := out.WriteJSON(os.Stdout, map[string]any{
+			"sent":    len(results) - failed,
+			"failed":  failed,
+			"results": results,
+		}); err != nil {
+			return err
+		}
+		return runErr
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TO\tSTATUS\tID\tERROR")
+	for _, r := range results {
+		status := "sent"
+		switch {
+		case r.Error != "":
+			status = "failed"
+		case r.Duplicate:
+			status = "already sent"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.To, status, r.ID, truncate(r.Error, 80))
+	}
+	_ = w.Flush()
+	fmt.Fprintf(os.Stdout, "Sent to %d of %d recipients.\n", len(results)-failed, len(targets))
+	return runErr
+}
+
+// sendToRecipient sends to one recipient of a list, guarded by
+// app.RecipientSendKey when key is set, and reports failure in the result instead of an error.
+func sendToRecipient(ctx context.Context, a *app.App, key string, to types.JID, send func(a *app.App, to types.JID, id types.MessageID) (sendResult, error)) sendResult {
+	fail := func(err error) sendResult {
+		return sendResult{To: to.String(), Error: err.Error()}
+	}
+
+	var id types.MessageID
+	if key != "" {
+		key = app.RecipientSendKey(key, to)
+		prev, err := a.DB().GetSendKey(key)
+		switch {
+		case err == nil && !prev.SentAt.IsZero():
+			var res sendResult
+			if err := json.Unmarshal([]byte(prev.Result), &res); err != nil {
+				return fail(fmt.Errorf("decode stored result for key %q: %w", key, err))
+			}
+			res.Duplicate = true
+			return res
+		case err != nil && !store.IsNotFound(err):
+			return fail(err)
+		}
+		if id, err = a.ReserveSendKey(key, to); err != nil {
+			return fail(err)
+		}
+	}
+
+	res, err := send(a, to, id)
+	if err != nil {
+		return fail(err)
+	}
+	res.Sent = true
+	res.To = to.String()
+	if key != "" {
+		res.IdempotencyKey = key
+		b, err := json.Marshal(res)
+		if err == nil {
+			err = a.DB().CompleteSendKey(key, string(res.ID), string(b))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: sent to %s, but failed to record idempotency key: %v\n", to, err)
+		}
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func newSendStickerCmd(flags *rootFlags) *cobra.Command {
+	var to string
+	var filePath string
+	var idemKey string
+
+	cmd := &cobra.Command{
+		Use:   "sticker",
+		Short: "Send a 512x512 WebP file as a sticker",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" || filePath == "" {
+				return fmt.Errorf("--to and --file are required")
+			}
+			toJID, err := wa.ParseUserOrJID(to)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			return runSend(ctx, flags, idemKey, toJID, func(a *app.App, id types.MessageID) (sendResult, error) {
+				sent, err := a.SendSticker(ctx, toJID, filePath, id)
+				return sendResult{
+					ID: sent.ID,
+					File: &sentFileInfo{
+						Name:     filepath.Base(filePath),
+						MimeType: "image/webp",
+						Media:    "sticker",
+						Width:    app.StickerSize,
+						Height:   app.StickerSize,
+						Animated: sent.Animated,
+					},
+				}, err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "recipient phone number or JID")
+	cmd.Flags().StringVar(&filePath, "file", "", "WebP file (512x512, static or animated)")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "send at most once per key; repeating a key returns the original result")
+	return cmd
+}
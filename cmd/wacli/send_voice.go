@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func newSendVoiceCmd(flags *rootFlags) *cobra.Command {
+	var to string
+	var filePath string
+	var idemKey string
+
+	cmd := &cobra.Command{
+		Use:   "voice",
+		Short: "Send an Ogg/Opus file as a voice note (push-to-talk)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" || filePath == "" {
+				return fmt.Errorf("--to and --file are required")
+			}
+			toJID, err := wa.ParseUserOrJID(to)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			return runSend(ctx, flags, idemKey, toJID, func(a *app.App, id types.MessageID) (sendResult, error) {
+				sent, err := a.SendVoice(ctx, toJID, filePath, id)
+				return sendResult{ID: sent.ID, Seconds: sent.Seconds}, err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "recipient phone number or JID")
+	cmd.Flags().StringVar(&filePath, "file", "", "Ogg/Opus audio file")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "send at most once per key; repeating a key returns the original result")
+	return cmd
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steipete/wacli/internal/app"
+	"github.com/steipete/wacli/internal/out"
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func newStatusCmd(flags *rootFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "View and post status updates (stories)",
+	}
+	cmd.AddCommand(newStatusListCmd(flags))
+	cmd.AddCommand(newStatusDownloadCmd(flags))
+	cmd.AddCommand(newStatusPostCmd(flags))
+	cmd.AddCommand(newStatusPrivacyCmd(flags))
+	return cmd
+}
+
+func newStatusListCmd(flags *rootFlags) *cobra.Command {
+	var from string
+	var since time.Duration
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List synced status updates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, false, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			p := store.ListMessagesParams{ChatJID: store.StatusJID, Limit: limit}
+			if from != "" {
+				jid, err := wa.ParseUserOrJID(from)
+				if err != nil {
+					return err
+				}
+				p.From = jid.String()
+			}
+			if since > 0 {
+				after := time.Now().Add(-since)
+				p.After = &after
+			}
+			msgs, err := a.DB().ListMessages(p)
+			if err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, map[string]any{"statuses": msgs})
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tFROM\tTYPE\tID\tTEXT")
+			for _, m := range msgs {
+				fromLabel := m.SenderJID
+				if m.FromMe {
+					fromLabel = "me"
+				}
+				kind := m.MediaType
+				if kind == "" {
+					kind = "text"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					m.Timestamp.Local().Format("2006-01-02 15:04:05"),
+					truncate(fromLabel, 24),
+					kind,
+					truncate(m.MsgID, 14),
+					truncate(m.Text, 80),
+				)
+			}
+			_ = w.Flush()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "only statuses posted by this phone number or JID")
+	cmd.Flags().DurationVar(&since, "since", 24*time.Hour, "only statuses posted within this long (0 for all)")
+	cmd.Flags().IntVar(&limit, "limit", 50, "limit results")
+	return cmd
+}
+
+func newStatusDownloadCmd(flags *rootFlags) *cobra.Command {
+	var id string
+	var outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "download",
+		Short: "Download the image or video of a status update",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if id == "" {
+				return fmt.Errorf("--id is required")
+			}
+			return downloadMedia(flags, store.StatusJID, id, outputPath)
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "status message ID (from `status list`)")
+	cmd.Flags().StringVar(&outputPath, "output", "", "output file or directory (default: store media dir)")
+	return cmd
+}
+
+func newStatusPostCmd(flags *rootFlags) *cobra.Command {
+	var text string
+	var filePath string
+	var caption string
+	var mimeOverride string
+	var background string
+	var audience string
+	var idemKey string
+
+	cmd := &cobra.Command{
+		Use:   "post",
+		Short: "Post a text, image or video status update",
+		Long: "Posts to the audience chosen in the phone's status privacy settings (My contacts,\n" +
+			"My contacts except..., Only share with...); linked devices can't pick an audience per post.\n" +
+			"--audience makes the post fail instead if that setting is not what the script expects.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (text == "") == (filePath == "") {
+				return fmt.Errorf("exactly one of --text or --file is required")
+			}
+			if caption != "" && filePath == "" {
+				return fmt.Errorf("--caption needs --file")
+			}
+			opts := app.StatusPostOptions{
+				Text:     text,
+				File:     filePath,
+				Caption:  caption,
+				MimeType: mimeOverride,
+				Audience: strings.ToLower(strings.TrimSpace(audience)),
+			}
+			if background != "" {
+				bg, err := parseColor(background)
+				if err != nil {
+					return err
+				}
+				opts.Background = bg
+			}
+
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			return runSend(ctx, flags, idemKey, types.StatusBroadcastJID, func(a *app.App, id types.MessageID) (sendResult, error) {
+				opts.ID = id
+				posted, err := a.PostStatus(ctx, opts)
+				res := sendResult{ID: posted.ID, Audience: &posted.Audience}
+				if posted.File != nil {
+					res.File = &sentFileInfo{
+						Name:     posted.File.Name,
+						MimeType: posted.File.MimeType,
+						Media:    posted.File.MediaType,
+						Width:    posted.File.Width,
+						Height:   posted.File.Height,
+						Seconds:  posted.File.Seconds,
+					}
+				}
+				return res, err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&text, "text", "", "post a text status")
+	cmd.Flags().StringVar(&filePath, "file", "", "post an image or video")
+	cmd.Flags().StringVar(&caption, "caption", "", "caption for --file")
+	cmd.Flags().StringVar(&mimeOverride, "mime", "", "override detected mime type")
+	cmd.Flags().StringVar(&background, "background", "", "background color of a text status (#RRGGBB)")
+	cmd.Flags().StringVar(&audience, "audience", "", "required status audience: contacts|except|only (fails if the phone's setting differs)")
+	cmd.Flags().StringVar(&idemKey, "idempotency-key", "", "post at most once per key; repeating a key returns the original result")
+	return cmd
+}
+
+func newStatusPrivacyCmd(flags *rootFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "privacy",
+		Short: "Show who status updates are shared with",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := withTimeout(context.Background(), flags)
+			defer cancel()
+
+			a, lk, err := newApp(ctx, flags, true, false)
+			if err != nil {
+				return err
+			}
+			defer closeApp(a, lk)
+
+			if err := a.EnsureAuthed(); err != nil {
+				return err
+			}
+			if err := a.Connect(ctx, false, nil); err != nil {
+				return err
+			}
+			aud, err := a.StatusAudience(ctx)
+			if err != nil {
+				return err
+			}
+
+			if flags.asJSON {
+				return out.WriteJSON(os.Stdout, aud)
+			}
+			fmt.Fprintf(os.Stdout, "Audience: %s\n", aud.Type)
+			for _, jid := range aud.JIDs {
+				fmt.Fprintf(os.Stdout, "  %s\n", jid)
+			}
+			return nil
+		},
+	}
+}
+
+// parseColor parses #RRGGBB into an opaque ARGB value.
+func parseColor(s string) (uint32, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("invalid color %q (want #RRGGBB)", s)
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid color %q (want #RRGGBB)", s)
+	}
+	return 0xFF000000 | uint32(rgb), nil
+}
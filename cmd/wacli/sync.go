@@ -18,6 +18,9 @@ func newSyncCmd(flags *rootFlags) *cobra.Command {
 	var follow bool
 	var idleExit time.Duration
 	var downloadMedia bool
+	var skipViewOnce bool
+	var rejectCalls bool
+	var rejectMessage string
 	var refreshContacts bool
 	var refreshGroups bool
 
@@ -25,6 +28,21 @@ func newSyncCmd(flags *rootFlags) *cobra.Command {
 		Use:   "sync",
 		Short: "Sync messages (requires prior auth; never shows QR)",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if rejectMessage != "" && !rejectCalls {
+				return fmt.Errorf("--reject-message needs --reject-calls")
+			}
+			mode := appPkg.SyncModeFollow
+			if once {
+				mode = appPkg.SyncModeOnce
+			} else if follow {
+				mode = appPkg.SyncModeFollow
+			} else {
+				mode = appPkg.SyncModeOnce
+			}
+			if rejectCalls && mode != appPkg.SyncModeFollow {
+				return fmt.Errorf("--reject-calls needs --follow")
+			}
+
 			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 			defer stop()
 
@@ -38,19 +56,13 @@ func newSyncCmd(flags *rootFlags) *cobra.Command {
 				return err
 			}
 
-			mode := appPkg.SyncModeFollow
-			if once {
-				mode = appPkg.SyncModeOnce
-			} else if follow {
-				mode = appPkg.SyncModeFollow
-			} else {
-				mode = appPkg.SyncModeOnce
-			}
-
 			res, err := a.Sync(ctx, appPkg.SyncOptions{
 				Mode:            mode,
 				AllowQR:         false,
 				DownloadMedia:   downloadMedia,
+				SkipViewOnce:    skipViewOnce,
+				RejectCalls:     rejectCalls,
+				RejectMessage:   rejectMessage,
 				RefreshContacts: refreshContacts,
 				RefreshGroups:   refreshGroups,
 				IdleExit:        idleExit,
@@ -74,6 +86,9 @@ func newSyncCmd(flags *rootFlags) *cobra.Command {
 	cmd.Flags().BoolVar(&follow, "follow", true, "keep syncing until Ctrl+C")
 	cmd.Flags().DurationVar(&idleExit, "idle-exit", 30*time.Second, "exit after being idle (once mode)")
 	cmd.Flags().BoolVar(&downloadMedia, "download-media", false, "download media in the background during sync")
+	cmd.Flags().BoolVar(&skipViewOnce, "skip-view-once", false, "never download view-once media (with --download-media)")
+	cmd.Flags().BoolVar(&rejectCalls, "reject-calls", false, "decline incoming voice/video calls (follow mode)")
+	cmd.Flags().StringVar(&rejectMessage, "reject-message", "", "text sent to callers after declining their call (with --reject-calls)")
 	cmd.Flags().BoolVar(&refreshContacts, "refresh-contacts", false, "refresh contacts from session store into local DB")
 	cmd.Flags().BoolVar(&refreshGroups, "refresh-groups", false, "refresh joined groups (live) into local DB")
 	return cmd
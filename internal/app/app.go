@@ -3,10 +3,13 @@ package app
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/steipete/wacli/internal/linkpreview"
 	"github.com/steipete/wacli/internal/store"
 	"github.com/steipete/wacli/internal/wa"
 	"go.mau.fi/whatsmeow"
@@ -28,17 +31,34 @@ type WAClient interface {
 	ResolveChatName(ctx context.Context, chat types.JID, pushName string) string
 	GetContact(ctx context.Context, jid types.JID) (types.ContactInfo, error)
 	GetAllContacts(ctx context.Context) (map[types.JID]types.ContactInfo, error)
+	IsOnWhatsApp(ctx context.Context, phones []string) ([]types.IsOnWhatsAppResponse, error)
+	GetUserInfo(ctx context.Context, jids []types.JID) (map[types.JID]types.UserInfo, error)
+	GetProfilePictureInfo(ctx context.Context, jid types.JID, existingID string) (*types.ProfilePictureInfo, error)
+	GetBlocklist(ctx context.Context) (*types.Blocklist, error)
+	GetPNForLID(ctx context.Context, lid types.JID) (types.JID, error)
+	GetBusinessProfile(ctx context.Context, jid types.JID) (*wa.BusinessProfile, error)
+	UpdateBlocklist(ctx context.Context, jid types.JID, block bool) (*types.Blocklist, error)
+	GetStatusPrivacy(ctx context.Context) ([]types.StatusPrivacy, error)
+	RejectCall(ctx context.Context, from types.JID, callID string) error
+	OwnJID() types.JID
 
 	GetJoinedGroups(ctx context.Context) ([]*types.GroupInfo, error)
 	GetGroupInfo(ctx context.Context, jid types.JID) (*types.GroupInfo, error)
 	SetGroupName(ctx context.Context, jid types.JID, name string) error
 	UpdateGroupParticipants(ctx context.Context, group types.JID, users []types.JID, action wa.GroupParticipantAction) ([]types.GroupParticipant, error)
 	GetGroupInviteLink(ctx context.Context, group types.JID, reset bool) (string, error)
+	GetGroupInfoFromLink(ctx context.Context, code string) (*types.GroupInfo, error)
 	JoinGroupWithLink(ctx context.Context, code string) (types.JID, error)
 	LeaveGroup(ctx context.Context, group types.JID) error
+	CreateGroup(ctx context.Context, req whatsmeow.ReqCreateGroup) (*types.GroupInfo, error)
+	LinkGroup(ctx context.Context, parent, child types.JID) error
+	UnlinkGroup(ctx context.Context, parent, child types.JID) error
+	GetSubGroups(ctx context.Context, community types.JID) ([]*types.GroupLinkTarget, error)
 
 	SendText(ctx context.Context, to types.JID, text string) (types.MessageID, error)
 	SendProtoMessage(ctx context.Context, to types.JID, msg *waProto.Message) (types.MessageID, error)
+	SendProtoMessageWithID(ctx context.Context, to types.JID, msg *waProto.Message, id types.MessageID) (types.MessageID, error)
+	GenerateMessageID() (types.MessageID, error)
 	Upload(ctx context.Context, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error)
 	DownloadMediaToFile(ctx context.Context, directPath string, encFileHash, fileHash, mediaKey []byte, fileLength uint64, mediaType, mmsType string, targetPath string) (int64, error)
 
@@ -58,6 +78,10 @@ type App struct {
 	opts Options
 	wa   WAClient
 	db   *store.DB
+	// links fetches pages for link previews; tests swap in an httptest client.
+	links linkpreview.Fetcher
+	// bg tracks work started from event handlers; Sync waits for it.
+	bg sync.WaitGroup
 }
 
 func New(opts Options) (*App, error) {
@@ -75,7 +99,17 @@ func New(opts Options) (*App, error) {
 		return nil, err
 	}
 
-	return &App{opts: opts, db: db}, nil
+	return &App{opts: opts, db: db, links: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+// goBackground runs fn off the event handler. Sync doesn't return until every
+// such goroutine has finished, so none of them outlives the store.
+func (a *App) goBackground(fn func()) {
+	a.bg.Add(1)
+	go func() {
+		defer a.bg.Done()
+		fn()
+	}()
 }
 
 func (a *App) OpenWA() error {
@@ -110,7 +144,7 @@ func (a *App) EnsureAuthed() error {
 	if a.wa.IsAuthed() {
 		return nil
 	}
-	return fmt.Errorf("not authenticated; run `wacli auth`")
+	return wa.ErrNotAuthenticated
 }
 
 func (a *App) WA() WAClient        { return a.wa }
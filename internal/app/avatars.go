@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steipete/wacli/internal/pathutil"
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+type AvatarResult struct {
+	JID       string `json:"jid"`
+	PictureID string `json:"picture_id,omitempty"`
+	Path      string `json:"path,omitempty"`
+	// Status is one of downloaded, unchanged, not_set or hidden.
+	Status string `json:"status"`
+}
+
+// FetchAvatar downloads the profile picture of a user or group into the store's
+// media dir. When the cached picture ID is still current the download is
+// skipped, unless force is set.
+func (a *App) FetchAvatar(ctx context.Context, jid types.JID, force bool) (AvatarResult, error) {
+	res := AvatarResult{JID: jid.String()}
+
+	cached, err := a.db.GetAvatar(jid.String())
+	if err != nil && !store.IsNotFound(err) {
+		return res, err
+	}
+	existingID := ""
+	if err == nil && !force {
+		if _, statErr := os.Stat(cached.Path); statErr == nil {
+			existingID = cached.PictureID
+		}
+	}
+
+	info, err := a.wa.GetProfilePictureInfo(ctx, jid, existingID)
+	switch {
+	case errors.Is(err, whatsmeow.ErrProfilePictureNotSet):
+		res.Status = "not_set"
+		if cached.Path != "" {
+			_ = os.Remove(cached.Path)
+			_ = a.db.DeleteAvatar(jid.String())
+		}
+		return res, nil
+	case errors.Is(err, whatsmeow.ErrProfilePictureUnauthorized):
+		res.Status = "hidden"
+		return res, nil
+	case err != nil:
+		return res, err
+	case info == nil:
+		res.Status = "unchanged"
+		res.PictureID = cached.PictureID
+		res.Path = cached.Path
+		return res, nil
+	}
+
+	path := a.avatarPath(jid, info.ID)
+	if err := downloadURL(ctx, info.URL, path); err != nil {
+		return res, fmt.Errorf("download avatar: %w", err)
+	}
+	if cached.Path != "" && cached.Path != path {
+		_ = os.Remove(cached.Path)
+	}
+	if err := a.db.SaveAvatar(store.Avatar{JID: jid.String(), PictureID: info.ID, Path: path}); err != nil {
+		return res, err
+	}
+	res.Status = "downloaded"
+	res.PictureID = info.ID
+	res.Path = path
+	return res, nil
+}
+
+// RefreshAbout fetches the "about" text of users and stores it on their
+// contact rows. Groups are ignored.
+func (a *App) RefreshAbout(ctx context.Context, jids []types.JID) (map[types.JID]string, error) {
+	var users []types.JID
+	for _, j := range jids {
+		if j.Server != types.GroupServer {
+			users = append(users, j)
+		}
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	infos, err := a.wa.GetUserInfo(ctx, users)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[types.JID]string, len(infos))
+	for j, info := range infos {
+		if err := a.db.SetContactAbout(j.String(), info.Status); err != nil {
+			return out, err
+		}
+		out[j] = info.Status
+	}
+	return out, nil
+}
+
+func (a *App) avatarPath(jid types.JID, pictureID string) string {
+	dir := filepath.Join(a.opts.StoreDir, "media", "avatars", pathutil.SanitizeSegment(jid.String()))
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Join(dir, pathutil.SanitizeFilename(pictureID)+".jpg")
+}
+
+func downloadURL(ctx context.Context, url, targetPath string) error {
+	if strings.TrimSpace(url) == "" {
+		return fmt.Errorf("empty url")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0700); err != nil {
+		return err
+	}
+	tmp := targetPath + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, targetPath)
+}
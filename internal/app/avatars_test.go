@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestFetchAvatarDownloadsOnceAndSkipsUnchanged(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		_, _ = w.Write([]byte("jpeg-bytes"))
+	}))
+	defer srv.Close()
+
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	jid := types.JID{User: "111", Server: types.DefaultUserServer}
+	f.pictures[jid] = &types.ProfilePictureInfo{ID: "pic1", URL: srv.URL + "/a.jpg"}
+
+	ctx := context.Background()
+	res, err := a.FetchAvatar(ctx, jid, false)
+	if err != nil {
+		t.Fatalf("FetchAvatar: %v", err)
+	}
+	if res.Status != "downloaded" || res.PictureID != "pic1" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if b, err := os.ReadFile(res.Path); err != nil || string(b) != "jpeg-bytes" {
+		t.Fatalf("expected avatar file, got %q (err=%v)", b, err)
+	}
+
+	res, err = a.FetchAvatar(ctx, jid, false)
+	if err != nil {
+		t.Fatalf("FetchAvatar (2): %v", err)
+	}
+	if res.Status != "unchanged" || atomic.LoadInt32(&hits) != 1 {
+		t.Fatalf("expected cached avatar to be reused, got %+v after %d downloads", res, hits)
+	}
+
+	// A new picture replaces the old file.
+	old := res.Path
+	f.pictures[jid] = &types.ProfilePictureInfo{ID: "pic2", URL: srv.URL + "/b.jpg"}
+	res, err = a.FetchAvatar(ctx, jid, false)
+	if err != nil {
+		t.Fatalf("FetchAvatar (3): %v", err)
+	}
+	if res.Status != "downloaded" || res.PictureID != "pic2" {
+		t.Fatalf("expected new download, got %+v", res)
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Fatalf("expected old avatar to be removed")
+	}
+}
+
+func TestFetchAvatarHiddenAndNotSet(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	hidden := types.JID{User: "222", Server: types.DefaultUserServer}
+	f.pictureErr[hidden] = whatsmeow.ErrProfilePictureUnauthorized
+
+	res, err := a.FetchAvatar(context.Background(), hidden, false)
+	if err != nil || res.Status != "hidden" {
+		t.Fatalf("expected hidden, got %+v (err=%v)", res, err)
+	}
+	res, err = a.FetchAvatar(context.Background(), types.JID{User: "333", Server: types.DefaultUserServer}, false)
+	if err != nil || res.Status != "not_set" {
+		t.Fatalf("expected not_set, got %+v (err=%v)", res, err)
+	}
+}
+
+func TestRefreshAboutStoresStatusText(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	jid := types.JID{User: "111", Server: types.DefaultUserServer}
+	f.userInfo[jid] = types.UserInfo{Status: "Hey there!"}
+
+	if _, err := a.RefreshAbout(context.Background(), []types.JID{jid, {User: "123", Server: types.GroupServer}}); err != nil {
+		t.Fatalf("RefreshAbout: %v", err)
+	}
+	c, err := a.DB().GetContact(jid.String())
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	if c.About != "Hey there!" {
+		t.Fatalf("expected about text, got %q", c.About)
+	}
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// RefreshBlocklist replaces the local blocklist with the one from WhatsApp.
+func (a *App) RefreshBlocklist(ctx context.Context) ([]types.JID, error) {
+	bl, err := a.wa.GetBlocklist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return a.saveBlocklist(bl)
+}
+
+// SetBlocked blocks or unblocks jid on WhatsApp and mirrors the resulting
+// blocklist locally.
+func (a *App) SetBlocked(ctx context.Context, jid types.JID, block bool) error {
+	bl, err := a.wa.UpdateBlocklist(ctx, jid, block)
+	if err != nil {
+		return err
+	}
+	if bl == nil {
+		return a.db.SetBlocked(jid.String(), block)
+	}
+	_, err = a.saveBlocklist(bl)
+	return err
+}
+
+func (a *App) saveBlocklist(bl *types.Blocklist) ([]types.JID, error) {
+	if bl == nil {
+		return nil, nil
+	}
+	jids := make([]string, 0, len(bl.JIDs))
+	for _, j := range bl.JIDs {
+		jids = append(jids, j.ToNonAD().String())
+	}
+	return bl.JIDs, a.db.ReplaceBlocklist(jids)
+}
+
+// applyBlocklistEvent updates the local blocklist from a live change. A
+// "modify" event carries no changes, so the whole list is fetched again.
+func (a *App) applyBlocklistEvent(ctx context.Context, evt *events.Blocklist) {
+	if evt.Action == events.BlocklistActionModify || len(evt.Changes) == 0 {
+		a.goBackground(func() { _, _ = a.RefreshBlocklist(ctx) })
+		return
+	}
+	for _, ch := range evt.Changes {
+		switch ch.Action {
+		case events.BlocklistChangeActionBlock:
+			_ = a.db.SetBlocked(ch.JID.ToNonAD().String(), true)
+		case events.BlocklistChangeActionUnblock:
+			_ = a.db.SetBlocked(ch.JID.ToNonAD().String(), false)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func TestSetBlockedMirrorsBlocklist(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	ctx := context.Background()
+	spam := types.JID{User: "111", Server: types.DefaultUserServer}
+	f.blocked = []types.JID{{User: "999", Server: types.DefaultUserServer}}
+
+	if err := a.SetBlocked(ctx, spam, true); err != nil {
+		t.Fatalf("SetBlocked: %v", err)
+	}
+	list, err := a.DB().ListBlocked()
+	if err != nil {
+		t.Fatalf("ListBlocked: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected full remote blocklist to be mirrored, got %+v", list)
+	}
+
+	if err := a.SetBlocked(ctx, spam, false); err != nil {
+		t.Fatalf("SetBlocked(false): %v", err)
+	}
+	if blocked, _ := a.DB().IsBlocked(spam.String()); blocked {
+		t.Fatalf("expected %s to be unblocked", spam)
+	}
+}
+
+func TestSyncAppliesBlocklistEvents(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+
+	blocked := types.JID{User: "111", Server: types.DefaultUserServer}
+	unblocked := types.JID{User: "222", Server: types.DefaultUserServer}
+	if err := a.DB().SetBlocked(unblocked.String(), true); err != nil {
+		t.Fatalf("SetBlocked: %v", err)
+	}
+	f.connectEvents = []interface{}{&events.Blocklist{Changes: []events.BlocklistChange{
+		{JID: blocked, Action: events.BlocklistChangeActionBlock},
+		{JID: unblocked, Action: events.BlocklistChangeActionUnblock},
+	}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	if ok, _ := a.DB().IsBlocked(blocked.String()); !ok {
+		t.Fatalf("expected %s to be blocked", blocked)
+	}
+	if ok, _ := a.DB().IsBlocked(unblocked.String()); ok {
+		t.Fatalf("expected %s to be unblocked", unblocked)
+	}
+}
+
+func TestSyncRefreshesBlocklistOnModify(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+
+	spam := types.JID{User: "333", Server: types.DefaultUserServer}
+	f.blocked = []types.JID{spam}
+	f.connectEvents = []interface{}{&events.Blocklist{Action: events.BlocklistActionModify}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// The refresh runs off the event handler; Sync waits for it.
+	if ok, _ := a.DB().IsBlocked(spam.String()); !ok {
+		t.Fatalf("expected %s to be blocked after the refresh", spam)
+	}
+}
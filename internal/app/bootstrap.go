@@ -3,6 +3,8 @@ package app
 import (
 	"context"
 	"time"
+
+	"go.mau.fi/whatsmeow/types"
 )
 
 func (a *App) refreshContacts(ctx context.Context) error {
@@ -14,9 +16,14 @@ func (a *App) refreshContacts(ctx context.Context) error {
 		return err
 	}
 	for jid, info := range contacts {
+		jid = a.resolveLID(ctx, jid, types.JID{})
+		phone := ""
+		if jid.Server == types.DefaultUserServer {
+			phone = jid.User
+		}
 		_ = a.db.UpsertContact(
 			jid.String(),
-			jid.User,
+			phone,
 			info.PushName,
 			info.FullName,
 			info.FirstName,
@@ -40,6 +47,7 @@ func (a *App) refreshGroups(ctx context.Context) error {
 			continue
 		}
 		_ = a.db.UpsertGroup(g.JID.String(), g.GroupName.Name, g.OwnerJID.String(), g.GroupCreated)
+		_ = a.db.SetGroupCommunity(g.JID.String(), g.IsParent, g.LinkedParentJID.String())
 		_ = a.db.UpsertChat(g.JID.String(), "group", g.GroupName.Name, now)
 	}
 	return nil
@@ -65,3 +65,40 @@ func TestRefreshGroupsStoresGroupsAndChats(t *testing.T) {
 		t.Fatalf("expected chat kind group, got %q", c.Kind)
 	}
 }
+
+func TestRefreshGroupsStoresCommunityLinks(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+
+	parent := types.JID{User: "100", Server: types.GroupServer}
+	child := types.JID{User: "200", Server: types.GroupServer}
+	f.groups[parent] = &types.GroupInfo{
+		JID:         parent,
+		GroupName:   types.GroupName{Name: "Community"},
+		GroupParent: types.GroupParent{IsParent: true},
+	}
+	f.groups[child] = &types.GroupInfo{
+		JID:               child,
+		GroupName:         types.GroupName{Name: "Subgroup"},
+		GroupLinkedParent: types.GroupLinkedParent{LinkedParentJID: parent},
+	}
+
+	if err := a.refreshGroups(context.Background()); err != nil {
+		t.Fatalf("refreshGroups: %v", err)
+	}
+	cs, err := a.db.ListCommunities("", 10)
+	if err != nil {
+		t.Fatalf("ListCommunities: %v", err)
+	}
+	if len(cs) != 1 || cs[0].JID != parent.String() || cs[0].SubGroups != 1 {
+		t.Fatalf("expected community with one linked group, got %+v", cs)
+	}
+	g, err := a.db.GetGroup(child.String())
+	if err != nil {
+		t.Fatalf("GetGroup: %v", err)
+	}
+	if g.ParentName != "Community" {
+		t.Fatalf("expected parent name Community, got %q", g.ParentName)
+	}
+}
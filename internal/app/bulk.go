@@ -0,0 +1,335 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// BulkMessage is one rendered row of a bulk send. Row is the 1-based data row
+// in the CSV (the header is not counted).
+type BulkMessage struct {
+	Row        int               `json:"row"`
+	To         string            `json:"to"`
+	Text       string            `json:"text"`
+	Attachment string            `json:"attachment,omitempty"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+type BulkLoadOptions struct {
+	ToColumn         string // recipient column; defaults to "phone"
+	AttachmentColumn string // optional per-row file path column
+}
+
+// LoadBulkMessages renders tmpl (Go text/template) once per CSV row, with the
+// row's columns as fields ({{.name}}). Relative attachment paths are resolved
+// against the CSV's directory and made absolute. Any row that fails to parse or render fails
+// the whole load, so nothing is sent from a broken input.
+func LoadBulkMessages(csvPath, tmpl string, opts BulkLoadOptions) ([]BulkMessage, error) {
+	if opts.ToColumn == "" {
+		opts.ToColumn = "phone"
+	}
+	t, err := template.New("message").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: read header: %w", csvPath, err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+	if !containsString(header, opts.ToColumn) {
+		return nil, fmt.Errorf("%s: no %q column (use --to-column)", csvPath, opts.ToColumn)
+	}
+	if opts.AttachmentColumn != "" && !containsString(header, opts.AttachmentColumn) {
+		return nil, fmt.Errorf("%s: no %q column", csvPath, opts.AttachmentColumn)
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(csvPath))
+	if err != nil {
+		return nil, err
+	}
+	var out []BulkMessage
+	for row := 1; ; row++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", csvPath, err)
+		}
+		fields := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(rec) {
+				fields[name] = strings.TrimSpace(rec[i])
+			}
+		}
+
+		to, err := parseBulkRecipient(fields[opts.ToColumn])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		var b strings.Builder
+		if err := t.Execute(&b, fields); err != nil {
+			return nil, fmt.Errorf("row %d: render template: %w", row, err)
+		}
+		msg := BulkMessage{Row: row, To: to.String(), Text: strings.TrimSpace(b.String()), Fields: fields}
+		if opts.AttachmentColumn != "" {
+			if p := fields[opts.AttachmentColumn]; p != "" {
+				if !filepath.IsAbs(p) {
+					p = filepath.Join(baseDir, p)
+				}
+				if _, err := os.Stat(p); err != nil {
+					return nil, fmt.Errorf("row %d: %w", row, err)
+				}
+				msg.Attachment = p
+			}
+		}
+		if msg.Text == "" && msg.Attachment == "" {
+			return nil, fmt.Errorf("row %d: rendered message is empty", row)
+		}
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+type BulkSendOptions struct {
+	Interval time.Duration // minimum pause between sends
+	Jitter   time.Duration // random extra pause, up to this much
+	// ProgressPath is an append-only JSON-lines log of sent rows. Rows logged
+	// as sent are skipped, so re-running continues an interrupted send.
+	ProgressPath string
+	OnResult     func(BulkResult)
+}
+
+type BulkResult struct {
+	Row    int             `json:"row"`
+	Key    string          `json:"key"` // identifies the row by content; see bulkRowKeys
+	To     string          `json:"to"`
+	Status string          `json:"status"` // sent, skipped or failed
+	ID     types.MessageID `json:"id,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	At     time.Time       `json:"at"`
+}
+
+// SendBulk sends msgs in order, pacing them by Interval plus jitter. Rows are
+// identified by content rather than position, so editing the CSV between runs
+// neither resends nor skips a row. Each row is guarded by an idempotency key
+// derived from the absolute progress log path and the row's content, so a
+// crash between sending and logging does not send the row twice on resume.
+func (a *App) SendBulk(ctx context.Context, msgs []BulkMessage, opts BulkSendOptions) ([]BulkResult, error) {
+	done, err := loadBulkProgress(opts.ProgressPath)
+	if err != nil {
+		return nil, err
+	}
+	campaign := opts.ProgressPath
+	if campaign != "" {
+		if campaign, err = filepath.Abs(campaign); err != nil {
+			return nil, err
+		}
+	}
+	keys := bulkRowKeys(msgs)
+	var logFile *os.File
+	if opts.ProgressPath != "" {
+		logFile, err = os.OpenFile(opts.ProgressPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open progress log: %w", err)
+		}
+		defer logFile.Close()
+	}
+
+	report := func(res BulkResult) error {
+		if opts.OnResult != nil {
+			opts.OnResult(res)
+		}
+		if logFile == nil || res.Status == "skipped" {
+			return nil
+		}
+		b, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if _, err := logFile.Write(append(b, '\n')); err != nil {
+			return fmt.Errorf("write progress log: %w", err)
+		}
+		return logFile.Sync()
+	}
+
+	results := make([]BulkResult, 0, len(msgs))
+	sentAny := false
+	for i, m := range msgs {
+		if done[keys[i]] {
+			res := BulkResult{Row: m.Row, Key: keys[i], To: m.To, Status: "skipped", At: time.Now().UTC()}
+			results = append(results, res)
+			_ = report(res)
+			continue
+		}
+		if sentAny {
+			if err := sleepCtx(ctx, opts.Interval+jitter(opts.Jitter)); err != nil {
+				return results, err
+			}
+		}
+		sentAny = true
+
+		res := BulkResult{Row: m.Row, Key: keys[i], To: m.To}
+		id, err := a.sendBulkMessage(ctx, m, bulkSendKey(campaign, keys[i]))
+		res.At = time.Now().UTC()
+		if err != nil {
+			res.Status = "failed"
+			res.Error = err.Error()
+		} else {
+			res.Status = "sent"
+			res.ID = id
+		}
+		results = append(results, res)
+		if err := report(res); err != nil {
+			return results, err
+		}
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+func (a *App) sendBulkMessage(ctx context.Context, m BulkMessage, key string) (types.MessageID, error) {
+	to, err := types.ParseJID(m.To)
+	if err != nil {
+		return "", err
+	}
+	id, err := a.ReserveSendKey(key, to)
+	if err != nil {
+		return "", err
+	}
+	if k, err := a.db.GetSendKey(key); err == nil && !k.SentAt.IsZero() {
+		return types.MessageID(k.MsgID), nil
+	}
+	if m.Attachment != "" {
+		sent, err := a.SendFile(ctx, to, SendFileOptions{Path: m.Attachment, Caption: m.Text, ID: id})
+		if err != nil {
+			return "", err
+		}
+		id = sent.ID
+	} else if id, err = a.SendText(ctx, to, m.Text, id); err != nil {
+		return "", err
+	}
+	if err := a.db.CompleteSendKey(key, string(id), ""); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// parseBulkRecipient accepts a JID or a phone number in any common notation.
+func parseBulkRecipient(s string) (types.JID, error) {
+	if strings.Contains(s, "@") {
+		return wa.ParseUserOrJID(s)
+	}
+	phone, err := wa.NormalizePhone(s)
+	if err != nil {
+		return types.JID{}, err
+	}
+	return types.NewJID(phone, types.DefaultUserServer), nil
+}
+
+func loadBulkProgress(path string) (map[string]bool, error) {
+	done := map[string]bool{}
+	if path == "" {
+		return done, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open progress log: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var res BulkResult
+		// A torn last line from a crash is ignored; that row is re-checked
+		// against its idempotency key.
+		if json.Unmarshal(sc.Bytes(), &res) != nil {
+			continue
+		}
+		if res.Status == "sent" && res.Key != "" {
+			done[res.Key] = true
+		}
+	}
+	return done, sc.Err()
+}
+
+// bulkRowKeys identifies each row by recipient, text and attachment. The
+// n-th repeat of an identical row gets its own key, so deliberate duplicates
+// are still sent once each.
+func bulkRowKeys(msgs []BulkMessage) []string {
+	keys := make([]string, len(msgs))
+	seen := map[string]int{}
+	for i, m := range msgs {
+		content := m.To + "\x00" + m.Text + "\x00" + m.Attachment
+		n := seen[content]
+		seen[content]++
+		h := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", content, n)))
+		keys[i] = hex.EncodeToString(h[:16])
+	}
+	return keys
+}
+
+func bulkSendKey(campaign, rowKey string) string {
+	h := sha256.Sum256([]byte(campaign + "\x00" + rowKey))
+	return "bulk:" + hex.EncodeToString(h[:16])
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBulkCSV(t *testing.T, dir, body string) string {
+	t.Helper()
+	p := filepath.Join(dir, "r.csv")
+	if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	return p
+}
+
+func TestLoadBulkMessagesRendersRows(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "invoice.pdf"), []byte("%PDF"), 0o600); err != nil {
+		t.Fatalf("write attachment: %v", err)
+	}
+	csvPath := writeBulkCSV(t, dir, "phone,name,file\n+1 (555) 010-0100,Ann,\n15550101,Bob,invoice.pdf\n")
+
+	msgs, err := LoadBulkMessages(csvPath, "Hi {{.name}}!\n", BulkLoadOptions{AttachmentColumn: "file"})
+	if err != nil {
+		t.Fatalf("LoadBulkMessages: %v", err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	if msgs[0].To != "15550100100@s.whatsapp.net" || msgs[0].Text != "Hi Ann!" || msgs[0].Attachment != "" {
+		t.Fatalf("unexpected first message: %+v", msgs[0])
+	}
+	if msgs[1].Row != 2 || msgs[1].Attachment != filepath.Join(dir, "invoice.pdf") {
+		t.Fatalf("expected attachment resolved against the CSV dir, got %+v", msgs[1])
+	}
+
+	if _, err := LoadBulkMessages(csvPath, "Hi {{.nickname}}", BulkLoadOptions{}); err == nil || !strings.Contains(err.Error(), "row 1") {
+		t.Fatalf("expected missing field to fail at row 1, got %v", err)
+	}
+	if _, err := LoadBulkMessages(csvPath, "Hi", BulkLoadOptions{ToColumn: "mobile"}); err == nil {
+		t.Fatalf("expected missing recipient column to fail")
+	}
+}
+
+func TestSendBulkResumesFromProgressLog(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	dir := t.TempDir()
+	csvPath := writeBulkCSV(t, dir, "phone,name\n15550100,Ann\n15550101,Bob\n15550102,Cid\n")
+	msgs, err := LoadBulkMessages(csvPath, "Hi {{.name}}", BulkLoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadBulkMessages: %v", err)
+	}
+	progress := filepath.Join(dir, "progress.jsonl")
+	ctx := context.Background()
+
+	// Row 2 fails permanently on the first run.
+	f.sendErrs = []error{nil, errors.New("rejected")}
+	results, err := a.SendBulk(ctx, msgs, BulkSendOptions{ProgressPath: progress})
+	if err != nil {
+		t.Fatalf("SendBulk: %v", err)
+	}
+	if got := bulkStatuses(results); got != "sent,failed,sent" {
+		t.Fatalf("unexpected first run: %s", got)
+	}
+
+	results, err = a.SendBulk(ctx, msgs, BulkSendOptions{ProgressPath: progress})
+	if err != nil {
+		t.Fatalf("SendBulk(resume): %v", err)
+	}
+	if got := bulkStatuses(results); got != "skipped,sent,skipped" {
+		t.Fatalf("unexpected resumed run: %s", got)
+	}
+	if len(f.sentMessages) != 3 {
+		t.Fatalf("expected each row delivered once, got %d sends", len(f.sentMessages))
+	}
+
+	// Losing the progress log does not resend: the idempotency keys remember.
+	if err := os.Remove(progress); err != nil {
+		t.Fatalf("remove progress: %v", err)
+	}
+	results, err = a.SendBulk(ctx, msgs, BulkSendOptions{ProgressPath: progress})
+	if err != nil || bulkStatuses(results) != "sent,sent,sent" || len(f.sentMessages) != 3 {
+		t.Fatalf("expected rows to be recognised as sent, got %s (%d sends, err=%v)", bulkStatuses(results), len(f.sentMessages), err)
+	}
+}
+
+func TestSendBulkResumeSurvivesEditsAndPathSpelling(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	dir := t.TempDir()
+	csvPath := writeBulkCSV(t, dir, "phone,name\n15550100,Ann\n15550101,Bob\n")
+	msgs, err := LoadBulkMessages(csvPath, "Hi {{.name}}", BulkLoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadBulkMessages: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := a.SendBulk(ctx, msgs, BulkSendOptions{ProgressPath: filepath.Join(dir, "progress.jsonl")}); err != nil {
+		t.Fatalf("SendBulk: %v", err)
+	}
+
+	// A row is added at the top, and the same log is given as a relative path.
+	csvPath = writeBulkCSV(t, dir, "phone,name\n15550099,Zoe\n15550100,Ann\n15550101,Bob\n")
+	msgs, err = LoadBulkMessages(csvPath, "Hi {{.name}}", BulkLoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadBulkMessages(edited): %v", err)
+	}
+	t.Chdir(dir)
+	results, err := a.SendBulk(ctx, msgs, BulkSendOptions{ProgressPath: "progress.jsonl"})
+	if err != nil {
+		t.Fatalf("SendBulk(resume): %v", err)
+	}
+	if got := bulkStatuses(results); got != "sent,skipped,skipped" {
+		t.Fatalf("expected only the new row to be sent, got %s", got)
+	}
+
+	// Without the log, the idempotency keys still match across spellings.
+	if err := os.Remove(filepath.Join(dir, "progress.jsonl")); err != nil {
+		t.Fatalf("remove progress: %v", err)
+	}
+	if _, err := a.SendBulk(ctx, msgs, BulkSendOptions{ProgressPath: filepath.Join(dir, "progress.jsonl")}); err != nil {
+		t.Fatalf("SendBulk(no log): %v", err)
+	}
+	if len(f.sentMessages) != 3 {
+		t.Fatalf("expected each recipient to get one message, got %d sends", len(f.sentMessages))
+	}
+}
+
+func TestBulkRowKeysKeepDuplicatesApart(t *testing.T) {
+	msgs := []BulkMessage{
+		{Row: 1, To: "1@s.whatsapp.net", Text: "hi"},
+		{Row: 2, To: "1@s.whatsapp.net", Text: "hi"},
+		{Row: 3, To: "2@s.whatsapp.net", Text: "hi"},
+	}
+	keys := bulkRowKeys(msgs)
+	if keys[0] == keys[1] || keys[0] == keys[2] {
+		t.Fatalf("expected distinct keys, got %v", keys)
+	}
+	// Keys don't depend on the row number.
+	msgs[0].Row = 7
+	if bulkRowKeys(msgs)[0] != keys[0] {
+		t.Fatalf("expected the key to ignore the row number")
+	}
+}
+
+func bulkStatuses(results []BulkResult) string {
+	var s []string
+	for _, r := range results {
+		s = append(s, r.Status)
+	}
+	return strings.Join(s, ",")
+}
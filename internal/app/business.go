@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// FetchBusinessProfile returns the business profile for jid, reusing the local
+// copy when it is newer than maxAge (zero always asks WhatsApp). It only
+// connects when the profile has to be fetched. The second return value
+// reports whether the cached copy was used.
+func (a *App) FetchBusinessProfile(ctx context.Context, jid types.JID, maxAge time.Duration) (store.BusinessProfile, bool, error) {
+	if maxAge > 0 {
+		if p, err := a.db.GetBusinessProfile(jid.String()); err == nil && time.Since(p.FetchedAt) <= maxAge {
+			return p, true, nil
+		}
+	}
+
+	if err := a.EnsureAuthed(); err != nil {
+		return store.BusinessProfile{}, false, err
+	}
+	if err := a.Connect(ctx, false, nil); err != nil {
+		return store.BusinessProfile{}, false, err
+	}
+	bp, err := a.wa.GetBusinessProfile(ctx, jid)
+	if err != nil {
+		return store.BusinessProfile{}, false, err
+	}
+	p := businessProfileFromWA(jid, bp)
+	if infos, err := a.wa.GetUserInfo(ctx, []types.JID{jid}); err == nil {
+		if vn := infos[jid].VerifiedName; vn != nil && vn.Details != nil {
+			p.Name = vn.Details.GetVerifiedName()
+		}
+	}
+	if err := a.db.SaveBusinessProfile(p); err != nil {
+		return p, false, err
+	}
+	if cached, err := a.db.GetBusinessProfile(jid.String()); err == nil {
+		p = cached
+	}
+	return p, false, nil
+}
+
+// businessProfileFromWA converts the fetched profile. Several websites are
+// kept as one comma-separated value.
+func businessProfileFromWA(jid types.JID, bp *wa.BusinessProfile) store.BusinessProfile {
+	p := store.BusinessProfile{JID: jid.String(), FetchedAt: time.Now().UTC()}
+	if bp == nil {
+		return p
+	}
+	p.Address = bp.Address
+	p.Email = bp.Email
+	p.Description = bp.Description
+	p.Website = strings.Join(bp.Websites, ", ")
+	for _, c := range bp.Categories {
+		if c.Name != "" {
+			p.Categories = append(p.Categories, c.Name)
+		}
+	}
+	p.HoursTimeZone = bp.BusinessHoursTimeZone
+	for _, h := range bp.BusinessHours {
+		p.Hours = append(p.Hours, store.BusinessHours{Day: h.DayOfWeek, Mode: h.Mode, Open: h.OpenTime, Close: h.CloseTime})
+	}
+	sort.SliceStable(p.Hours, func(i, j int) bool { return weekdayIndex(p.Hours[i].Day) < weekdayIndex(p.Hours[j].Day) })
+	return p
+}
+
+func weekdayIndex(day string) int {
+	for i, d := range []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"} {
+		if d == day {
+			return i
+		}
+	}
+	return 7
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestFetchBusinessProfileCachesResult(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	jid := types.JID{User: "111", Server: types.DefaultUserServer}
+	f.business[jid] = &wa.BusinessProfile{
+		BusinessProfile: types.BusinessProfile{
+			JID:        jid,
+			Address:    "1 Main St",
+			Categories: []types.Category{{ID: "1", Name: "Retail"}},
+			BusinessHours: []types.BusinessHoursConfig{
+				{DayOfWeek: "tue", Mode: "open_24h"},
+				{DayOfWeek: "mon", Mode: "specific_hours", OpenTime: "540", CloseTime: "1020"},
+			},
+		},
+		Description: "Hardware and garden supplies",
+		Websites:    []string{"https://acme.example"},
+	}
+
+	ctx := context.Background()
+	p, cached, err := a.FetchBusinessProfile(ctx, jid, time.Hour)
+	if err != nil {
+		t.Fatalf("FetchBusinessProfile: %v", err)
+	}
+	if cached || p.Address != "1 Main St" || p.Website != "https://acme.example" || p.Description != "Hardware and garden supplies" || len(p.Hours) != 2 || p.Hours[0].Day != "mon" {
+		t.Fatalf("unexpected profile: %+v (cached=%v)", p, cached)
+	}
+
+	delete(f.business, jid)
+	f.connected = false
+	p, cached, err = a.FetchBusinessProfile(ctx, jid, time.Hour)
+	if err != nil || !cached || p.Categories[0] != "Retail" {
+		t.Fatalf("expected cached profile, got %+v (cached=%v err=%v)", p, cached, err)
+	}
+	if f.IsConnected() {
+		t.Fatalf("a cached profile must not connect")
+	}
+	if _, _, err := a.FetchBusinessProfile(ctx, jid, 0); err == nil {
+		t.Fatalf("expected max-age 0 to bypass the cache")
+	}
+
+	c, err := a.DB().GetContact(jid.String())
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	found, err := a.DB().SearchContacts(store.SearchContactsParams{BusinessOnly: true})
+	if err != nil || len(found) != 1 || found[0].JID != c.JID {
+		t.Fatalf("expected business contact to be searchable, got %+v (err=%v)", found, err)
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// callRejecter auto-rejects incoming calls during sync --follow and optionally
+// answers the caller with a text.
+type callRejecter struct {
+	enabled bool
+	message string
+}
+
+// handleCallEvent records a call event in the calls table. Offers are
+// rejected right away when rej is enabled.
+func (a *App) handleCallEvent(ctx context.Context, evt interface{}, rej callRejecter) {
+	switch v := evt.(type) {
+	case *events.CallOffer:
+		video := false
+		if v.Data != nil {
+			_, video = v.Data.GetOptionalChildByTag("video")
+		}
+		c := a.newCall(ctx, v.BasicCallMeta, video)
+		// The call is rejected even if it couldn't be logged.
+		_ = a.db.RecordCall(c)
+		if rej.enabled && c.Direction == "incoming" && v.GroupJID.IsEmpty() {
+			a.autoRejectCall(ctx, v.BasicCallMeta, c.PeerJID, rej.message)
+		}
+	case *events.CallOfferNotice:
+		_ = a.db.RecordCall(a.newCall(ctx, v.BasicCallMeta, v.Media == "video"))
+	case *events.CallAccept:
+		_ = a.db.AnswerCall(v.CallID, v.Timestamp)
+	case *events.CallReject:
+		_ = a.db.RejectCall(v.CallID, store.CallRejected, v.Timestamp)
+	case *events.CallTerminate:
+		_ = a.db.EndCall(v.CallID, v.Reason, v.Timestamp)
+	}
+}
+
+func (a *App) newCall(ctx context.Context, meta types.BasicCallMeta, video bool) store.Call {
+	creator := a.resolveLID(ctx, meta.CallCreator, meta.CallCreatorAlt).ToNonAD()
+	c := store.Call{
+		CallID:    meta.CallID,
+		PeerJID:   creator.String(),
+		Direction: "incoming",
+		Video:     video,
+		StartedAt: meta.Timestamp,
+	}
+	if own := a.wa.OwnJID(); !own.IsEmpty() && creator.User == own.User {
+		// A call placed from the phone: the peer is the other side.
+		c.Direction = "outgoing"
+		c.PeerJID = a.resolveLID(ctx, meta.From, types.JID{}).ToNonAD().String()
+	}
+	if !meta.GroupJID.IsEmpty() {
+		c.GroupJID = meta.GroupJID.ToNonAD().String()
+	}
+	return c
+}
+
+// autoRejectCall declines a call and sends the canned message, off the event
+// handler so a slow send doesn't hold up other events.
+func (a *App) autoRejectCall(ctx context.Context, meta types.BasicCallMeta, peer, message string) {
+	a.goBackground(func() {
+		if err := a.wa.RejectCall(ctx, meta.From, meta.CallID); err != nil {
+			fmt.Fprintf(os.Stderr, "\nReject call from %s: %v\n", peer, err)
+			return
+		}
+		_ = a.db.RejectCall(meta.CallID, store.CallAutoRejected, time.Now())
+		fmt.Fprintf(os.Stderr, "\nRejected call from %s.\n", peer)
+		if strings.TrimSpace(message) == "" {
+			return
+		}
+		to, err := types.ParseJID(peer)
+		if err != nil {
+			return
+		}
+		if _, err := a.SendText(ctx, to, message, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Reply to rejected call from %s: %v\n", peer, err)
+		}
+	})
+}
+
+// recordHistoryCall stores the missed-call notices found in history sync, so
+// calls from before wacli was linked show up too. Other messages are ignored.
+func (a *App) recordHistoryCall(ctx context.Context, chatID string, m *waWeb.WebMessageInfo) {
+	video := false
+	switch m.GetMessageStubType() {
+	case waWeb.WebMessageInfo_CALL_MISSED_VOICE, waWeb.WebMessageInfo_CALL_MISSED_GROUP_VOICE:
+	case waWeb.WebMessageInfo_CALL_MISSED_VIDEO, waWeb.WebMessageInfo_CALL_MISSED_GROUP_VIDEO:
+		video = true
+	default:
+		return
+	}
+	chat, err := types.ParseJID(chatID)
+	if err != nil || m.GetKey().GetID() == "" {
+		return
+	}
+	at := time.Unix(int64(m.GetMessageTimestamp()), 0)
+	c := store.Call{
+		CallID:    m.GetKey().GetID(),
+		PeerJID:   a.resolveLID(ctx, chat, types.JID{}).ToNonAD().String(),
+		Direction: "incoming",
+		Video:     video,
+		Outcome:   store.CallMissed,
+		StartedAt: at,
+		EndedAt:   at,
+	}
+	if chat.Server == types.GroupServer {
+		c.GroupJID = chat.String()
+		if p, err := types.ParseJID(m.GetParticipant()); err == nil && !p.IsEmpty() {
+			c.PeerJID = a.resolveLID(ctx, p, types.JID{}).ToNonAD().String()
+		}
+	}
+	_ = a.db.RecordCall(c)
+}
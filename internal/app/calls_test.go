@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSyncRecordsAndRejectsCalls(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	f.ownJID = types.JID{User: "15550009999", Server: types.DefaultUserServer}
+
+	alice := types.JID{User: "15550001111", Server: types.DefaultUserServer}
+	bob := types.JID{User: "15550002222", Server: types.DefaultUserServer}
+	group := types.JID{User: "120363000000000001", Server: types.GroupServer}
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	meta := func(id string, from types.JID, at time.Time) types.BasicCallMeta {
+		return types.BasicCallMeta{From: from, CallCreator: from, CallID: id, Timestamp: at}
+	}
+
+	videoOffer := waBinary.Node{Tag: "offer", Content: []waBinary.Node{{Tag: "video"}}}
+	groupMeta := meta("c-group", bob, base.Add(time.Hour))
+	groupMeta.GroupJID = group
+
+	missed := &waWeb.WebMessageInfo{
+		Key:              &waCommon.MessageKey{RemoteJID: proto.String(bob.String()), ID: proto.String("c-old")},
+		MessageTimestamp: proto.Uint64(uint64(base.Add(-24 * time.Hour).Unix())),
+		MessageStubType:  waWeb.WebMessageInfo_CALL_MISSED_VOICE.Enum(),
+	}
+	f.connectEvents = []interface{}{
+		&events.HistorySync{Data: &waHistorySync.HistorySync{
+			SyncType: waHistorySync.HistorySync_FULL.Enum(),
+			Conversations: []*waHistorySync.Conversation{{
+				ID:       proto.String(bob.String()),
+				Messages: []*waHistorySync.HistorySyncMsg{{Message: missed}},
+			}},
+		}},
+		&events.CallOffer{BasicCallMeta: meta("c-alice", alice, base), Data: &videoOffer},
+		&events.CallOfferNotice{BasicCallMeta: groupMeta, Media: "audio", Type: "group"},
+		&events.CallTerminate{BasicCallMeta: groupMeta, Reason: "timeout"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow, RejectCalls: true, RejectMessage: "Calls aren't answered here, please text."}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	calls, err := a.db.ListCalls(store.ListCallsParams{})
+	if err != nil || len(calls) != 3 {
+		t.Fatalf("expected 3 calls, got %+v err=%v", calls, err)
+	}
+	byID := map[string]store.Call{}
+	for _, c := range calls {
+		byID[c.CallID] = c
+	}
+	if c := byID["c-alice"]; c.PeerJID != alice.String() || !c.Video || c.Outcome != store.CallAutoRejected || c.Direction != "incoming" {
+		t.Fatalf("unexpected rejected call %+v", c)
+	}
+	if c := byID["c-group"]; c.GroupJID != group.String() || c.Outcome != store.CallMissed || c.EndReason != "timeout" {
+		t.Fatalf("unexpected group call %+v", c)
+	}
+	if c := byID["c-old"]; c.PeerJID != bob.String() || c.Outcome != store.CallMissed || !c.StartedAt.Equal(base.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected history call %+v", c)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.rejected) != 1 || f.rejected[0] != "c-alice" {
+		t.Fatalf("expected only the 1:1 call to be rejected, got %v", f.rejected)
+	}
+	if len(f.sentMessages) != 1 || f.sentMessages[0].to != alice || f.sentMessages[0].msg.GetConversation() != "Calls aren't answered here, please text." {
+		t.Fatalf("expected the canned reply to alice, got %+v", f.sentMessages)
+	}
+}
+
+func TestOutgoingCallFromPhone(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.ownJID = types.JID{User: "15550009999", Server: types.DefaultUserServer}
+
+	peer := types.JID{User: "15550001111", Server: types.DefaultUserServer}
+	a.handleCallEvent(context.Background(), &events.CallOffer{BasicCallMeta: types.BasicCallMeta{
+		From:        peer,
+		CallCreator: types.JID{User: f.ownJID.User, Server: types.DefaultUserServer, Device: 3},
+		CallID:      "c-out",
+		Timestamp:   time.Now(),
+	}}, callRejecter{enabled: true})
+
+	calls, err := a.db.ListCalls(store.ListCallsParams{Direction: "outgoing"})
+	if err != nil || len(calls) != 1 || calls[0].PeerJID != peer.String() || calls[0].Outcome != store.CallRinging {
+		t.Fatalf("expected an outgoing call to the peer, got %+v err=%v", calls, err)
+	}
+	if len(f.rejected) != 0 {
+		t.Fatalf("outgoing calls must not be rejected, got %v", f.rejected)
+	}
+}
+
+func TestAutoRejectedCallNotLoggedAsMissed(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.ownJID = types.JID{User: "15550009999", Server: types.DefaultUserServer}
+
+	ctx := context.Background()
+	caller := types.JID{User: "15550001111", Server: types.DefaultUserServer}
+	meta := types.BasicCallMeta{From: caller, CallCreator: caller, CallID: "c-race", Timestamp: time.Now()}
+	// The server's terminate arrives while the reject is still in flight.
+	f.onReject = func(string) {
+		a.handleCallEvent(ctx, &events.CallTerminate{BasicCallMeta: meta, Reason: "reject"}, callRejecter{})
+	}
+	a.handleCallEvent(ctx, &events.CallOffer{BasicCallMeta: meta}, callRejecter{enabled: true})
+	a.bg.Wait()
+
+	calls, err := a.db.ListCalls(store.ListCallsParams{})
+	if err != nil || len(calls) != 1 || calls[0].Outcome != store.CallAutoRejected {
+		t.Fatalf("expected the call to be logged as auto-rejected, got %+v err=%v", calls, err)
+	}
+}
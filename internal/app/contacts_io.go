@@ -0,0 +1,239 @@
+package app
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/vcard"
+	"github.com/steipete/wacli/internal/wa"
+)
+
+// ContactImportEntry is one contact read from a vCard or CSV file. Phones are
+// tried in order until one matches a known contact.
+type ContactImportEntry struct {
+	Phones []string
+	Alias  string
+	Notes  string
+	Tags   []string
+}
+
+type ContactImportResult struct {
+	Input  string   `json:"input"`
+	JID    string   `json:"jid,omitempty"`
+	Alias  string   `json:"alias,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Status string   `json:"status"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// ImportContacts matches entries to known contacts by phone number and sets
+// their alias, notes and tags. Existing tags are kept. With dryRun nothing is
+// written.
+func (a *App) ImportContacts(entries []ContactImportEntry, dryRun bool) ([]ContactImportResult, error) {
+	results := make([]ContactImportResult, 0, len(entries))
+	for _, e := range entries {
+		r := ContactImportResult{Input: strings.Join(e.Phones, ", "), Alias: e.Alias, Tags: e.Tags}
+		if len(e.Phones) == 0 {
+			r.Status = "invalid"
+			r.Error = "no phone number"
+			results = append(results, r)
+			continue
+		}
+
+		var match store.Contact
+		var found, valid bool
+		for _, p := range e.Phones {
+			phone, err := wa.NormalizePhone(p)
+			if err != nil {
+				r.Error = err.Error()
+				continue
+			}
+			valid = true
+			c, err := a.db.FindContactByPhone(phone)
+			if err != nil {
+				if store.IsNotFound(err) {
+					continue
+				}
+				return results, err
+			}
+			match, found = c, true
+			break
+		}
+		if !found {
+			r.Status = "not_found"
+			if valid {
+				r.Error = ""
+			} else {
+				r.Status = "invalid"
+			}
+			results = append(results, r)
+			continue
+		}
+
+		r.JID = match.JID
+		r.Error = ""
+		r.Status = "updated"
+		if dryRun {
+			r.Status = "matched"
+			results = append(results, r)
+			continue
+		}
+		if err := applyContactImport(a.db, match.JID, e); err != nil {
+			r.Status = "failed"
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func applyContactImport(db *store.DB, jid string, e ContactImportEntry) error {
+	if strings.TrimSpace(e.Alias) != "" {
+		if err := db.SetAlias(jid, e.Alias); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(e.Notes) != "" {
+		if err := db.SetNotes(jid, e.Notes); err != nil {
+			return err
+		}
+	}
+	for _, t := range e.Tags {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		if err := db.AddTag(jid, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ContactEntriesFromVCards converts parsed vCards to import entries. The
+// WhatsApp "waid" of a TEL line wins over the printed number.
+func ContactEntriesFromVCards(cards []vcard.Card) []ContactImportEntry {
+	out := make([]ContactImportEntry, 0, len(cards))
+	for _, c := range cards {
+		e := ContactImportEntry{Notes: c.Note, Tags: c.Categories}
+		// FN is the contact's name, not something the user chose; only
+		// NICKNAME (where export puts the alias) becomes the alias.
+		e.Alias = c.Nickname
+		for _, p := range c.Phones {
+			if p.WAID != "" {
+				e.Phones = append(e.Phones, p.WAID)
+			} else if p.Number != "" {
+				e.Phones = append(e.Phones, p.Number)
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// ParseContactsCSV reads a CSV file with a header row. Recognised columns are
+// phone (or number/jid), alias (or nickname), notes and tags; tags are
+// separated by ";" or "|". A name column is the contact's own name, as in an
+// export, and is ignored like a vCard's FN.
+func ParseContactsCSV(r io.Reader) ([]ContactImportEntry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	col := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if _, ok := col[h]; !ok {
+			col[h] = i
+		}
+	}
+	pick := func(names ...string) int {
+		for _, n := range names {
+			if i, ok := col[n]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	phoneCol := pick("phone", "number", "phone_number", "jid")
+	if phoneCol < 0 {
+		return nil, fmt.Errorf("csv header needs a phone column")
+	}
+	aliasCol := pick("alias", "nickname")
+	notesCol := pick("notes", "note")
+	tagsCol := pick("tags", "tag", "categories")
+
+	field := func(rec []string, i int) string {
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var out []ContactImportEntry
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		phone := field(rec, phoneCol)
+		if phone == "" {
+			continue
+		}
+		// Accept JIDs in the phone column, e.g. from a previous export.
+		if user, _, ok := strings.Cut(phone, "@"); ok {
+			phone = user
+		}
+		e := ContactImportEntry{
+			Phones: []string{phone},
+			Alias:  field(rec, aliasCol),
+			Notes:  field(rec, notesCol),
+		}
+		for _, t := range strings.FieldsFunc(field(rec, tagsCol), func(r rune) bool { return r == ';' || r == '|' }) {
+			if t = strings.TrimSpace(t); t != "" {
+				e.Tags = append(e.Tags, t)
+			}
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// ContactCard renders a stored contact as a vCard, carrying the alias as
+// NICKNAME, notes as NOTE and tags as CATEGORIES.
+func ContactCard(c store.Contact) vcard.Card {
+	card := vcard.Card{
+		FullName:   c.Name,
+		Nickname:   c.Alias,
+		Note:       c.Notes,
+		Categories: c.Tags,
+		Org:        c.BusinessName,
+	}
+	phone := c.Phone
+	if phone == "" && strings.HasSuffix(c.JID, "@s.whatsapp.net") {
+		phone = strings.TrimSuffix(c.JID, "@s.whatsapp.net")
+	}
+	if phone != "" {
+		card.Phones = []vcard.Phone{{Number: "+" + phone, WAID: phone}}
+	}
+	if card.FullName == "" {
+		card.FullName = c.JID
+		if phone != "" {
+			card.FullName = "+" + phone
+		}
+	}
+	return card
+}
@@ -0,0 +1,147 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steipete/wacli/internal/vcard"
+)
+
+func TestImportContactsMatchesByPhone(t *testing.T) {
+	a := newTestApp(t)
+	db := a.DB()
+	if err := db.UpsertContact("15550102030@s.whatsapp.net", "15550102030", "Jane", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.AddTag("15550102030@s.whatsapp.net", "existing"); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+
+	cards, err := vcard.Parse(strings.NewReader(strings.Join([]string{
+		"BEGIN:VCARD",
+		"FN:Jane Doe",
+		"NICKNAME:Janie",
+		"TEL;type=CELL:+1 (555) 010-2030",
+		"NOTE:prefers mornings",
+		"CATEGORIES:vip",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"FN:Stranger",
+		"TEL:+49 151 0000000",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"FN:No Phone",
+		"END:VCARD",
+	}, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	res, err := a.ImportContacts(ContactEntriesFromVCards(cards), false)
+	if err != nil {
+		t.Fatalf("ImportContacts: %v", err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(res))
+	}
+	if res[0].Status != "updated" || res[0].JID != "15550102030@s.whatsapp.net" {
+		t.Fatalf("unexpected first result: %+v", res[0])
+	}
+	if res[1].Status != "not_found" || res[2].Status != "invalid" {
+		t.Fatalf("unexpected statuses: %+v", res)
+	}
+
+	c, err := db.GetContact("15550102030@s.whatsapp.net")
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	if c.Alias != "Janie" || c.Notes != "prefers mornings" {
+		t.Fatalf("expected alias and notes, got %+v", c)
+	}
+	if strings.Join(c.Tags, ",") != "existing,vip" {
+		t.Fatalf("expected tags to be merged, got %v", c.Tags)
+	}
+}
+
+func TestImportContactsDryRunWritesNothing(t *testing.T) {
+	a := newTestApp(t)
+	if err := a.DB().UpsertContact("111222333@s.whatsapp.net", "111222333", "", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	entries, err := ParseContactsCSV(strings.NewReader("phone,alias,notes,tags\n+111222333,Ali,note,a;b\n"))
+	if err != nil {
+		t.Fatalf("ParseContactsCSV: %v", err)
+	}
+	if len(entries) != 1 || len(entries[0].Tags) != 2 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	res, err := a.ImportContacts(entries, true)
+	if err != nil {
+		t.Fatalf("ImportContacts: %v", err)
+	}
+	if res[0].Status != "matched" {
+		t.Fatalf("expected matched, got %+v", res[0])
+	}
+	c, _ := a.DB().GetContact("111222333@s.whatsapp.net")
+	if c.Alias != "" || len(c.Tags) != 0 {
+		t.Fatalf("dry run must not write, got %+v", c)
+	}
+}
+
+func TestParseContactsCSVIgnoresNameColumn(t *testing.T) {
+	entries, err := ParseContactsCSV(strings.NewReader("jid,phone,name,alias\n111@s.whatsapp.net,111,Alice Smith,\n222@s.whatsapp.net,222,Bob Jones,Bobby\n"))
+	if err != nil {
+		t.Fatalf("ParseContactsCSV: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Alias != "" || entries[1].Alias != "Bobby" {
+		t.Fatalf("expected the alias only from the alias column, got %+v", entries)
+	}
+	entries, err = ParseContactsCSV(strings.NewReader("phone,name,nickname\n333,Carol,Caz\n"))
+	if err != nil || len(entries) != 1 || entries[0].Alias != "Caz" {
+		t.Fatalf("expected the alias from the nickname column, got %+v err=%v", entries, err)
+	}
+}
+
+func TestParseContactsCSVRequiresPhoneColumn(t *testing.T) {
+	if _, err := ParseContactsCSV(strings.NewReader("name,notes\nA,B\n")); err == nil {
+		t.Fatalf("expected error for missing phone column")
+	}
+}
+
+func TestContactCardKeepsAliasAsNickname(t *testing.T) {
+	a := newTestApp(t)
+	db := a.DB()
+	jid := "15550102030@s.whatsapp.net"
+	other := "15550102031@s.whatsapp.net"
+	_ = db.UpsertContact(jid, "", "Push", "", "", "")
+	_ = db.SetAlias(jid, "Boss")
+	_ = db.AddTag(jid, "work")
+	_ = db.UpsertContact(other, "", "", "Carol Smith", "", "")
+	c, err := db.GetContact(jid)
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	card := ContactCard(c)
+	if card.FullName != "Push" || card.Nickname != "Boss" || len(card.Phones) != 1 || card.Phones[0].WAID != "15550102030" || card.Categories[0] != "work" {
+		t.Fatalf("unexpected card: %+v", card)
+	}
+
+	// Export then import must not turn names into aliases.
+	oc, err := db.GetContact(other)
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	cards, err := vcard.Parse(strings.NewReader(card.String() + ContactCard(oc).String()))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := a.ImportContacts(ContactEntriesFromVCards(cards), false); err != nil {
+		t.Fatalf("ImportContacts: %v", err)
+	}
+	if c, _ := db.GetContact(jid); c.Alias != "Boss" {
+		t.Fatalf("expected alias to survive the round trip, got %q", c.Alias)
+	}
+	if oc, _ := db.GetContact(other); oc.Alias != "" {
+		t.Fatalf("expected no alias from the exported name, got %q", oc.Alias)
+	}
+}
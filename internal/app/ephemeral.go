@@ -0,0 +1,82 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// FormatTimer describes a disappearing-message timer the way WhatsApp
+// labels it ("24 hours", "7 days").
+func FormatTimer(seconds uint32) string {
+	d := time.Duration(seconds) * time.Second
+	switch {
+	case d > 24*time.Hour && d%(24*time.Hour) == 0:
+		return plural(int(d/(24*time.Hour)), "day")
+	case d >= time.Hour && d%time.Hour == 0:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return d.String()
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// chatTimer returns the disappearing-message timer of a chat, 0 when off.
+func (a *App) chatTimer(to types.JID) uint32 {
+	if to == types.StatusBroadcastJID {
+		return 0
+	}
+	seconds, err := a.db.ChatEphemeral(a.db.ResolveJID(to.String()))
+	if err != nil {
+		return 0
+	}
+	return seconds
+}
+
+// withDisappearingTimer marks msg to expire after the chat's timer, like
+// messages sent from the phone in a chat with disappearing messages on.
+// Plain text becomes an extended text message, which can carry the timer.
+func withDisappearingTimer(msg *waProto.Message, seconds uint32) *waProto.Message {
+	if seconds == 0 || msg == nil {
+		return msg
+	}
+	if msg.Conversation != nil {
+		msg = &waProto.Message{ExtendedTextMessage: &waProto.ExtendedTextMessage{Text: msg.Conversation}}
+	}
+	var ci **waProto.ContextInfo
+	switch {
+	case msg.ExtendedTextMessage != nil:
+		ci = &msg.ExtendedTextMessage.ContextInfo
+	case msg.ImageMessage != nil:
+		ci = &msg.ImageMessage.ContextInfo
+	case msg.VideoMessage != nil:
+		ci = &msg.VideoMessage.ContextInfo
+	case msg.AudioMessage != nil:
+		ci = &msg.AudioMessage.ContextInfo
+	case msg.DocumentMessage != nil:
+		ci = &msg.DocumentMessage.ContextInfo
+	case msg.StickerMessage != nil:
+		ci = &msg.StickerMessage.ContextInfo
+	case msg.LocationMessage != nil:
+		ci = &msg.LocationMessage.ContextInfo
+	case msg.ContactMessage != nil:
+		ci = &msg.ContactMessage.ContextInfo
+	default:
+		// Reactions, edits and other protocol messages don't expire.
+		return msg
+	}
+	if *ci == nil {
+		*ci = &waProto.ContextInfo{}
+	}
+	(*ci).Expiration = proto.Uint32(seconds)
+	return msg
+}
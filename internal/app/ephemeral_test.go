@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSyncAppliesEditsAndDisappearingTimers(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+
+	chat := types.JID{User: "123", Server: types.DefaultUserServer}
+	base := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	info := func(id string, ts time.Time) types.MessageInfo {
+		return types.MessageInfo{
+			MessageSource: types.MessageSource{Chat: chat, Sender: chat},
+			ID:            types.MessageID(id),
+			Timestamp:     ts,
+		}
+	}
+
+	histMsg := &waWeb.WebMessageInfo{
+		Key: &waCommon.MessageKey{
+			RemoteJID: proto.String(chat.String()),
+			FromMe:    proto.Bool(false),
+			ID:        proto.String("m-orig"),
+		},
+		MessageTimestamp: proto.Uint64(uint64(base.Unix())),
+		Message: &waProto.Message{EphemeralMessage: &waProto.FutureProofMessage{Message: &waProto.Message{
+			ExtendedTextMessage: &waProto.ExtendedTextMessage{
+				Text:        proto.String("see you at 5"),
+				ContextInfo: &waProto.ContextInfo{Expiration: proto.Uint32(7 * 24 * 3600)},
+			},
+		}}},
+	}
+	history := &events.HistorySync{
+		Data: &waHistorySync.HistorySync{
+			SyncType: waHistorySync.HistorySync_FULL.Enum(),
+			Conversations: []*waHistorySync.Conversation{{
+				ID:                  proto.String(chat.String()),
+				EphemeralExpiration: proto.Uint32(7 * 24 * 3600),
+				Messages:            []*waHistorySync.HistorySyncMsg{{Message: histMsg}},
+			}},
+		},
+	}
+	edit := &events.Message{
+		Info: info("m-edit", base.Add(time.Minute)),
+		Message: &waProto.Message{ProtocolMessage: &waProto.ProtocolMessage{
+			Type:          waProto.ProtocolMessage_MESSAGE_EDIT.Enum(),
+			Key:           &waProto.MessageKey{RemoteJID: proto.String(chat.String()), ID: proto.String("m-orig")},
+			EditedMessage: &waProto.Message{Conversation: proto.String("see you at 6")},
+		}},
+	}
+	viewOnce := &events.Message{
+		Info: info("m-once", base.Add(2*time.Minute)),
+		Message: &waProto.Message{ImageMessage: &waProto.ImageMessage{
+			Mimetype:      proto.String("image/jpeg"),
+			DirectPath:    proto.String("/direct"),
+			MediaKey:      []byte{1},
+			FileSHA256:    []byte{2},
+			FileEncSHA256: []byte{3},
+			FileLength:    proto.Uint64(10),
+			ViewOnce:      proto.Bool(true),
+		}},
+		IsViewOnce: true,
+	}
+	timerOff := &events.Message{
+		Info: info("m-timer", base.Add(3*time.Minute)),
+		Message: &waProto.Message{ProtocolMessage: &waProto.ProtocolMessage{
+			Type:                waProto.ProtocolMessage_EPHEMERAL_SETTING.Enum(),
+			EphemeralExpiration: proto.Uint32(0),
+		}},
+	}
+
+	f.connectEvents = []interface{}{history, edit, viewOnce}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow, SkipViewOnce: true}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	orig, err := a.db.GetMessage(chat.String(), "m-orig")
+	if err != nil {
+		t.Fatalf("GetMessage: %v", err)
+	}
+	if orig.Text != "see you at 6" || orig.EditedAt.IsZero() || orig.EphemeralExpiration != 7*24*3600 {
+		t.Fatalf("expected edited ephemeral message, got %+v", orig)
+	}
+	if _, err := a.db.GetMessage(chat.String(), "m-edit"); err == nil {
+		t.Fatalf("the edit must not be stored as a message of its own")
+	}
+	once, err := a.db.GetMessage(chat.String(), "m-once")
+	if err != nil || !once.ViewOnce {
+		t.Fatalf("expected view-once message, got %+v err=%v", once, err)
+	}
+	if seconds, _ := a.db.ChatEphemeral(chat.String()); seconds != 7*24*3600 {
+		t.Fatalf("expected 7 day chat timer, got %d", seconds)
+	}
+
+	f.connectEvents = []interface{}{timerOff}
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if seconds, _ := a.db.ChatEphemeral(chat.String()); seconds != 0 {
+		t.Fatalf("expected timer to be turned off, got %d", seconds)
+	}
+}
+
+func TestSendRespectsDisappearingTimer(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+
+	to := types.JID{User: "15550001234", Server: types.DefaultUserServer}
+	if err := a.db.UpsertChat(to.String(), "dm", "Alice", time.Now()); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	if err := a.db.SetChatEphemeral(to.String(), 24*3600); err != nil {
+		t.Fatalf("SetChatEphemeral: %v", err)
+	}
+
+	id, err := a.SendText(context.Background(), to, "hi", "")
+	if err != nil {
+		t.Fatalf("SendText: %v", err)
+	}
+	ext := f.sentMessages[0].msg.GetExtendedTextMessage()
+	if ext.GetText() != "hi" || ext.GetContextInfo().GetExpiration() != 24*3600 {
+		t.Fatalf("expected text with a 24h expiration, got %+v", f.sentMessages[0].msg)
+	}
+	m, err := a.db.GetMessage(to.String(), string(id))
+	if err != nil || m.EphemeralExpiration != 24*3600 {
+		t.Fatalf("expected stored expiration, got %+v err=%v", m, err)
+	}
+	if FormatTimer(24*3600) != "24 hours" || FormatTimer(7*24*3600) != "7 days" {
+		t.Fatalf("unexpected timer labels %q %q", FormatTimer(24*3600), FormatTimer(7*24*3600))
+	}
+}
@@ -29,16 +29,54 @@ type fakeWA struct {
 	contacts map[types.JID]types.ContactInfo
 	groups   map[types.JID]*types.GroupInfo
 
+	registered map[string]types.IsOnWhatsAppResponse
+	userInfo   map[types.JID]types.UserInfo
+	pictures   map[types.JID]*types.ProfilePictureInfo
+	pictureErr map[types.JID]error
+	blocked    []types.JID
+	statusPriv []types.StatusPrivacy
+	ownJID     types.JID
+	rejected   []string // call IDs
+	onReject   func(callID string)
+	lidToPN    map[types.JID]types.JID
+	business   map[types.JID]*wa.BusinessProfile
+
+	participantErrors map[types.JID]int
+	participantCalls  [][]types.JID
+	sentTexts         []fakeSentText
+	sentMessages      []fakeSentMessage
+	sendErrs          []error
+	uploads           int
+	nextMsgID         int
+
 	onDemandHistory func(lastKnown types.MessageInfo, count int) *events.HistorySync
 }
 
+type fakeSentText struct {
+	to   types.JID
+	text string
+}
+
+type fakeSentMessage struct {
+	to  types.JID
+	id  types.MessageID
+	msg *waProto.Message
+}
+
 func newFakeWA() *fakeWA {
 	return &fakeWA{
-		authed:        true,
-		handlers:      map[uint32]func(interface{}){},
-		contacts:      map[types.JID]types.ContactInfo{},
-		groups:        map[types.JID]*types.GroupInfo{},
-		nextHandlerID: 1,
+		authed:            true,
+		handlers:          map[uint32]func(interface{}){},
+		contacts:          map[types.JID]types.ContactInfo{},
+		groups:            map[types.JID]*types.GroupInfo{},
+		registered:        map[string]types.IsOnWhatsAppResponse{},
+		userInfo:          map[types.JID]types.UserInfo{},
+		pictures:          map[types.JID]*types.ProfilePictureInfo{},
+		pictureErr:        map[types.JID]error{},
+		lidToPN:           map[types.JID]types.JID{},
+		business:          map[types.JID]*wa.BusinessProfile{},
+		participantErrors: map[types.JID]int{},
+		nextHandlerID:     1,
 	}
 }
 
@@ -71,7 +109,7 @@ func (f *fakeWA) Connect(ctx context.Context, opts wa.ConnectOptions) error {
 	f.mu.Unlock()
 
 	if !authed && !opts.AllowQR {
-		return fmt.Errorf("not authenticated; run `wacli auth`")
+		return wa.ErrNotAuthenticated
 	}
 	f.emit(&events.Connected{})
 	for _, e := range eventsToEmit {
@@ -135,6 +173,109 @@ func (f *fakeWA) GetAllContacts(ctx context.Context) (map[types.JID]types.Contac
 	return out, nil
 }
 
+func (f *fakeWA) IsOnWhatsApp(ctx context.Context, phones []string) ([]types.IsOnWhatsAppResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]types.IsOnWhatsAppResponse, 0, len(phones))
+	for _, p := range phones {
+		if r, ok := f.registered[p]; ok {
+			r.Query = p
+			out = append(out, r)
+			continue
+		}
+		out = append(out, types.IsOnWhatsAppResponse{Query: p})
+	}
+	return out, nil
+}
+
+func (f *fakeWA) GetUserInfo(ctx context.Context, jids []types.JID) (map[types.JID]types.UserInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := map[types.JID]types.UserInfo{}
+	for _, j := range jids {
+		if info, ok := f.userInfo[j]; ok {
+			out[j] = info
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWA) GetProfilePictureInfo(ctx context.Context, jid types.JID, existingID string) (*types.ProfilePictureInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.pictureErr[jid]; err != nil {
+		return nil, err
+	}
+	p := f.pictures[jid]
+	if p == nil {
+		return nil, whatsmeow.ErrProfilePictureNotSet
+	}
+	if existingID != "" && existingID == p.ID {
+		return nil, nil
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (f *fakeWA) GetBlocklist(ctx context.Context) (*types.Blocklist, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &types.Blocklist{JIDs: append([]types.JID{}, f.blocked...)}, nil
+}
+
+func (f *fakeWA) UpdateBlocklist(ctx context.Context, jid types.JID, block bool) (*types.Blocklist, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var next []types.JID
+	for _, j := range f.blocked {
+		if j != jid {
+			next = append(next, j)
+		}
+	}
+	if block {
+		next = append(next, jid)
+	}
+	f.blocked = next
+	return &types.Blocklist{JIDs: append([]types.JID{}, next...)}, nil
+}
+
+func (f *fakeWA) RejectCall(ctx context.Context, from types.JID, callID string) error {
+	if f.onReject != nil {
+		f.onReject(callID)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rejected = append(f.rejected, callID)
+	return nil
+}
+
+func (f *fakeWA) OwnJID() types.JID { return f.ownJID }
+
+func (f *fakeWA) GetStatusPrivacy(ctx context.Context) ([]types.StatusPrivacy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.statusPriv) == 0 {
+		return whatsmeow.DefaultStatusPrivacy, nil
+	}
+	return f.statusPriv, nil
+}
+
+func (f *fakeWA) GetPNForLID(ctx context.Context, lid types.JID) (types.JID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lidToPN[lid.ToNonAD()], nil
+}
+
+func (f *fakeWA) GetBusinessProfile(ctx context.Context, jid types.JID) (*wa.BusinessProfile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.business[jid]
+	if !ok {
+		return nil, fmt.Errorf("not a business account")
+	}
+	return p, nil
+}
+
 func (f *fakeWA) GetJoinedGroups(ctx context.Context) ([]*types.GroupInfo, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -166,20 +307,30 @@ func (f *fakeWA) SetGroupName(ctx context.Context, jid types.JID, name string) e
 func (f *fakeWA) UpdateGroupParticipants(ctx context.Context, group types.JID, users []types.JID, action wa.GroupParticipantAction) ([]types.GroupParticipant, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	f.participantCalls = append(f.participantCalls, append([]types.JID{}, users...))
 	g := f.groups[group]
 	if g == nil {
 		g = &types.GroupInfo{JID: group}
 		f.groups[group] = g
 	}
+	var changed []types.GroupParticipant
+	var ok []types.JID
+	for _, u := range users {
+		code := f.participantErrors[u]
+		changed = append(changed, types.GroupParticipant{JID: u, Error: code})
+		if code == 0 {
+			ok = append(ok, u)
+		}
+	}
 	switch action {
 	case wa.GroupParticipantAdd:
-		for _, u := range users {
+		for _, u := range ok {
 			g.Participants = append(g.Participants, types.GroupParticipant{JID: u})
 		}
 	case wa.GroupParticipantRemove:
 		var kept []types.GroupParticipant
 		rm := map[types.JID]bool{}
-		for _, u := range users {
+		for _, u := range ok {
 			rm[u] = true
 		}
 		for _, p := range g.Participants {
@@ -191,29 +342,108 @@ func (f *fakeWA) UpdateGroupParticipants(ctx context.Context, group types.JID, u
 	default:
 		// promote/demote ignored for tests
 	}
-	return g.Participants, nil
+	return changed, nil
 }
 
 func (f *fakeWA) GetGroupInviteLink(ctx context.Context, group types.JID, reset bool) (string, error) {
 	return "https://chat.whatsapp.com/invite/test", nil
 }
 
+func (f *fakeWA) GetGroupInfoFromLink(ctx context.Context, code string) (*types.GroupInfo, error) {
+	jid, _ := types.ParseJID("12345@g.us")
+	return &types.GroupInfo{JID: jid}, nil
+}
+
 func (f *fakeWA) JoinGroupWithLink(ctx context.Context, code string) (types.JID, error) {
 	return types.ParseJID("12345@g.us")
 }
 
 func (f *fakeWA) LeaveGroup(ctx context.Context, group types.JID) error { return nil }
 
+func (f *fakeWA) CreateGroup(ctx context.Context, req whatsmeow.ReqCreateGroup) (*types.GroupInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	jid := types.JID{User: fmt.Sprintf("new%d", len(f.groups)+1), Server: types.GroupServer}
+	g := &types.GroupInfo{JID: jid}
+	g.GroupName.Name = req.Name
+	g.IsParent = req.IsParent
+	g.LinkedParentJID = req.LinkedParentJID
+	f.groups[jid] = g
+	return g, nil
+}
+
+func (f *fakeWA) LinkGroup(ctx context.Context, parent, child types.JID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g := f.groups[child]
+	if g == nil {
+		g = &types.GroupInfo{JID: child}
+		f.groups[child] = g
+	}
+	g.LinkedParentJID = parent
+	return nil
+}
+
+func (f *fakeWA) UnlinkGroup(ctx context.Context, parent, child types.JID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if g := f.groups[child]; g != nil && g.LinkedParentJID == parent {
+		g.LinkedParentJID = types.JID{}
+	}
+	return nil
+}
+
+func (f *fakeWA) GetSubGroups(ctx context.Context, community types.JID) ([]*types.GroupLinkTarget, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*types.GroupLinkTarget
+	for _, g := range f.groups {
+		if g.LinkedParentJID == community {
+			out = append(out, &types.GroupLinkTarget{JID: g.JID, GroupName: g.GroupName})
+		}
+	}
+	return out, nil
+}
+
 func (f *fakeWA) SendText(ctx context.Context, to types.JID, text string) (types.MessageID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sentTexts = append(f.sentTexts, fakeSentText{to: to, text: text})
 	return types.MessageID("msgid"), nil
 }
 
 func (f *fakeWA) SendProtoMessage(ctx context.Context, to types.JID, msg *waProto.Message) (types.MessageID, error) {
-	return types.MessageID("msgid"), nil
+	id, _ := f.GenerateMessageID()
+	return f.SendProtoMessageWithID(ctx, to, msg, id)
+}
+
+// SendProtoMessageWithID fails with the next queued error in sendErrs, if any.
+func (f *fakeWA) SendProtoMessageWithID(ctx context.Context, to types.JID, msg *waProto.Message, id types.MessageID) (types.MessageID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.sendErrs) > 0 {
+		err := f.sendErrs[0]
+		f.sendErrs = f.sendErrs[1:]
+		if err != nil {
+			return "", err
+		}
+	}
+	f.sentMessages = append(f.sentMessages, fakeSentMessage{to: to, id: id, msg: msg})
+	return id, nil
+}
+
+func (f *fakeWA) GenerateMessageID() (types.MessageID, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextMsgID++
+	return types.MessageID(fmt.Sprintf("FAKE%04d", f.nextMsgID)), nil
 }
 
 func (f *fakeWA) Upload(ctx context.Context, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
-	return whatsmeow.UploadResponse{}, nil
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uploads++
+	return whatsmeow.UploadResponse{URL: "https://mmg.example/u", DirectPath: "/u", FileLength: uint64(len(data))}, nil
 }
 
 func (f *fakeWA) DecryptReaction(ctx context.Context, reaction *events.Message) (*waProto.ReactionMessage, error) {
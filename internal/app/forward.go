@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steipete/wacli/internal/store"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// ForwardMessage re-sends a stored message to another chat, marked as
+// forwarded. Media is sent by reference to the already-uploaded file (stored
+// direct path, media key and hashes), so nothing is downloaded or uploaded.
+func (a *App) ForwardMessage(ctx context.Context, chat, msgID string, to types.JID, id types.MessageID) (types.MessageID, error) {
+	m, err := a.db.GetMessage(chat, msgID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("message %s not found in %s", msgID, chat)
+	}
+	if err != nil {
+		return "", err
+	}
+	media, err := a.db.GetMediaDownloadInfo(m.ChatJID, m.MsgID)
+	if err != nil {
+		return "", err
+	}
+
+	fwd := &waProto.ContextInfo{
+		IsForwarded:     proto.Bool(true),
+		ForwardingScore: proto.Uint32(m.ForwardingScore + 1),
+	}
+	msg, err := forwardProto(m, media, fwd)
+	if err != nil {
+		return "", err
+	}
+	sentID, err := a.sendProto(ctx, to, msg, id)
+	if err != nil {
+		return "", err
+	}
+
+	a.recordSent(ctx, to, store.UpsertMessageParams{
+		MsgID:           string(sentID),
+		Text:            m.Text,
+		MediaType:       media.MediaType,
+		MediaCaption:    captionFor(media.MediaType, m.Text),
+		Filename:        media.Filename,
+		MimeType:        media.MimeType,
+		DirectPath:      media.DirectPath,
+		MediaKey:        media.MediaKey,
+		FileSHA256:      media.FileSHA256,
+		FileEncSHA256:   media.FileEncSHA256,
+		FileLength:      media.FileLength,
+		DurationSeconds: m.DurationSeconds,
+		PTT:             m.PTT,
+		Width:           m.Width,
+		Height:          m.Height,
+		LinkURL:         m.LinkURL,
+		LinkTitle:       m.LinkTitle,
+		LinkDescription: m.LinkDescription,
+		Forwarded:       true,
+		ForwardingScore: m.ForwardingScore + 1,
+	})
+	return sentID, nil
+}
+
+func forwardProto(m store.Message, media store.MediaDownloadInfo, fwd *waProto.ContextInfo) (*waProto.Message, error) {
+	mediaType := strings.ToLower(strings.TrimSpace(media.MediaType))
+	if mediaType == "" {
+		if strings.TrimSpace(m.Text) == "" {
+			return nil, fmt.Errorf("message %s has no stored text or media to forward", m.MsgID)
+		}
+		// Plain conversation messages can't carry context info.
+		ext := &waProto.ExtendedTextMessage{Text: proto.String(m.Text), ContextInfo: fwd}
+		if m.LinkTitle != "" || m.LinkDescription != "" {
+			ext.MatchedText = proto.String(m.LinkURL)
+			ext.Title = proto.String(m.LinkTitle)
+			ext.Description = proto.String(m.LinkDescription)
+		}
+		return &waProto.Message{ExtendedTextMessage: ext}, nil
+	}
+
+	if media.DirectPath == "" || len(media.MediaKey) == 0 || len(media.FileEncSHA256) == 0 {
+		return nil, fmt.Errorf("message %s: no stored media reference for its %s; it can't be forwarded without re-uploading", m.MsgID, mediaType)
+	}
+	caption := proto.String(captionFor(mediaType, m.Text))
+	switch mediaType {
+	case "image":
+		return &waProto.Message{ImageMessage: &waProto.ImageMessage{
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String(media.MimeType),
+			Caption:       caption,
+			Width:         proto.Uint32(uint32(m.Width)),
+			Height:        proto.Uint32(uint32(m.Height)),
+			ContextInfo:   fwd,
+		}}, nil
+	case "video", "gif":
+		return &waProto.Message{VideoMessage: &waProto.VideoMessage{
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String(media.MimeType),
+			Caption:       caption,
+			Seconds:       proto.Uint32(m.DurationSeconds),
+			Width:         proto.Uint32(uint32(m.Width)),
+			Height:        proto.Uint32(uint32(m.Height)),
+			GifPlayback:   proto.Bool(mediaType == "gif"),
+			ContextInfo:   fwd,
+		}}, nil
+	case "audio":
+		return &waProto.Message{AudioMessage: &waProto.AudioMessage{
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String(media.MimeType),
+			Seconds:       proto.Uint32(m.DurationSeconds),
+			PTT:           proto.Bool(m.PTT),
+			ContextInfo:   fwd,
+		}}, nil
+	case "document":
+		return &waProto.Message{DocumentMessage: &waProto.DocumentMessage{
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String(media.MimeType),
+			FileName:      proto.String(media.Filename),
+			Title:         proto.String(media.Filename),
+			Caption:       caption,
+			ContextInfo:   fwd,
+		}}, nil
+	case "sticker":
+		return &waProto.Message{StickerMessage: &waProto.StickerMessage{
+			DirectPath:    proto.String(media.DirectPath),
+			MediaKey:      media.MediaKey,
+			FileEncSHA256: media.FileEncSHA256,
+			FileSHA256:    media.FileSHA256,
+			FileLength:    proto.Uint64(media.FileLength),
+			Mimetype:      proto.String(media.MimeType),
+			Width:         proto.Uint32(uint32(m.Width)),
+			Height:        proto.Uint32(uint32(m.Height)),
+			ContextInfo:   fwd,
+		}}, nil
+	default:
+		return nil, fmt.Errorf("forwarding %s messages is not supported", mediaType)
+	}
+}
+
+// captionFor returns the stored text as a caption for media types that
+// display one.
+func captionFor(mediaType, text string) string {
+	switch mediaType {
+	case "image", "video", "gif", "document":
+		return text
+	}
+	return ""
+}
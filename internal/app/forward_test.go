@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestForwardMessageReusesStoredMedia(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	src := "111@s.whatsapp.net"
+	to := types.JID{User: "222", Server: types.DefaultUserServer}
+
+	if err := a.db.UpsertChat(src, "dm", "Alice", time.Now()); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	for _, p := range []store.UpsertMessageParams{
+		{
+			ChatJID: src, MsgID: "img1", Timestamp: time.Now(), Text: "look",
+			MediaType: "image", MediaCaption: "look", MimeType: "image/jpeg",
+			DirectPath: "/v/t62/abc", MediaKey: []byte("key"), FileSHA256: []byte("sha"), FileEncSHA256: []byte("enc"), FileLength: 1234,
+			Width: 640, Height: 480, Forwarded: true, ForwardingScore: 2,
+		},
+		{ChatJID: src, MsgID: "txt1", Timestamp: time.Now(), Text: "hello there"},
+		{ChatJID: src, MsgID: "doc1", Timestamp: time.Now(), MediaType: "document", Filename: "a.pdf"},
+	} {
+		if err := a.db.UpsertMessage(p); err != nil {
+			t.Fatalf("UpsertMessage: %v", err)
+		}
+	}
+
+	id, err := a.ForwardMessage(context.Background(), src, "img1", to, "")
+	if err != nil {
+		t.Fatalf("ForwardMessage: %v", err)
+	}
+	if f.uploads != 0 {
+		t.Fatalf("expected no upload, got %d", f.uploads)
+	}
+	img := f.sentMessages[0].msg.GetImageMessage()
+	if img.GetDirectPath() != "/v/t62/abc" || string(img.GetMediaKey()) != "key" || img.GetCaption() != "look" || img.GetWidth() != 640 {
+		t.Fatalf("unexpected forwarded image: %+v", img)
+	}
+	if ci := img.GetContextInfo(); !ci.GetIsForwarded() || ci.GetForwardingScore() != 3 {
+		t.Fatalf("unexpected context info: %+v", ci)
+	}
+	stored, err := a.db.GetMessage(to.String(), string(id))
+	if err != nil || !stored.Forwarded || stored.ForwardingScore != 3 {
+		t.Fatalf("expected stored forwarded copy, got %+v err=%v", stored, err)
+	}
+
+	if _, err := a.ForwardMessage(context.Background(), src, "txt1", to, ""); err != nil {
+		t.Fatalf("forward text: %v", err)
+	}
+	ext := f.sentMessages[1].msg.GetExtendedTextMessage()
+	if ext.GetText() != "hello there" || !ext.GetContextInfo().GetIsForwarded() || ext.GetContextInfo().GetForwardingScore() != 1 {
+		t.Fatalf("unexpected forwarded text: %+v", ext)
+	}
+
+	if _, err := a.ForwardMessage(context.Background(), src, "doc1", to, ""); err == nil {
+		t.Fatalf("expected media without a stored reference to be rejected")
+	}
+	if _, err := a.ForwardMessage(context.Background(), src, "missing", to, ""); err == nil {
+		t.Fatalf("expected unknown message to be rejected")
+	}
+
+	all, _ := a.db.ListMessages(store.ListMessagesParams{ChatJID: to.String()})
+	own, _ := a.db.ListMessages(store.ListMessagesParams{ChatJID: to.String(), ExcludeForwarded: true})
+	if len(all) != 2 || len(own) != 0 {
+		t.Fatalf("expected forwarded messages to be filterable, got all=%d own=%d", len(all), len(own))
+	}
+}
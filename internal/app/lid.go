@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// resolveLID maps a LID to its phone-number JID. alt is the alternate address
+// WhatsApp sent with the message, if any. Newly learned mappings are stored,
+// which also rewrites rows previously stored under the LID. JIDs that are not
+// LIDs, or LIDs without a known mapping, are returned unchanged.
+func (a *App) resolveLID(ctx context.Context, jid, alt types.JID) types.JID {
+	if jid.Server != types.HiddenUserServer {
+		return jid
+	}
+	lid := jid.ToNonAD()
+	if pn, err := a.db.GetPNForLID(lid.String()); err == nil && pn != "" {
+		if parsed, err := types.ParseJID(pn); err == nil {
+			return parsed
+		}
+	}
+
+	pn := types.JID{}
+	if alt.Server == types.DefaultUserServer {
+		pn = alt.ToNonAD()
+	} else if a.wa != nil {
+		if got, err := a.wa.GetPNForLID(ctx, lid); err == nil && got.Server == types.DefaultUserServer {
+			pn = got.ToNonAD()
+		}
+	}
+	if pn.IsEmpty() {
+		return jid
+	}
+	if err := a.db.SaveLIDMapping(lid.String(), pn.String()); err != nil {
+		return jid
+	}
+	return pn
+}
+
+// ResolveStoredLIDs looks up phone-number JIDs for every LID still referenced
+// by stored rows and rewrites those rows. It returns how many LIDs were mapped.
+func (a *App) ResolveStoredLIDs(ctx context.Context) (int, error) {
+	lids, err := a.db.ListUnmappedLIDs()
+	if err != nil {
+		return 0, err
+	}
+	mapped := 0
+	for _, s := range lids {
+		lid, err := types.ParseJID(s)
+		if err != nil {
+			continue
+		}
+		if a.resolveLID(ctx, lid, types.JID{}) != lid {
+			mapped++
+		}
+	}
+	return mapped, nil
+}
+
+// ParticipantJID prefers the phone-number JID of a group participant, learning
+// the LID mapping from the participant entry when WhatsApp includes it.
+func (a *App) ParticipantJID(ctx context.Context, p types.GroupParticipant) types.JID {
+	if p.JID.Server == types.HiddenUserServer {
+		return a.resolveLID(ctx, p.JID, p.PhoneNumber)
+	}
+	return p.JID
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSyncNormalizesLIDsToPhoneNumbers(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lid := types.JID{User: "777", Server: types.HiddenUserServer}
+	pn := types.JID{User: "111", Server: types.DefaultUserServer}
+	oldLID := types.JID{User: "888", Server: types.HiddenUserServer}
+	oldPN := types.JID{User: "222", Server: types.DefaultUserServer}
+
+	// A row stored before the mapping was known.
+	if err := a.DB().UpsertChat(oldLID.String(), "unknown", "Bob", base); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	if err := a.DB().UpsertMessage(store.UpsertMessageParams{ChatJID: oldLID.String(), MsgID: "old", SenderJID: oldLID.String(), Timestamp: base, Text: "old"}); err != nil {
+		t.Fatalf("UpsertMessage: %v", err)
+	}
+	f.lidToPN[oldLID] = oldPN
+
+	live := &events.Message{
+		Info: types.MessageInfo{
+			MessageSource: types.MessageSource{
+				Chat:      lid,
+				Sender:    lid,
+				SenderAlt: pn,
+			},
+			ID:        "m1",
+			Timestamp: base.Add(time.Second),
+			PushName:  "Alice",
+		},
+		Message: &waProto.Message{Conversation: proto.String("hi")},
+	}
+	f.connectEvents = []interface{}{live}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	m, err := a.DB().GetMessage(pn.String(), "m1")
+	if err != nil {
+		t.Fatalf("expected live message under phone-number chat: %v", err)
+	}
+	if m.SenderJID != pn.String() {
+		t.Fatalf("expected sender %s, got %s", pn, m.SenderJID)
+	}
+	if _, err := a.DB().GetMessage(oldPN.String(), "old"); err != nil {
+		t.Fatalf("expected stored LID message to be rewritten: %v", err)
+	}
+	// Lookups by LID resolve to the phone-number chat.
+	if _, err := a.DB().GetMessage(lid.String(), "m1"); err != nil {
+		t.Fatalf("expected LID lookup to resolve: %v", err)
+	}
+	c, err := a.DB().GetChat(pn.String())
+	if err != nil {
+		t.Fatalf("GetChat: %v", err)
+	}
+	if c.Kind != "dm" {
+		t.Fatalf("expected dm chat, got %q", c.Kind)
+	}
+}
+
+func TestChatKindClassifiesLIDAsDM(t *testing.T) {
+	if got := chatKind(types.JID{User: "777", Server: types.HiddenUserServer}); got != "dm" {
+		t.Fatalf("expected dm, got %q", got)
+	}
+}
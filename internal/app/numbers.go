@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const numberCheckBatchSize = 50
+
+type NumberCheckResult struct {
+	Input        string    `json:"input"`
+	Phone        string    `json:"phone"`
+	Registered   bool      `json:"registered"`
+	JID          string    `json:"jid,omitempty"`
+	Business     bool      `json:"business"`
+	BusinessName string    `json:"business_name,omitempty"`
+	CheckedAt    time.Time `json:"checked_at"`
+	Cached       bool      `json:"cached"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type NumberCheckOptions struct {
+	// MaxAge reuses cached results newer than this; zero always asks WhatsApp.
+	MaxAge time.Duration
+	// Connect is called once before the first live lookup.
+	Connect func(context.Context) error
+}
+
+// CheckNumbers resolves phone numbers to WhatsApp JIDs and caches the outcome
+// in the contacts table. Results are returned in input order.
+func (a *App) CheckNumbers(ctx context.Context, inputs []string, opts NumberCheckOptions) ([]NumberCheckResult, error) {
+	results := make([]NumberCheckResult, len(inputs))
+	var pending []int
+	now := time.Now().UTC()
+	for i, in := range inputs {
+		results[i].Input = in
+		phone, err := wa.NormalizePhone(in)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Phone = phone
+		if opts.MaxAge > 0 {
+			if c, err := a.db.GetNumberCheck(phone); err == nil && now.Sub(c.CheckedAt) <= opts.MaxAge {
+				results[i].Registered = c.Registered
+				if c.Registered {
+					results[i].JID = c.JID
+				}
+				results[i].Business = c.Business
+				results[i].BusinessName = c.BusinessName
+				results[i].CheckedAt = c.CheckedAt
+				results[i].Cached = true
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	if opts.Connect != nil {
+		if err := opts.Connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for start := 0; start < len(pending); start += numberCheckBatchSize {
+		end := start + numberCheckBatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		batch := pending[start:end]
+		queries := make([]string, 0, len(batch))
+		for _, idx := range batch {
+			queries = append(queries, "+"+results[idx].Phone)
+		}
+		resp, err := a.wa.IsOnWhatsApp(ctx, queries)
+		if err != nil {
+			return nil, err
+		}
+		byPhone := map[string]types.IsOnWhatsAppResponse{}
+		for _, r := range resp {
+			byPhone[strings.TrimPrefix(r.Query, "+")] = r
+		}
+
+		checkedAt := time.Now().UTC()
+		for _, idx := range batch {
+			res := &results[idx]
+			res.CheckedAt = checkedAt
+			r, ok := byPhone[res.Phone]
+			jid := types.JID{User: res.Phone, Server: types.DefaultUserServer}
+			if ok && r.IsIn {
+				res.Registered = true
+				if !r.JID.IsEmpty() {
+					jid = r.JID.ToNonAD()
+				}
+				res.JID = jid.String()
+				if r.VerifiedName != nil {
+					res.Business = true
+					if d := r.VerifiedName.Details; d != nil {
+						res.BusinessName = d.GetVerifiedName()
+					}
+				}
+			}
+			_ = a.db.SaveNumberCheck(store.NumberCheck{
+				Phone:        res.Phone,
+				JID:          jid.String(),
+				Registered:   res.Registered,
+				Business:     res.Business,
+				BusinessName: res.BusinessName,
+				CheckedAt:    checkedAt,
+			})
+		}
+	}
+	return results, nil
+}
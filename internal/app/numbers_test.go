@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestCheckNumbersCachesResults(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+
+	registered := types.JID{User: "15550102030", Server: types.DefaultUserServer}
+	f.registered["+15550102030"] = types.IsOnWhatsAppResponse{JID: registered, IsIn: true}
+
+	connects := 0
+	opts := NumberCheckOptions{
+		MaxAge:  time.Hour,
+		Connect: func(context.Context) error { connects++; return nil },
+	}
+	res, err := a.CheckNumbers(context.Background(), []string{"+1 555 010 2030", "+1 555 010 9999", "nope"}, opts)
+	if err != nil {
+		t.Fatalf("CheckNumbers: %v", err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("expected 3 results, got %+v", res)
+	}
+	if !res[0].Registered || res[0].JID != registered.String() || res[0].Cached {
+		t.Fatalf("unexpected registered result: %+v", res[0])
+	}
+	if res[1].Registered || res[1].JID != "" {
+		t.Fatalf("unexpected unregistered result: %+v", res[1])
+	}
+	if res[2].Error == "" {
+		t.Fatalf("expected error for invalid input, got %+v", res[2])
+	}
+	if connects != 1 {
+		t.Fatalf("expected one connect, got %d", connects)
+	}
+
+	c, err := a.db.GetNumberCheck("15550102030")
+	if err != nil {
+		t.Fatalf("GetNumberCheck: %v", err)
+	}
+	if !c.Registered || c.CheckedAt.IsZero() {
+		t.Fatalf("expected cached registered check, got %+v", c)
+	}
+
+	res, err = a.CheckNumbers(context.Background(), []string{"15550102030", "15550109999"}, opts)
+	if err != nil {
+		t.Fatalf("CheckNumbers cached: %v", err)
+	}
+	if !res[0].Cached || !res[0].Registered || !res[1].Cached || res[1].Registered {
+		t.Fatalf("expected cached results, got %+v", res)
+	}
+	if connects != 1 {
+		t.Fatalf("expected no reconnect for cached lookups, got %d", connects)
+	}
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Outbox retry policy: attempts back off exponentially from outboxRetryBase
+// up to outboxRetryMax, and an item is marked failed after
+// OutboxMaxAttempts.
+const (
+	OutboxMaxAttempts = 5
+	outboxRetryBase   = 30 * time.Second
+	outboxRetryMax    = 30 * time.Minute
+
+	outboxPollInterval = 15 * time.Second
+)
+
+type OutboxResult struct {
+	Sent    int `json:"sent"`
+	Retried int `json:"retried"`
+	Failed  int `json:"failed"`
+}
+
+// DeliverOutbox sends every outbox item that is due. Each item gets a message
+// ID before its first attempt and keeps it across retries and crashes, so
+// WhatsApp drops a resend of a message that already went out.
+func (a *App) DeliverOutbox(ctx context.Context) (OutboxResult, error) {
+	var res OutboxResult
+	due, err := a.db.DueOutbox(time.Now().UTC(), 0)
+	if err != nil {
+		return res, err
+	}
+	for _, it := range due {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+		msgID := it.MsgID
+		if msgID == "" {
+			id, err := a.wa.GenerateMessageID()
+			if err != nil {
+				return res, err
+			}
+			msgID = string(id)
+		}
+		it, err = a.db.BeginOutboxAttempt(it.ID, msgID)
+		if err != nil {
+			return res, err
+		}
+		if it.Status != store.OutboxSending {
+			continue // canceled or rescheduled meanwhile
+		}
+
+		// A previous run may have sent and stored the message but died before
+		// marking the item sent.
+		if _, err := a.db.GetMessage(it.ChatJID, it.MsgID); err == nil {
+			if err := a.db.MarkOutboxSent(it.ID, it.MsgID, time.Now().UTC()); err != nil {
+				return res, err
+			}
+			res.Sent++
+			continue
+		}
+
+		sentID, sendErr := a.sendOutboxItem(ctx, it)
+		switch {
+		case sendErr == nil:
+			err = a.db.MarkOutboxSent(it.ID, string(sentID), time.Now().UTC())
+			res.Sent++
+		case it.Attempts >= OutboxMaxAttempts:
+			err = a.db.MarkOutboxFailed(it.ID, sendErr.Error())
+			res.Failed++
+		default:
+			err = a.db.MarkOutboxRetry(it.ID, sendErr.Error(), time.Now().UTC().Add(outboxBackoff(it.Attempts)))
+			res.Retried++
+		}
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// deliverOutboxEvery delivers what is due now and then on every tick until ctx
+// is done.
+func (a *App) deliverOutboxEvery(ctx context.Context, interval time.Duration) {
+	a.deliverOutboxQuietly(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.deliverOutboxQuietly(ctx)
+		}
+	}
+}
+
+// deliverOutboxQuietly runs DeliverOutbox from sync, reporting only activity
+// and errors.
+func (a *App) deliverOutboxQuietly(ctx context.Context) {
+	res, err := a.DeliverOutbox(ctx)
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintf(os.Stderr, "\nOutbox: %v\n", err)
+	}
+	if res.Sent+res.Retried+res.Failed > 0 {
+		fmt.Fprintf(os.Stderr, "\nOutbox: sent %d, retrying %d, failed %d\n", res.Sent, res.Retried, res.Failed)
+	}
+}
+
+func (a *App) sendOutboxItem(ctx context.Context, it store.OutboxItem) (types.MessageID, error) {
+	to, err := types.ParseJID(it.ChatJID)
+	if err != nil {
+		return "", err
+	}
+	id := types.MessageID(it.MsgID)
+	switch it.Kind {
+	case "text":
+		return a.SendText(ctx, to, it.Text, id)
+	case "link":
+		sent, err := a.SendTextWithPreview(ctx, to, it.Text, id)
+		return sent.ID, err
+	case "file":
+		sent, err := a.SendFile(ctx, to, SendFileOptions{
+			Path:     it.FilePath,
+			Filename: it.Filename,
+			Caption:  it.Text,
+			MimeType: it.MimeType,
+			ID:       id,
+		})
+		return sent.ID, err
+	default:
+		return "", fmt.Errorf("unsupported outbox kind %q", it.Kind)
+	}
+}
+
+// outboxBackoff returns the delay before the attempt following attempt n.
+func outboxBackoff(n int) time.Duration {
+	d := outboxRetryBase
+	for i := 1; i < n && d < outboxRetryMax; i++ {
+		d *= 2
+	}
+	if d > outboxRetryMax {
+		d = outboxRetryMax
+	}
+	return d
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestDeliverOutboxRetriesWithSameIDThenFails(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+
+	id, err := a.db.EnqueueOutbox(store.OutboxItem{ChatJID: to.String(), Kind: "text", Text: "hello", SendAt: time.Now().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("EnqueueOutbox: %v", err)
+	}
+
+	ctx := context.Background()
+	f.sendErrs = []error{errors.New("boom")}
+	res, err := a.DeliverOutbox(ctx)
+	if err != nil {
+		t.Fatalf("DeliverOutbox: %v", err)
+	}
+	if res.Retried != 1 || res.Sent != 0 {
+		t.Fatalf("expected a retry, got %+v", res)
+	}
+	it, _ := a.db.GetOutboxItem(id)
+	if it.Status != store.OutboxPending || it.LastError != "boom" || it.MsgID == "" || !it.NextAttemptAt.After(time.Now()) {
+		t.Fatalf("unexpected item after failure: %+v", it)
+	}
+	firstID := it.MsgID
+
+	// Not due yet: nothing happens.
+	if res, _ := a.DeliverOutbox(ctx); res != (OutboxResult{}) {
+		t.Fatalf("expected backoff to defer delivery, got %+v", res)
+	}
+
+	if err := a.db.MarkOutboxRetry(id, it.LastError, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("MarkOutboxRetry: %v", err)
+	}
+	res, err = a.DeliverOutbox(ctx)
+	if err != nil || res.Sent != 1 {
+		t.Fatalf("expected delivery, got %+v err=%v", res, err)
+	}
+	if len(f.sentMessages) != 1 || string(f.sentMessages[0].id) != firstID {
+		t.Fatalf("expected retry to reuse message ID %s, got %+v", firstID, f.sentMessages)
+	}
+	it, _ = a.db.GetOutboxItem(id)
+	if it.Status != store.OutboxSent || it.MsgID != firstID || it.SentAt.IsZero() {
+		t.Fatalf("unexpected sent item: %+v", it)
+	}
+	if _, err := a.db.GetMessage(to.String(), firstID); err != nil {
+		t.Fatalf("expected sent message to be stored: %v", err)
+	}
+
+	// Exhausting the attempts marks the item failed.
+	id2, _ := a.db.EnqueueOutbox(store.OutboxItem{ChatJID: to.String(), Kind: "text", Text: "again", SendAt: time.Now().Add(-time.Second)})
+	for i := 1; i <= OutboxMaxAttempts; i++ {
+		f.sendErrs = []error{errors.New("down")}
+		res, err = a.DeliverOutbox(ctx)
+		if err != nil {
+			t.Fatalf("DeliverOutbox: %v", err)
+		}
+		if i < OutboxMaxAttempts {
+			// Skip the backoff wait.
+			_ = a.db.MarkOutboxRetry(id2, "down", time.Now().Add(-time.Second))
+		}
+	}
+	it, _ = a.db.GetOutboxItem(id2)
+	if res.Failed != 1 || it.Status != store.OutboxFailed || it.Attempts != OutboxMaxAttempts {
+		t.Fatalf("expected item to fail after %d attempts, got %+v (%+v)", OutboxMaxAttempts, it, res)
+	}
+}
+
+func TestSyncFollowDeliversOutbox(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+
+	id, err := a.db.EnqueueOutbox(store.OutboxItem{ChatJID: to.String(), Kind: "text", Text: "due", SendAt: time.Now().Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("EnqueueOutbox: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	// Delivery runs on its own goroutine; Sync waits for it before returning.
+	it, _ := a.db.GetOutboxItem(id)
+	if it.Status != store.OutboxSent {
+		t.Fatalf("expected the due item to be sent during sync, got %+v", it)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.sentMessages) != 1 || f.sentMessages[0].to != to {
+		t.Fatalf("expected one message to %s, got %+v", to, f.sentMessages)
+	}
+}
+
+func TestDeliverOutboxSkipsResendAfterCrash(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+
+	id, _ := a.db.EnqueueOutbox(store.OutboxItem{ChatJID: to.String(), Kind: "text", Text: "hello", SendAt: time.Now().Add(-time.Second)})
+	// Simulate a run that sent and stored the message, then crashed.
+	if _, err := a.db.BeginOutboxAttempt(id, "CRASHED1"); err != nil {
+		t.Fatalf("BeginOutboxAttempt: %v", err)
+	}
+	a.recordSent(context.Background(), to, store.UpsertMessageParams{MsgID: "CRASHED1", Text: "hello"})
+
+	res, err := a.DeliverOutbox(context.Background())
+	if err != nil || res.Sent != 1 {
+		t.Fatalf("expected item to be marked sent, got %+v err=%v", res, err)
+	}
+	if len(f.sentMessages) != 0 {
+		t.Fatalf("expected no resend, got %+v", f.sentMessages)
+	}
+	if it, _ := a.db.GetOutboxItem(id); it.Status != store.OutboxSent || it.MsgID != "CRASHED1" {
+		t.Fatalf("unexpected item: %+v", it)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	if got := outboxBackoff(1); got != outboxRetryBase {
+		t.Fatalf("backoff(1) = %s", got)
+	}
+	if got := outboxBackoff(3); got != 4*outboxRetryBase {
+		t.Fatalf("backoff(3) = %s", got)
+	}
+	if got := outboxBackoff(20); got != outboxRetryMax {
+		t.Fatalf("backoff(20) = %s", got)
+	}
+}
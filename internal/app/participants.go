@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// DefaultParticipantChunkSize keeps each participant update well below the
+// number of users WhatsApp accepts in a single request.
+const DefaultParticipantChunkSize = 20
+
+type ParticipantUpdateOptions struct {
+	Action     wa.GroupParticipantAction
+	ChunkSize  int
+	ChunkDelay time.Duration
+}
+
+type ParticipantResult struct {
+	User             string     `json:"user"`
+	JID              string     `json:"jid"`
+	Status           string     `json:"status"`
+	Code             int        `json:"code,omitempty"`
+	InviteCode       string     `json:"invite_code,omitempty"`
+	InviteExpiration *time.Time `json:"invite_expiration,omitempty"`
+	InviteSent       bool       `json:"invite_sent,omitempty"`
+	Error            string     `json:"error,omitempty"`
+}
+
+// UpdateGroupParticipants applies action to users in chunks and reports the
+// outcome for every requested user, in request order.
+func (a *App) UpdateGroupParticipants(ctx context.Context, group types.JID, users []types.JID, opts ParticipantUpdateOptions) ([]ParticipantResult, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultParticipantChunkSize
+	}
+
+	results := make([]ParticipantResult, 0, len(users))
+	for start := 0; start < len(users); start += opts.ChunkSize {
+		if start > 0 && opts.ChunkDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			case <-time.After(opts.ChunkDelay):
+			}
+		}
+		end := start + opts.ChunkSize
+		if end > len(users) {
+			end = len(users)
+		}
+		chunk := users[start:end]
+
+		updated, err := a.wa.UpdateGroupParticipants(ctx, group, chunk, opts.Action)
+		if err != nil {
+			for _, u := range chunk {
+				results = append(results, ParticipantResult{User: u.User, JID: u.String(), Status: "failed", Error: err.Error()})
+			}
+			continue
+		}
+		results = append(results, matchParticipantResults(chunk, updated, opts.Action)...)
+	}
+	return results, nil
+}
+
+func matchParticipantResults(requested []types.JID, updated []types.GroupParticipant, action wa.GroupParticipantAction) []ParticipantResult {
+	byJID := map[string]types.GroupParticipant{}
+	for _, p := range updated {
+		for _, j := range []types.JID{p.JID, p.PhoneNumber, p.LID} {
+			if !j.IsEmpty() {
+				byJID[j.ToNonAD().String()] = p
+			}
+		}
+	}
+
+	out := make([]ParticipantResult, 0, len(requested))
+	for _, u := range requested {
+		r := ParticipantResult{User: u.User, JID: u.String()}
+		p, ok := byJID[u.ToNonAD().String()]
+		if !ok {
+			r.Status = "unknown"
+			out = append(out, r)
+			continue
+		}
+		r.Code = p.Error
+		r.Status = ParticipantStatus(action, p.Error)
+		if p.AddRequest != nil {
+			r.InviteCode = p.AddRequest.Code
+			if exp := p.AddRequest.Expiration; !exp.IsZero() {
+				r.InviteExpiration = &exp
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// AnyParticipantUpdated reports whether at least one user was changed, was
+// already in the wanted state, or got an invite instead.
+func AnyParticipantUpdated(results []ParticipantResult) bool {
+	for _, r := range results {
+		switch {
+		case r.InviteSent:
+			return true
+		case r.Code == 0 || r.Code == 200:
+			if r.Status != "failed" && r.Status != "unknown" {
+				return true
+			}
+		case r.Status == "already_member":
+			return true
+		}
+	}
+	return false
+}
+
+// ParticipantStatus maps the per-participant error code returned by WhatsApp
+// to a short status label.
+func ParticipantStatus(action wa.GroupParticipantAction, code int) string {
+	switch code {
+	case 0, 200:
+		switch action {
+		case wa.GroupParticipantAdd:
+			return "added"
+		case wa.GroupParticipantRemove:
+			return "removed"
+		case wa.GroupParticipantPromote:
+			return "promoted"
+		case wa.GroupParticipantDemote:
+			return "demoted"
+		}
+		return "ok"
+	case 401:
+		return "blocked"
+	case 403:
+		return "privacy_blocked"
+	case 404:
+		if action == wa.GroupParticipantAdd {
+			return "not_on_whatsapp"
+		}
+		return "not_member"
+	case 408:
+		return "recently_left"
+	case 409:
+		if action == wa.GroupParticipantAdd {
+			return "already_member"
+		}
+		return "conflict"
+	case 500:
+		return "group_full"
+	default:
+		return fmt.Sprintf("error_%d", code)
+	}
+}
+
+// SendGroupInvites DMs the group's invite link to every result whose add was
+// refused by the user's privacy settings. message is prepended to the link.
+func (a *App) SendGroupInvites(ctx context.Context, group types.JID, results []ParticipantResult, message string) error {
+	var link string
+	for i := range results {
+		if results[i].Status != "privacy_blocked" {
+			continue
+		}
+		if link == "" {
+			l, err := a.wa.GetGroupInviteLink(ctx, group, false)
+			if err != nil {
+				return fmt.Errorf("get invite link: %w", err)
+			}
+			link = l
+		}
+		to, err := types.ParseJID(results[i].JID)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		text := link
+		if m := strings.TrimSpace(message); m != "" {
+			text = m + "\n" + link
+		}
+		id, err := a.wa.SendText(ctx, to, text)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].InviteSent = true
+
+		now := time.Now().UTC()
+		chatName := a.wa.ResolveChatName(ctx, to, "")
+		_ = a.db.UpsertChat(to.String(), chatKind(to), chatName, now)
+		_ = a.db.UpsertMessage(store.UpsertMessageParams{
+			ChatJID:    to.String(),
+			ChatName:   chatName,
+			MsgID:      string(id),
+			SenderName: "me",
+			Timestamp:  now,
+			FromMe:     true,
+			Text:       text,
+		})
+	}
+	return nil
+}
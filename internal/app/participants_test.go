@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestUpdateGroupParticipantsChunksAndReportsStatus(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+
+	group := types.JID{User: "123", Server: types.GroupServer}
+	var users []types.JID
+	for i := 0; i < 5; i++ {
+		users = append(users, types.JID{User: fmt.Sprintf("100%d", i), Server: types.DefaultUserServer})
+	}
+	f.participantErrors[users[1]] = 409
+	f.participantErrors[users[2]] = 403
+	f.participantErrors[users[3]] = 404
+
+	results, err := a.UpdateGroupParticipants(context.Background(), group, users, ParticipantUpdateOptions{
+		Action:    wa.GroupParticipantAdd,
+		ChunkSize: 2,
+	})
+	if err != nil {
+		t.Fatalf("UpdateGroupParticipants: %v", err)
+	}
+	if len(f.participantCalls) != 3 {
+		t.Fatalf("expected 3 chunked calls, got %d", len(f.participantCalls))
+	}
+	want := []string{"added", "already_member", "privacy_blocked", "not_on_whatsapp", "added"}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	for i, r := range results {
+		if r.Status != want[i] || r.JID != users[i].String() {
+			t.Fatalf("result %d: expected %s for %s, got %+v", i, want[i], users[i], r)
+		}
+	}
+
+	if err := a.SendGroupInvites(context.Background(), group, results, "Join us"); err != nil {
+		t.Fatalf("SendGroupInvites: %v", err)
+	}
+	if len(f.sentTexts) != 1 || f.sentTexts[0].to != users[2] {
+		t.Fatalf("expected one invite DM to %s, got %+v", users[2], f.sentTexts)
+	}
+	if !strings.Contains(f.sentTexts[0].text, "https://chat.whatsapp.com/") {
+		t.Fatalf("expected invite link in DM, got %q", f.sentTexts[0].text)
+	}
+	if !results[2].InviteSent {
+		t.Fatalf("expected InviteSent for privacy-blocked user")
+	}
+}
+
+func TestAnyParticipantUpdated(t *testing.T) {
+	failed := []ParticipantResult{
+		{Status: "failed", Error: "timeout"},
+		{Status: "not_on_whatsapp", Code: 404},
+		{Status: "privacy_blocked", Code: 403},
+		{Status: "unknown"},
+	}
+	if AnyParticipantUpdated(failed) {
+		t.Fatalf("expected no success in %+v", failed)
+	}
+	for _, ok := range []ParticipantResult{
+		{Status: "added", Code: 200},
+		{Status: "already_member", Code: 409},
+		{Status: "privacy_blocked", Code: 403, InviteSent: true},
+	} {
+		if !AnyParticipantUpdated(append(failed, ok)) {
+			t.Fatalf("expected %+v to count as a success", ok)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// RecipientOptions selects the chats a message goes to. All sources are
+// combined.
+type RecipientOptions struct {
+	To   []string // phone numbers or JIDs
+	Tag  string   // every contact with this tag
+	File string   // one phone number or JID per line; blank lines and # comments are skipped
+}
+
+// ResolveRecipients returns the chats selected by opts in the order given,
+// without duplicates. A broadcast list stands for its known members.
+func (a *App) ResolveRecipients(opts RecipientOptions) ([]types.JID, error) {
+	var out []types.JID
+	seen := map[types.JID]bool{}
+	add := func(s, source string) error {
+		jid, err := parseBulkRecipient(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if jid == types.StatusBroadcastJID {
+			return fmt.Errorf("%s: status updates are not a chat", source)
+		}
+		if jid.Server == types.BroadcastServer {
+			// whatsmeow can't send to a broadcast list, so each member known
+			// from history sync gets the message in their own chat, as the
+			// phone does.
+			members, err := a.db.BroadcastMembers(jid.String())
+			if err != nil {
+				return err
+			}
+			if len(members) == 0 {
+				return fmt.Errorf("%s: no known members for broadcast list %s; run `wacli sync` to import it, or use --to-tag or --to-file", source, jid)
+			}
+			for _, m := range members {
+				mj, err := types.ParseJID(m)
+				if err != nil {
+					return fmt.Errorf("%s: member %s: %w", source, m, err)
+				}
+				if !seen[mj] {
+					seen[mj] = true
+					out = append(out, mj)
+				}
+			}
+			return nil
+		}
+		if !seen[jid] {
+			seen[jid] = true
+			out = append(out, jid)
+		}
+		return nil
+	}
+
+	for _, s := range opts.To {
+		if err := add(s, "--to "+s); err != nil {
+			return nil, err
+		}
+	}
+	if tag := strings.TrimSpace(opts.Tag); tag != "" {
+		jids, err := a.db.ContactsWithTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if len(jids) == 0 {
+			return nil, fmt.Errorf("no contacts tagged %q", tag)
+		}
+		for _, s := range jids {
+			if err := add(s, "tag "+tag); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if opts.File != "" {
+		f, err := os.Open(opts.File)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for line := 1; sc.Scan(); line++ {
+			s := strings.TrimSpace(sc.Text())
+			if s == "" || strings.HasPrefix(s, "#") {
+				continue
+			}
+			if err := add(s, fmt.Sprintf("%s:%d", opts.File, line)); err != nil {
+				return nil, err
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no recipients")
+	}
+	return out, nil
+}
+
+// RecipientSendKey is the idempotency key guarding the send of key to one
+// recipient. Every recipient gets its own key, whether it is sent to alone or
+// in a list, so rerunning with recipients added sends only to the new ones.
+func RecipientSendKey(key string, to types.JID) string {
+	return key + "/" + to.String()
+}
+
+// recordBroadcastMembers stores the members history sync reports for a
+// broadcast list, so the list can be used as a send target.
+func (a *App) recordBroadcastMembers(ctx context.Context, list types.JID, participants []*waHistorySync.GroupParticipant) {
+	if list.Server != types.BroadcastServer || list == types.StatusBroadcastJID || len(participants) == 0 {
+		return
+	}
+	members := make([]string, 0, len(participants))
+	for _, p := range participants {
+		jid, err := types.ParseJID(p.GetUserJID())
+		if err != nil || jid.User == "" {
+			continue
+		}
+		members = append(members, a.resolveLID(ctx, jid.ToNonAD(), types.JID{}).String())
+	}
+	_ = a.db.ReplaceBroadcastMembers(list.String(), members)
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestResolveRecipients(t *testing.T) {
+	a := newTestApp(t)
+	for _, jid := range []string{"15550000111@s.whatsapp.net", "15550000222@s.whatsapp.net", "15550000333@s.whatsapp.net"} {
+		if err := a.db.UpsertContact(jid, "", "", "", "", ""); err != nil {
+			t.Fatalf("UpsertContact: %v", err)
+		}
+	}
+	_ = a.db.AddTag("15550000222@s.whatsapp.net", "team")
+	_ = a.db.AddTag("15550000333@s.whatsapp.net", "team")
+
+	file := filepath.Join(t.TempDir(), "to.txt")
+	if err := os.WriteFile(file, []byte("# weekly list\n+1 (555) 000-0444\n\n15550000333@s.whatsapp.net\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := a.ResolveRecipients(RecipientOptions{To: []string{"15550000111", "15550000222@s.whatsapp.net"}, Tag: "team", File: file})
+	if err != nil {
+		t.Fatalf("ResolveRecipients: %v", err)
+	}
+	want := []string{"15550000111@s.whatsapp.net", "15550000222@s.whatsapp.net", "15550000333@s.whatsapp.net", "15550000444@s.whatsapp.net"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	if _, err := a.ResolveRecipients(RecipientOptions{Tag: "nobody"}); err == nil {
+		t.Fatalf("expected an unknown tag to be rejected")
+	}
+	if _, err := a.ResolveRecipients(RecipientOptions{To: []string{"status@broadcast"}}); err == nil {
+		t.Fatalf("expected status broadcast to be rejected as a recipient")
+	}
+	if _, err := a.ResolveRecipients(RecipientOptions{To: []string{"120363000000000000@broadcast"}}); err == nil {
+		t.Fatalf("expected a broadcast list without known members to be rejected")
+	}
+}
+
+func TestBroadcastListRecipientsFromHistorySync(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+
+	list := "1712345678@broadcast"
+	f.connectEvents = []interface{}{&events.HistorySync{Data: &waHistorySync.HistorySync{
+		SyncType: waHistorySync.HistorySync_FULL.Enum(),
+		Conversations: []*waHistorySync.Conversation{{
+			ID: proto.String(list),
+			Participant: []*waHistorySync.GroupParticipant{
+				{UserJID: proto.String("15550000222@s.whatsapp.net")},
+				{UserJID: proto.String("15550000111@s.whatsapp.net")},
+			},
+		}},
+	}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := a.Sync(ctx, SyncOptions{Mode: SyncModeFollow}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	got, err := a.ResolveRecipients(RecipientOptions{To: []string{"15550000111", list}})
+	if err != nil {
+		t.Fatalf("ResolveRecipients: %v", err)
+	}
+	want := []string{"15550000111@s.whatsapp.net", "15550000222@s.whatsapp.net"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestRecipientSendKeyReusedWhenAddingRecipients(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	alice := types.JID{User: "15550000111", Server: types.DefaultUserServer}
+	bob := types.JID{User: "15550000222", Server: types.DefaultUserServer}
+
+	// First run: --to alice --idempotency-key k.
+	first, err := a.ReserveSendKey(RecipientSendKey("k", alice), alice)
+	if err != nil {
+		t.Fatalf("ReserveSendKey: %v", err)
+	}
+	if err := a.db.CompleteSendKey(RecipientSendKey("k", alice), string(first), `{"id":"`+string(first)+`"}`); err != nil {
+		t.Fatalf("CompleteSendKey: %v", err)
+	}
+
+	// Second run: --to alice --to bob --idempotency-key k.
+	prev, err := a.db.GetSendKey(RecipientSendKey("k", alice))
+	if err != nil || prev.SentAt.IsZero() || prev.MsgID != string(first) {
+		t.Fatalf("expected alice's send to be found as done, got %+v err=%v", prev, err)
+	}
+	if _, err := a.db.GetSendKey(RecipientSendKey("k", bob)); !store.IsNotFound(err) {
+		t.Fatalf("expected no send recorded for bob, got %v", err)
+	}
+	second, err := a.ReserveSendKey(RecipientSendKey("k", bob), bob)
+	if err != nil || second == first {
+		t.Fatalf("expected a new ID for bob, got %q (err=%v)", second, err)
+	}
+}
+
+func TestSendPreparedFileUploadsOnce(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4 report"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	p, err := a.PrepareFile(context.Background(), SendFileOptions{Path: path, Caption: "Q3"})
+	if err != nil {
+		t.Fatalf("PrepareFile: %v", err)
+	}
+	recipients := []types.JID{
+		{User: "111", Server: types.DefaultUserServer},
+		{User: "222", Server: types.DefaultUserServer},
+		{User: "333", Server: types.DefaultUserServer},
+	}
+	for _, to := range recipients {
+		if _, err := a.SendPreparedFile(context.Background(), to, p, ""); err != nil {
+			t.Fatalf("SendPreparedFile: %v", err)
+		}
+	}
+	if f.uploads != 1 {
+		t.Fatalf("expected one upload, got %d", f.uploads)
+	}
+	if len(f.sentMessages) != 3 || f.sentMessages[0].msg == f.sentMessages[1].msg {
+		t.Fatalf("expected a separate message per recipient, got %+v", f.sentMessages)
+	}
+	for _, to := range recipients {
+		msgs, err := a.db.ListMessages(store.ListMessagesParams{ChatJID: to.String(), Type: "document"})
+		if err != nil || len(msgs) != 1 {
+			t.Fatalf("expected stored document in %s, got %+v err=%v", to, msgs, err)
+		}
+	}
+}
@@ -0,0 +1,510 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steipete/wacli/internal/linkpreview"
+	"github.com/steipete/wacli/internal/mediainfo"
+	"github.com/steipete/wacli/internal/oggopus"
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// Sends that fail with a transient error are retried up to sendAttempts times,
+// waiting sendRetryDelay (doubling) in between.
+var (
+	sendAttempts   = 3
+	sendRetryDelay = 2 * time.Second
+)
+
+var ErrIdempotencyConflict = errors.New("idempotency key was already used for a different recipient")
+
+type SendFileOptions struct {
+	Path     string
+	Filename string // display name; defaults to the base name of Path
+	Caption  string
+	MimeType string // overrides detection
+	// ID, when set, is used as the WhatsApp message ID (see SendText).
+	ID types.MessageID
+}
+
+type SentFile struct {
+	ID        types.MessageID `json:"id"`
+	Name      string          `json:"name"`
+	MimeType  string          `json:"mime_type"`
+	MediaType string          `json:"media"`
+	Width     int             `json:"width,omitempty"`
+	Height    int             `json:"height,omitempty"`
+	Seconds   uint32          `json:"seconds,omitempty"`
+}
+
+// SendText sends text to a chat and stores the sent message. A non-empty id is
+// used as the WhatsApp message ID so a retried send is deduplicated.
+func (a *App) SendText(ctx context.Context, to types.JID, text string, id types.MessageID) (types.MessageID, error) {
+	return a.sendText(ctx, to, text, id, nil)
+}
+
+type SentText struct {
+	ID      types.MessageID      `json:"id"`
+	Preview *linkpreview.Preview `json:"link_preview,omitempty"`
+	// PreviewErr says why no preview was attached.
+	PreviewErr error `json:"-"`
+}
+
+// SendTextWithPreview sends text like SendText, with a link preview for the
+// first URL in it. If there is no URL or the page can't be fetched, the text
+// is still sent, without a preview, and PreviewErr says why.
+func (a *App) SendTextWithPreview(ctx context.Context, to types.JID, text string, id types.MessageID) (SentText, error) {
+	var out SentText
+	out.Preview, out.PreviewErr = a.LinkPreview(ctx, text)
+	sentID, err := a.sendText(ctx, to, text, id, out.Preview)
+	if err != nil {
+		return SentText{}, err
+	}
+	out.ID = sentID
+	return out, nil
+}
+
+// LinkPreview fetches the preview for the first URL in text.
+func (a *App) LinkPreview(ctx context.Context, text string) (*linkpreview.Preview, error) {
+	u := linkpreview.FindURL(text)
+	if u == "" {
+		return nil, fmt.Errorf("no http(s) link in the message")
+	}
+	p, err := linkpreview.Fetch(ctx, a.links, u)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SendTextWithLink sends text with an already fetched link preview, which
+// may be nil.
+func (a *App) SendTextWithLink(ctx context.Context, to types.JID, text string, link *linkpreview.Preview, id types.MessageID) (types.MessageID, error) {
+	return a.sendText(ctx, to, text, id, link)
+}
+
+func (a *App) sendText(ctx context.Context, to types.JID, text string, id types.MessageID, link *linkpreview.Preview) (types.MessageID, error) {
+	if err := wa.CheckTextLength(text); err != nil {
+		return "", err
+	}
+	msg := &waProto.Message{Conversation: proto.String(text)}
+	rec := store.UpsertMessageParams{Text: text}
+	if link != nil {
+		msg = &waProto.Message{ExtendedTextMessage: &waProto.ExtendedTextMessage{
+			Text:          proto.String(text),
+			MatchedText:   proto.String(link.URL),
+			Title:         proto.String(link.Title),
+			Description:   proto.String(link.Description),
+			JPEGThumbnail: link.Thumbnail,
+			PreviewType:   waProto.ExtendedTextMessage_NONE.Enum(),
+		}}
+		rec.LinkURL = link.URL
+		rec.LinkTitle = link.Title
+		rec.LinkDescription = link.Description
+	}
+	sentID, err := a.sendProto(ctx, to, msg, id)
+	if err != nil {
+		return "", err
+	}
+	rec.MsgID = string(sentID)
+	a.recordSent(ctx, to, rec)
+	return sentID, nil
+}
+
+// SendFile uploads a file and sends it as an image, video, audio or document
+// message depending on its MIME type.
+func (a *App) SendFile(ctx context.Context, to types.JID, opts SendFileOptions) (SentFile, error) {
+	p, err := a.PrepareFile(ctx, opts)
+	if err != nil {
+		return SentFile{}, err
+	}
+	return a.SendPreparedFile(ctx, to, p, opts.ID)
+}
+
+// PreparedFile is an uploaded file ready to be sent to any number of chats.
+type PreparedFile struct {
+	msg  *waProto.Message
+	rec  store.UpsertMessageParams
+	sent SentFile
+}
+
+// PrepareFile uploads a file once and builds its message; see SendFile.
+// opts.ID is ignored.
+func (a *App) PrepareFile(ctx context.Context, opts SendFileOptions) (*PreparedFile, error) {
+	data, err := os.ReadFile(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(opts.Filename)
+	if name == "" {
+		name = filepath.Base(opts.Path)
+	}
+	mimeType, mediaType := fileMediaType(opts.Path, data, opts.MimeType)
+	uploadType, _ := wa.MediaTypeFromString(mediaType)
+
+	var up whatsmeow.UploadResponse
+	err = a.retryTransient(ctx, func() error {
+		var err error
+		up, err = a.wa.Upload(ctx, data, uploadType)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sent := SentFile{Name: name, MimeType: mimeType, MediaType: mediaType}
+	caption := opts.Caption
+	msg := &waProto.Message{}
+	switch mediaType {
+	case "image":
+		msg.ImageMessage = &waProto.ImageMessage{
+			URL:           proto.String(up.URL),
+			DirectPath:    proto.String(up.DirectPath),
+			MediaKey:      up.MediaKey,
+			FileEncSHA256: up.FileEncSHA256,
+			FileSHA256:    up.FileSHA256,
+			FileLength:    proto.Uint64(up.FileLength),
+			Mimetype:      proto.String(mimeType),
+			Caption:       proto.String(caption),
+		}
+		// Formats the standard library can't decode (e.g. WebP, HEIC) are
+		// still sent, just without a preview.
+		if info, err := mediainfo.Image(data); err == nil {
+			sent.Width, sent.Height = info.Width, info.Height
+			msg.ImageMessage.Width = proto.Uint32(uint32(info.Width))
+			msg.ImageMessage.Height = proto.Uint32(uint32(info.Height))
+			msg.ImageMessage.JPEGThumbnail = info.Thumbnail
+		}
+	case "video":
+		msg.VideoMessage = &waProto.VideoMessage{
+			URL:           proto.String(up.URL),
+			DirectPath:    proto.String(up.DirectPath),
+			MediaKey:      up.MediaKey,
+			FileEncSHA256: up.FileEncSHA256,
+			FileSHA256:    up.FileSHA256,
+			FileLength:    proto.Uint64(up.FileLength),
+			Mimetype:      proto.String(mimeType),
+			Caption:       proto.String(caption),
+		}
+		if info, err := mediainfo.MP4(bytes.NewReader(data)); err == nil {
+			sent.Width, sent.Height = info.Width, info.Height
+			sent.Seconds = uint32(info.Duration.Round(time.Second) / time.Second)
+			msg.VideoMessage.Width = proto.Uint32(uint32(info.Width))
+			msg.VideoMessage.Height = proto.Uint32(uint32(info.Height))
+			msg.VideoMessage.Seconds = proto.Uint32(sent.Seconds)
+		}
+	case "audio":
+		msg.AudioMessage = &waProto.AudioMessage{
+			URL:           proto.String(up.URL),
+			DirectPath:    proto.String(up.DirectPath),
+			MediaKey:      up.MediaKey,
+			FileEncSHA256: up.FileEncSHA256,
+			FileSHA256:    up.FileSHA256,
+			FileLength:    proto.Uint64(up.FileLength),
+			Mimetype:      proto.String(mimeType),
+			PTT:           proto.Bool(false),
+		}
+	default:
+		msg.DocumentMessage = &waProto.DocumentMessage{
+			URL:           proto.String(up.URL),
+			DirectPath:    proto.String(up.DirectPath),
+			MediaKey:      up.MediaKey,
+			FileEncSHA256: up.FileEncSHA256,
+			FileSHA256:    up.FileSHA256,
+			FileLength:    proto.Uint64(up.FileLength),
+			Mimetype:      proto.String(mimeType),
+			FileName:      proto.String(name),
+			Caption:       proto.String(caption),
+			Title:         proto.String(name),
+		}
+	}
+
+	return &PreparedFile{msg: msg, sent: sent, rec: store.UpsertMessageParams{
+		Text:            caption,
+		MediaType:       mediaType,
+		MediaCaption:    caption,
+		Filename:        name,
+		MimeType:        mimeType,
+		DirectPath:      up.DirectPath,
+		MediaKey:        up.MediaKey,
+		FileSHA256:      up.FileSHA256,
+		FileEncSHA256:   up.FileEncSHA256,
+		FileLength:      up.FileLength,
+		Width:           sent.Width,
+		Height:          sent.Height,
+		DurationSeconds: sent.Seconds,
+	}}, nil
+}
+
+// fileMediaType detects a file's MIME type, unless overridden, and the kind
+// of message it is sent as.
+func fileMediaType(path string, data []byte, override string) (mimeType, mediaType string) {
+	mimeType = strings.TrimSpace(override)
+	if mimeType == "" {
+		// Use the path for MIME detection, not the display name override.
+		mimeType = mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	}
+	if mimeType == "" {
+		sniff := data
+		if len(sniff) > 512 {
+			sniff = sniff[:512]
+		}
+		mimeType = http.DetectContentType(sniff)
+	}
+
+	mediaType = "document"
+	switch {
+	case mimeType == "image/webp":
+		// WhatsApp clients don't show WebP image messages; stickers go
+		// through SendSticker.
+	case strings.HasPrefix(mimeType, "image/"):
+		mediaType = "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		mediaType = "video"
+	case strings.HasPrefix(mimeType, "audio/"):
+		mediaType = "audio"
+	}
+	return mimeType, mediaType
+}
+
+// SendPreparedFile sends a prepared file to a chat without uploading it again.
+func (a *App) SendPreparedFile(ctx context.Context, to types.JID, p *PreparedFile, id types.MessageID) (SentFile, error) {
+	// whatsmeow may add fields to the message it sends, so each chat gets its
+	// own copy.
+	sentID, err := a.sendProto(ctx, to, proto.Clone(p.msg).(*waProto.Message), id)
+	if err != nil {
+		return SentFile{}, err
+	}
+	rec := p.rec
+	rec.MsgID = string(sentID)
+	a.recordSent(ctx, to, rec)
+	sent := p.sent
+	sent.ID = sentID
+	return sent, nil
+}
+
+// VoiceMimeType is the MIME type WhatsApp clients use for voice notes.
+const VoiceMimeType = "audio/ogg; codecs=opus"
+
+type SentVoice struct {
+	ID      types.MessageID `json:"id"`
+	Seconds uint32          `json:"seconds"`
+}
+
+// SendVoice sends an Ogg/Opus file as a push-to-talk voice note with its
+// duration and waveform. Other formats are rejected, since WhatsApp clients
+// only play Opus voice notes.
+func (a *App) SendVoice(ctx context.Context, to types.JID, path string, id types.MessageID) (SentVoice, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SentVoice{}, err
+	}
+	info, err := oggopus.Parse(bytes.NewReader(data))
+	if err != nil {
+		return SentVoice{}, fmt.Errorf("%s: %w (convert with: ffmpeg -i IN -c:a libopus -b:a 32k -ac 1 OUT.ogg)", path, err)
+	}
+	seconds := uint32((info.Duration + time.Second/2) / time.Second)
+
+	var up whatsmeow.UploadResponse
+	err = a.retryTransient(ctx, func() error {
+		var err error
+		up, err = a.wa.Upload(ctx, data, whatsmeow.MediaAudio)
+		return err
+	})
+	if err != nil {
+		return SentVoice{}, err
+	}
+
+	msg := &waProto.Message{AudioMessage: &waProto.AudioMessage{
+		URL:           proto.String(up.URL),
+		DirectPath:    proto.String(up.DirectPath),
+		MediaKey:      up.MediaKey,
+		FileEncSHA256: up.FileEncSHA256,
+		FileSHA256:    up.FileSHA256,
+		FileLength:    proto.Uint64(up.FileLength),
+		Mimetype:      proto.String(VoiceMimeType),
+		PTT:           proto.Bool(true),
+		Seconds:       proto.Uint32(seconds),
+		Waveform:      info.Waveform,
+	}}
+	sentID, err := a.sendProto(ctx, to, msg, id)
+	if err != nil {
+		return SentVoice{}, err
+	}
+
+	a.recordSent(ctx, to, store.UpsertMessageParams{
+		MsgID:           string(sentID),
+		MediaType:       "audio",
+		MimeType:        VoiceMimeType,
+		DirectPath:      up.DirectPath,
+		MediaKey:        up.MediaKey,
+		FileSHA256:      up.FileSHA256,
+		FileEncSHA256:   up.FileEncSHA256,
+		FileLength:      up.FileLength,
+		DurationSeconds: seconds,
+		PTT:             true,
+	})
+	return SentVoice{ID: sentID, Seconds: seconds}, nil
+}
+
+// StickerSize is the width and height WhatsApp requires for stickers.
+const StickerSize = 512
+
+type SentSticker struct {
+	ID       types.MessageID `json:"id"`
+	Animated bool            `json:"animated"`
+}
+
+// SendSticker sends a 512x512 WebP file as a sticker. The header is checked
+// first, so a wrong size or format fails before anything is uploaded.
+func (a *App) SendSticker(ctx context.Context, to types.JID, path string, id types.MessageID) (SentSticker, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SentSticker{}, err
+	}
+	info, err := mediainfo.WebP(data)
+	if err != nil {
+		return SentSticker{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if info.Width != StickerSize || info.Height != StickerSize {
+		return SentSticker{}, fmt.Errorf("%s: stickers must be %dx%d, got %dx%d", path, StickerSize, StickerSize, info.Width, info.Height)
+	}
+
+	var up whatsmeow.UploadResponse
+	err = a.retryTransient(ctx, func() error {
+		var err error
+		up, err = a.wa.Upload(ctx, data, whatsmeow.MediaImage)
+		return err
+	})
+	if err != nil {
+		return SentSticker{}, err
+	}
+
+	msg := &waProto.Message{StickerMessage: &waProto.StickerMessage{
+		URL:           proto.String(up.URL),
+		DirectPath:    proto.String(up.DirectPath),
+		MediaKey:      up.MediaKey,
+		FileEncSHA256: up.FileEncSHA256,
+		FileSHA256:    up.FileSHA256,
+		FileLength:    proto.Uint64(up.FileLength),
+		Mimetype:      proto.String("image/webp"),
+		Width:         proto.Uint32(StickerSize),
+		Height:        proto.Uint32(StickerSize),
+		IsAnimated:    proto.Bool(info.Animated),
+	}}
+	sentID, err := a.sendProto(ctx, to, msg, id)
+	if err != nil {
+		return SentSticker{}, err
+	}
+
+	a.recordSent(ctx, to, store.UpsertMessageParams{
+		MsgID:         string(sentID),
+		MediaType:     "sticker",
+		MimeType:      "image/webp",
+		DirectPath:    up.DirectPath,
+		MediaKey:      up.MediaKey,
+		FileSHA256:    up.FileSHA256,
+		FileEncSHA256: up.FileEncSHA256,
+		FileLength:    up.FileLength,
+		Width:         StickerSize,
+		Height:        StickerSize,
+	})
+	return SentSticker{ID: sentID, Animated: info.Animated}, nil
+}
+
+// sendProto sends msg, retrying transient failures. Without an explicit id a
+// message ID is generated up front so a retry can't deliver the message twice.
+// In a chat with disappearing messages on, msg expires with the chat's timer.
+func (a *App) sendProto(ctx context.Context, to types.JID, msg *waProto.Message, id types.MessageID) (types.MessageID, error) {
+	msg = withDisappearingTimer(msg, a.chatTimer(to))
+	var sentID types.MessageID
+	err := a.retryTransient(ctx, func() error {
+		if id == "" {
+			generated, err := a.wa.GenerateMessageID()
+			if err != nil {
+				return err
+			}
+			id = generated
+		}
+		var err error
+		sentID, err = a.wa.SendProtoMessageWithID(ctx, to, msg, id)
+		return err
+	})
+	return sentID, err
+}
+
+// retryTransient calls fn up to sendAttempts times while it fails with a
+// transient error, reconnecting in between when the connection was lost.
+func (a *App) retryTransient(ctx context.Context, fn func() error) error {
+	delay := sendRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= sendAttempts || !wa.IsTransient(err) || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if !a.wa.IsConnected() {
+			_ = a.Connect(ctx, false, nil)
+		}
+	}
+}
+
+// ReserveSendKey returns the message ID to use for a send guarded by an
+// idempotency key, reserving a new one on first use. Reusing a key for a
+// different chat fails with ErrIdempotencyConflict.
+func (a *App) ReserveSendKey(key string, to types.JID) (types.MessageID, error) {
+	if k, err := a.db.GetSendKey(key); err == nil {
+		if k.ChatJID != to.String() {
+			return "", ErrIdempotencyConflict
+		}
+		return types.MessageID(k.MsgID), nil
+	} else if !store.IsNotFound(err) {
+		return "", err
+	}
+	id, err := a.wa.GenerateMessageID()
+	if err != nil {
+		return "", err
+	}
+	k, err := a.db.ReserveSendKey(key, to.String(), string(id))
+	if err != nil {
+		return "", err
+	}
+	return types.MessageID(k.MsgID), nil
+}
+
+// recordSent stores an outgoing message (best-effort). Chat, sender and
+// timestamp fields of p are filled in here.
+func (a *App) recordSent(ctx context.Context, to types.JID, p store.UpsertMessageParams) {
+	now := time.Now().UTC()
+	chatName := a.wa.ResolveChatName(ctx, to, "")
+	_ = a.db.UpsertChat(to.String(), chatKind(to), chatName, now)
+	p.ChatJID = to.String()
+	p.ChatName = chatName
+	p.SenderName = "me"
+	p.Timestamp = now
+	p.FromMe = true
+	if p.EphemeralExpiration == 0 {
+		p.EphemeralExpiration = a.chatTimer(to)
+	}
+	_ = a.db.UpsertMessage(p)
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/vcard"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// String describes the location the way it is stored as message text.
+func (l Location) String() string {
+	coords := fmt.Sprintf("%.6f, %.6f", l.Latitude, l.Longitude)
+	label := strings.Join(nonEmpty(l.Name, l.Address), ", ")
+	if label == "" {
+		return coords
+	}
+	return label + " (" + coords + ")"
+}
+
+// SendLocation sends a location pin.
+func (a *App) SendLocation(ctx context.Context, to types.JID, loc Location, id types.MessageID) (types.MessageID, error) {
+	if math.IsNaN(loc.Latitude) || loc.Latitude < -90 || loc.Latitude > 90 {
+		return "", fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(loc.Longitude) || loc.Longitude < -180 || loc.Longitude > 180 {
+		return "", fmt.Errorf("longitude must be between -180 and 180")
+	}
+	lm := &waProto.LocationMessage{
+		DegreesLatitude:  proto.Float64(loc.Latitude),
+		DegreesLongitude: proto.Float64(loc.Longitude),
+	}
+	if loc.Name != "" {
+		lm.Name = proto.String(loc.Name)
+	}
+	if loc.Address != "" {
+		lm.Address = proto.String(loc.Address)
+	}
+	sentID, err := a.sendProto(ctx, to, &waProto.Message{LocationMessage: lm}, id)
+	if err != nil {
+		return "", err
+	}
+	a.recordSent(ctx, to, store.UpsertMessageParams{
+		MsgID:       string(sentID),
+		Text:        loc.String(),
+		DisplayText: "Sent location: " + loc.String(),
+		MediaType:   "location",
+	})
+	return sentID, nil
+}
+
+// SendContact sends a single vCard as a contact card. displayName defaults to
+// the card's full name.
+func (a *App) SendContact(ctx context.Context, to types.JID, card string, displayName string, id types.MessageID) (types.MessageID, error) {
+	cards, err := vcard.Parse(strings.NewReader(card))
+	if err != nil {
+		return "", err
+	}
+	if len(cards) != 1 {
+		return "", fmt.Errorf("expected exactly one vCard, got %d", len(cards))
+	}
+	if strings.TrimSpace(displayName) == "" {
+		displayName = cards[0].FullName
+	}
+	msg := &waProto.Message{ContactMessage: &waProto.ContactMessage{
+		DisplayName: proto.String(displayName),
+		Vcard:       proto.String(card),
+	}}
+	sentID, err := a.sendProto(ctx, to, msg, id)
+	if err != nil {
+		return "", err
+	}
+	a.recordSent(ctx, to, store.UpsertMessageParams{
+		MsgID:       string(sentID),
+		Text:        displayName,
+		DisplayText: "Sent contact: " + displayName,
+		MediaType:   "contact",
+	})
+	return sentID, nil
+}
+
+// ContactVCard builds a vCard for a known contact from the local contacts and
+// alias data. Notes and tags are private and left out.
+func (a *App) ContactVCard(jid string) (string, error) {
+	c, err := a.db.GetContact(a.db.ResolveJID(jid))
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("contact %s not found (run sync, or use --vcard)", jid)
+	}
+	if err != nil {
+		return "", err
+	}
+	card := ContactCard(c)
+	if card.Nickname != "" {
+		// The recipient sees the contact under the name we know them by.
+		card.FullName, card.Nickname = card.Nickname, ""
+	}
+	card.Note = ""
+	card.Categories = nil
+	if len(card.Phones) == 0 {
+		return "", fmt.Errorf("contact %s has no phone number to share", jid)
+	}
+	return card.String(), nil
+}
+
+func nonEmpty(ss ...string) []string {
+	var out []string
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestSendLocation(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+
+	if _, err := a.SendLocation(context.Background(), to, Location{Latitude: 91}, ""); err == nil {
+		t.Fatalf("expected out-of-range latitude to be rejected")
+	}
+	if len(f.sentMessages) != 0 {
+		t.Fatalf("nothing should be sent for invalid input")
+	}
+
+	loc := Location{Latitude: 52.5163, Longitude: 13.3777, Name: "Brandenburg Gate", Address: "Pariser Platz, Berlin"}
+	if _, err := a.SendLocation(context.Background(), to, loc, ""); err != nil {
+		t.Fatalf("SendLocation: %v", err)
+	}
+	lm := f.sentMessages[0].msg.GetLocationMessage()
+	if lm.GetDegreesLatitude() != 52.5163 || lm.GetDegreesLongitude() != 13.3777 || lm.GetName() != "Brandenburg Gate" || lm.GetAddress() != "Pariser Platz, Berlin" {
+		t.Fatalf("unexpected location message: %+v", lm)
+	}
+	msgs, err := a.db.ListMessages(store.ListMessagesParams{ChatJID: to.String(), Type: "location"})
+	if err != nil || len(msgs) != 1 || !strings.Contains(msgs[0].Text, "Brandenburg Gate") {
+		t.Fatalf("expected stored location, got %+v err=%v", msgs, err)
+	}
+}
+
+func TestSendContactFromLocalContact(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+	jid := "15550102030@s.whatsapp.net"
+
+	if _, err := a.ContactVCard(jid); err == nil {
+		t.Fatalf("expected unknown contact to be rejected")
+	}
+	if err := a.db.UpsertContact(jid, "15550102030", "Jane", "Jane Doe", "Jane", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	_ = a.db.SetAlias(jid, "Jane (work)")
+	_ = a.db.SetNotes(jid, "owes me lunch")
+	_ = a.db.AddTag(jid, "vip")
+
+	card, err := a.ContactVCard(jid)
+	if err != nil {
+		t.Fatalf("ContactVCard: %v", err)
+	}
+	if !strings.Contains(card, "FN:Jane (work)") || !strings.Contains(card, "waid=15550102030") {
+		t.Fatalf("unexpected vCard:\n%s", card)
+	}
+	if strings.Contains(card, "lunch") || strings.Contains(card, "vip") {
+		t.Fatalf("private notes/tags leaked into vCard:\n%s", card)
+	}
+
+	if _, err := a.SendContact(context.Background(), to, card, "", ""); err != nil {
+		t.Fatalf("SendContact: %v", err)
+	}
+	cm := f.sentMessages[0].msg.GetContactMessage()
+	if cm.GetDisplayName() != "Jane (work)" || cm.GetVcard() != card {
+		t.Fatalf("unexpected contact message: %+v", cm)
+	}
+
+	if _, err := a.SendContact(context.Background(), to, card+card, "", ""); err == nil {
+		t.Fatalf("expected a file with two vCards to be rejected")
+	}
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steipete/wacli/internal/oggopus"
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestSendTextRetriesTransientErrorsWithSameID(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	sendRetryDelay = time.Millisecond
+	t.Cleanup(func() { sendRetryDelay = 2 * time.Second })
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+
+	f.sendErrs = []error{wa.ErrNotConnected}
+	id, err := a.SendText(context.Background(), to, "hi", "")
+	if err != nil {
+		t.Fatalf("SendText: %v", err)
+	}
+	if len(f.sentMessages) != 1 || f.sentMessages[0].id != id {
+		t.Fatalf("expected one delivery with id %s, got %+v", id, f.sentMessages)
+	}
+	if f.nextMsgID != 1 {
+		t.Fatalf("expected the retry to reuse the generated ID, generated %d", f.nextMsgID)
+	}
+
+	f.sendErrs = []error{errors.New("rejected")}
+	if _, err := a.SendText(context.Background(), to, "hi", ""); err == nil || len(f.sentMessages) != 1 {
+		t.Fatalf("expected permanent error without retry, got err=%v sent=%d", err, len(f.sentMessages))
+	}
+}
+
+func TestReserveSendKey(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+	other := types.JID{User: "222", Server: types.DefaultUserServer}
+
+	id, err := a.ReserveSendKey("k1", to)
+	if err != nil || id == "" {
+		t.Fatalf("ReserveSendKey: id=%q err=%v", id, err)
+	}
+	again, err := a.ReserveSendKey("k1", to)
+	if err != nil || again != id {
+		t.Fatalf("expected the reserved ID %s again, got %s (err=%v)", id, again, err)
+	}
+	if _, err := a.ReserveSendKey("k1", other); !errors.Is(err, ErrIdempotencyConflict) {
+		t.Fatalf("expected conflict for a different recipient, got %v", err)
+	}
+}
+
+func TestSendVoiceSendsPTTWithDuration(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+	dir := t.TempDir()
+
+	mp3 := filepath.Join(dir, "a.mp3")
+	_ = os.WriteFile(mp3, []byte("ID3\x04\x00\x00 not opus"), 0o600)
+	if _, err := a.SendVoice(context.Background(), to, mp3, ""); !errors.Is(err, oggopus.ErrNotOgg) {
+		t.Fatalf("expected non-Ogg input to be rejected, got %v", err)
+	}
+
+	// One-page Ogg/Opus stream: head, tags and 150 x 20 ms packets (3 s).
+	head := []byte("OpusHead\x01\x01\x00\x00\x80\xbb\x00\x00\x00\x00\x00")
+	packets := [][]byte{head, []byte("OpusTags")}
+	for i := 0; i < 150; i++ {
+		packets = append(packets, []byte{31 << 3, byte(i)})
+	}
+	var segs, body []byte
+	for _, p := range packets {
+		segs = append(segs, byte(len(p)))
+		body = append(body, p...)
+	}
+	page := []byte("OggS\x00\x00")
+	page = binary.LittleEndian.AppendUint64(page, 150*960)
+	page = append(page, make([]byte, 12)...)
+	page = append(page, byte(len(segs)))
+	page = append(append(page, segs...), body...)
+	ogg := filepath.Join(dir, "v.ogg")
+	if err := os.WriteFile(ogg, page, 0o600); err != nil {
+		t.Fatalf("write ogg: %v", err)
+	}
+
+	sent, err := a.SendVoice(context.Background(), to, ogg, "")
+	if err != nil {
+		t.Fatalf("SendVoice: %v", err)
+	}
+	if sent.Seconds != 3 || len(f.sentMessages) != 1 {
+		t.Fatalf("unexpected send: %+v (%d messages)", sent, len(f.sentMessages))
+	}
+	aud := f.sentMessages[0].msg.GetAudioMessage()
+	if aud == nil || !aud.GetPTT() || aud.GetSeconds() != 3 || len(aud.GetWaveform()) != oggopus.WaveformSamples || aud.GetMimetype() != VoiceMimeType {
+		t.Fatalf("unexpected audio message: %+v", aud)
+	}
+	voice, err := a.db.ListMessages(store.ListMessagesParams{ChatJID: to.String(), Type: "voice"})
+	if err != nil || len(voice) != 1 {
+		t.Fatalf("expected the sent voice note to be stored, got %+v err=%v", voice, err)
+	}
+}
+
+func TestSendFileImageHasThumbnailAndSize(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 300, 150))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "pic.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write png: %v", err)
+	}
+
+	sent, err := a.SendFile(context.Background(), to, SendFileOptions{Path: path})
+	if err != nil {
+		t.Fatalf("SendFile: %v", err)
+	}
+	if sent.MediaType != "image" || sent.Width != 300 || sent.Height != 150 {
+		t.Fatalf("unexpected send: %+v", sent)
+	}
+	img := f.sentMessages[0].msg.GetImageMessage()
+	if img.GetWidth() != 300 || img.GetHeight() != 150 || len(img.GetJPEGThumbnail()) == 0 {
+		t.Fatalf("unexpected image message: width=%d height=%d thumb=%d bytes", img.GetWidth(), img.GetHeight(), len(img.GetJPEGThumbnail()))
+	}
+	m, err := a.db.GetMessage(to.String(), string(sent.ID))
+	if err != nil || m.Width != 300 || m.Height != 150 {
+		t.Fatalf("expected stored dimensions, got %+v err=%v", m, err)
+	}
+}
+
+// webpVP8X returns an extended-format WebP header with the given canvas size.
+func webpVP8X(w, h int, animated bool) []byte {
+	flags := byte(0)
+	if animated {
+		flags = 0x02
+	}
+	body := []byte{flags, 0, 0, 0,
+		byte(w - 1), byte((w - 1) >> 8), byte((w - 1) >> 16),
+		byte(h - 1), byte((h - 1) >> 8), byte((h - 1) >> 16)}
+	b := []byte("RIFF")
+	b = binary.LittleEndian.AppendUint32(b, uint32(4+8+len(body)))
+	b = append(b, "WEBPVP8X"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(body)))
+	return append(b, body...)
+}
+
+func TestSendStickerValidatesWebP(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+	dir := t.TempDir()
+
+	small := filepath.Join(dir, "small.webp")
+	_ = os.WriteFile(small, webpVP8X(256, 256, false), 0o600)
+	if _, err := a.SendSticker(context.Background(), to, small, ""); err == nil || f.uploads != 0 {
+		t.Fatalf("expected a 256x256 sticker to be rejected before upload, err=%v uploads=%d", err, f.uploads)
+	}
+
+	anim := filepath.Join(dir, "anim.webp")
+	_ = os.WriteFile(anim, webpVP8X(512, 512, true), 0o600)
+	sent, err := a.SendSticker(context.Background(), to, anim, "")
+	if err != nil {
+		t.Fatalf("SendSticker: %v", err)
+	}
+	st := f.sentMessages[0].msg.GetStickerMessage()
+	if !sent.Animated || st == nil || !st.GetIsAnimated() || st.GetMimetype() != "image/webp" {
+		t.Fatalf("unexpected sticker message: %+v", st)
+	}
+
+	// send file must not turn a WebP into an image message.
+	if _, err := a.SendFile(context.Background(), to, SendFileOptions{Path: anim}); err != nil {
+		t.Fatalf("SendFile: %v", err)
+	}
+	if f.sentMessages[1].msg.GetImageMessage() != nil || f.sentMessages[1].msg.GetDocumentMessage() == nil {
+		t.Fatalf("expected WebP to be sent as a document, got %+v", f.sentMessages[1].msg)
+	}
+}
+
+func TestSendTextWithPreviewUsesOpenGraph(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+	to := types.JID{User: "111", Server: types.DefaultUserServer}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><meta property="og:title" content="Release notes"><meta property="og:description" content="What changed"></head></html>`))
+	}))
+	defer srv.Close()
+	a.links = srv.Client()
+
+	text := "notes: " + srv.URL + "/notes."
+	sent, err := a.SendTextWithPreview(context.Background(), to, text, "")
+	if err != nil || sent.PreviewErr != nil {
+		t.Fatalf("SendTextWithPreview: err=%v previewErr=%v", err, sent.PreviewErr)
+	}
+	ext := f.sentMessages[0].msg.GetExtendedTextMessage()
+	if ext.GetText() != text || ext.GetMatchedText() != srv.URL+"/notes" || ext.GetTitle() != "Release notes" || ext.GetDescription() != "What changed" {
+		t.Fatalf("unexpected extended text: %+v", ext)
+	}
+	m, err := a.db.GetMessage(to.String(), string(sent.ID))
+	if err != nil || m.LinkTitle != "Release notes" {
+		t.Fatalf("expected stored link title, got %+v err=%v", m, err)
+	}
+
+	// Without a reachable page the text still goes out, as a plain message.
+	srv.Close()
+	sent, err = a.SendTextWithPreview(context.Background(), to, text, "")
+	if err != nil || sent.PreviewErr == nil || sent.Preview != nil {
+		t.Fatalf("expected a plain send with PreviewErr, got %+v err=%v", sent, err)
+	}
+	if f.sentMessages[1].msg.GetConversation() != text {
+		t.Fatalf("expected plain conversation message, got %+v", f.sentMessages[1].msg)
+	}
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/steipete/wacli/internal/store"
+	"github.com/steipete/wacli/internal/wa"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// Status audiences, named after the options in the phone's status privacy
+// settings.
+const (
+	StatusAudienceContacts = "contacts" // all contacts
+	StatusAudienceExcept   = "except"   // contacts except the listed ones
+	StatusAudienceOnly     = "only"     // only the listed contacts
+)
+
+var statusAudienceNames = map[types.StatusPrivacyType]string{
+	types.StatusPrivacyTypeContacts:  StatusAudienceContacts,
+	types.StatusPrivacyTypeBlacklist: StatusAudienceExcept,
+	types.StatusPrivacyTypeWhitelist: StatusAudienceOnly,
+}
+
+// DefaultStatusBackground is the background of text status updates (ARGB).
+const DefaultStatusBackground uint32 = 0xFF075E54
+
+// StatusAudience is who status updates are shown to.
+type StatusAudience struct {
+	Type string   `json:"type"`
+	JIDs []string `json:"jids,omitempty"`
+}
+
+// StatusAudience returns the audience of status updates. It is the phone's
+// default status privacy setting: WhatsApp doesn't let linked devices choose
+// an audience per post.
+func (a *App) StatusAudience(ctx context.Context) (StatusAudience, error) {
+	lists, err := a.wa.GetStatusPrivacy(ctx)
+	if err != nil {
+		return StatusAudience{}, err
+	}
+	if len(lists) == 0 {
+		return StatusAudience{Type: StatusAudienceContacts}, nil
+	}
+	name, ok := statusAudienceNames[lists[0].Type]
+	if !ok {
+		name = string(lists[0].Type)
+	}
+	out := StatusAudience{Type: name}
+	for _, jid := range lists[0].List {
+		out.JIDs = append(out.JIDs, jid.String())
+	}
+	return out, nil
+}
+
+type StatusPostOptions struct {
+	// Text posts a text status; File posts an image or video with Caption.
+	Text       string
+	Background uint32 // ARGB; defaults to DefaultStatusBackground
+	File       string
+	Caption    string
+	MimeType   string // overrides detection
+	// Audience, when set, must match the phone's status privacy setting;
+	// the post is refused otherwise.
+	Audience string
+	ID       types.MessageID
+}
+
+type PostedStatus struct {
+	ID       types.MessageID `json:"id"`
+	Audience StatusAudience  `json:"audience"`
+	File     *SentFile       `json:"file,omitempty"`
+}
+
+// PostStatus posts a status update (story) to the audience set on the phone.
+func (a *App) PostStatus(ctx context.Context, opts StatusPostOptions) (PostedStatus, error) {
+	if (strings.TrimSpace(opts.Text) == "") == (opts.File == "") {
+		return PostedStatus{}, fmt.Errorf("a status needs either text or a file")
+	}
+	if opts.Audience != "" {
+		switch opts.Audience {
+		case StatusAudienceContacts, StatusAudienceExcept, StatusAudienceOnly:
+		default:
+			return PostedStatus{}, fmt.Errorf("unknown audience %q (contacts|except|only)", opts.Audience)
+		}
+	}
+
+	var media string
+	if opts.File != "" {
+		data, err := os.ReadFile(opts.File)
+		if err != nil {
+			return PostedStatus{}, err
+		}
+		if _, media = fileMediaType(opts.File, data, opts.MimeType); media != "image" && media != "video" {
+			return PostedStatus{}, fmt.Errorf("status updates can only be text, images or videos, not %s", media)
+		}
+	} else if err := wa.CheckTextLength(opts.Text); err != nil {
+		return PostedStatus{}, err
+	}
+
+	audience, err := a.StatusAudience(ctx)
+	if err != nil {
+		return PostedStatus{}, fmt.Errorf("get status privacy: %w", err)
+	}
+	if opts.Audience != "" && opts.Audience != audience.Type {
+		return PostedStatus{}, fmt.Errorf("status audience is %q on the phone, not %q; change it in WhatsApp's status privacy settings", audience.Type, opts.Audience)
+	}
+	out := PostedStatus{Audience: audience}
+
+	if opts.File != "" {
+		p, err := a.PrepareFile(ctx, SendFileOptions{Path: opts.File, Caption: opts.Caption, MimeType: opts.MimeType})
+		if err != nil {
+			return PostedStatus{}, err
+		}
+		sent, err := a.SendPreparedFile(ctx, types.StatusBroadcastJID, p, opts.ID)
+		if err != nil {
+			return PostedStatus{}, err
+		}
+		out.ID = sent.ID
+		out.File = &sent
+		return out, nil
+	}
+
+	bg := opts.Background
+	if bg == 0 {
+		bg = DefaultStatusBackground
+	}
+	msg := &waProto.Message{ExtendedTextMessage: &waProto.ExtendedTextMessage{
+		Text:           proto.String(opts.Text),
+		TextArgb:       proto.Uint32(0xFFFFFFFF),
+		BackgroundArgb: proto.Uint32(bg),
+		Font:           waProto.ExtendedTextMessage_SYSTEM.Enum(),
+	}}
+	sentID, err := a.sendProto(ctx, types.StatusBroadcastJID, msg, opts.ID)
+	if err != nil {
+		return PostedStatus{}, err
+	}
+	a.recordSent(ctx, types.StatusBroadcastJID, store.UpsertMessageParams{
+		MsgID: string(sentID),
+		Text:  opts.Text,
+	})
+	out.ID = sentID
+	return out, nil
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steipete/wacli/internal/store"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestPostStatus(t *testing.T) {
+	a := newTestApp(t)
+	f := newFakeWA()
+	a.wa = f
+	f.connected = true
+
+	posted, err := a.PostStatus(context.Background(), StatusPostOptions{Text: "Open until 8pm today", Background: 0xFF112233})
+	if err != nil {
+		t.Fatalf("PostStatus: %v", err)
+	}
+	if posted.Audience.Type != StatusAudienceContacts {
+		t.Fatalf("expected default contacts audience, got %+v", posted.Audience)
+	}
+	sent := f.sentMessages[0]
+	ext := sent.msg.GetExtendedTextMessage()
+	if sent.to != types.StatusBroadcastJID || ext.GetText() != "Open until 8pm today" || ext.GetBackgroundArgb() != 0xFF112233 {
+		t.Fatalf("unexpected status message to %s: %+v", sent.to, ext)
+	}
+	chat, err := a.db.GetChat(store.StatusJID)
+	if err != nil || chat.Kind != "status" {
+		t.Fatalf("expected status chat kind, got %+v err=%v", chat, err)
+	}
+
+	f.statusPriv = []types.StatusPrivacy{{Type: types.StatusPrivacyTypeWhitelist, IsDefault: true, List: []types.JID{{User: "111", Server: types.DefaultUserServer}}}}
+	if _, err := a.PostStatus(context.Background(), StatusPostOptions{Text: "hi", Audience: StatusAudienceContacts}); err == nil {
+		t.Fatalf("expected an audience mismatch to be rejected")
+	}
+	if _, err := a.PostStatus(context.Background(), StatusPostOptions{Text: "hi", Audience: StatusAudienceOnly}); err != nil {
+		t.Fatalf("PostStatus with matching audience: %v", err)
+	}
+
+	pdf := filepath.Join(t.TempDir(), "menu.pdf")
+	if err := os.WriteFile(pdf, []byte("%PDF-1.4"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := a.PostStatus(context.Background(), StatusPostOptions{File: pdf}); err == nil {
+		t.Fatalf("expected a document status to be rejected")
+	}
+	if f.uploads != 0 || len(f.sentMessages) != 2 {
+		t.Fatalf("rejected posts must not upload or send, got uploads=%d sent=%d", f.uploads, len(f.sentMessages))
+	}
+}
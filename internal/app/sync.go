@@ -28,6 +28,9 @@ type SyncOptions struct {
 	OnQRCode        func(string)
 	AfterConnect    func(context.Context) error
 	DownloadMedia   bool
+	SkipViewOnce    bool   // don't download view-once media
+	RejectCalls     bool   // follow mode: decline incoming calls
+	RejectMessage   string // text sent to callers whose call was declined
 	RefreshContacts bool
 	RefreshGroups   bool
 	IdleExit        time.Duration // only used for bootstrap/once
@@ -79,6 +82,11 @@ func (a *App) Sync(ctx context.Context, opts SyncOptions) (SyncResult, error) {
 		}
 	}
 
+	rej := callRejecter{enabled: opts.RejectCalls && opts.Mode == SyncModeFollow, message: opts.RejectMessage}
+
+	// Deferred before the handler is removed, so it runs after: once no new
+	// events arrive, wait for the work they started.
+	defer a.bg.Wait()
 	handlerID := a.wa.AddEventHandler(func(evt interface{}) {
 		lastEvent.Store(time.Now().UTC().UnixNano())
 
@@ -98,7 +106,11 @@ func (a *App) Sync(ctx context.Context, opts SyncOptions) (SyncResult, error) {
 			if err := a.storeParsedMessage(ctx, pm); err == nil {
 				messagesStored.Add(1)
 			}
-			if opts.DownloadMedia && pm.Media != nil && pm.ID != "" {
+			if pm.Expiration > 0 {
+				// A new message carries the chat's current timer.
+				_ = a.db.SetChatEphemeral(a.db.ResolveJID(pm.Chat.String()), pm.Expiration)
+			}
+			if opts.DownloadMedia && pm.Media != nil && pm.ID != "" && pm.EditOfID == "" && !(opts.SkipViewOnce && pm.ViewOnce) {
 				enqueueMedia(pm.Chat.String(), pm.ID)
 			}
 			if messagesStored.Load()%25 == 0 {
@@ -112,11 +124,15 @@ func (a *App) Sync(ctx context.Context, opts SyncOptions) (SyncResult, error) {
 				if chatID == "" {
 					continue
 				}
+				if list, err := types.ParseJID(chatID); err == nil {
+					a.recordBroadcastMembers(ctx, list, conv.GetParticipant())
+				}
 				for _, m := range conv.Messages {
 					lastEvent.Store(time.Now().UTC().UnixNano())
 					if m.Message == nil {
 						continue
 					}
+					a.recordHistoryCall(ctx, chatID, m.Message)
 					pm := wa.ParseHistoryMessage(chatID, m.Message)
 					if pm.ID == "" || pm.Chat.IsEmpty() {
 						continue
@@ -124,12 +140,17 @@ func (a *App) Sync(ctx context.Context, opts SyncOptions) (SyncResult, error) {
 					if err := a.storeParsedMessage(ctx, pm); err == nil {
 						messagesStored.Add(1)
 					}
-					if opts.DownloadMedia && pm.Media != nil && pm.ID != "" {
+					if opts.DownloadMedia && pm.Media != nil && pm.ID != "" && pm.EditOfID == "" && !(opts.SkipViewOnce && pm.ViewOnce) {
 						enqueueMedia(pm.Chat.String(), pm.ID)
 					}
 				}
+				_ = a.db.SetChatEphemeral(a.db.ResolveJID(chatID), conv.GetEphemeralExpiration())
 			}
 			fmt.Fprintf(os.Stderr, "\rSynced %d messages...", messagesStored.Load())
+		case *events.Blocklist:
+			a.applyBlocklistEvent(ctx, v)
+		case *events.CallOffer, *events.CallOfferNotice, *events.CallAccept, *events.CallReject, *events.CallTerminate:
+			a.handleCallEvent(ctx, v, rej)
 		case *events.Connected:
 			fmt.Fprintln(os.Stderr, "\nConnected.")
 		case *events.Disconnected:
@@ -155,6 +176,11 @@ func (a *App) Sync(ctx context.Context, opts SyncOptions) (SyncResult, error) {
 		defer stopMedia()
 	}
 
+	// Rewrite rows stored under LIDs whose phone number is now known.
+	if n, err := a.ResolveStoredLIDs(ctx); err == nil && n > 0 {
+		fmt.Fprintf(os.Stderr, "Merged %d LID identities into phone-number JIDs.\n", n)
+	}
+
 	// Optional: bootstrap imports (helps contacts/groups management without waiting for events).
 	if opts.RefreshContacts {
 		_ = a.refreshContacts(ctx)
@@ -169,6 +195,11 @@ func (a *App) Sync(ctx context.Context, opts SyncOptions) (SyncResult, error) {
 	}
 
 	if opts.Mode == SyncModeFollow {
+		// Follow mode also delivers scheduled messages as they come due, on
+		// its own goroutine so a slow send doesn't hold up reconnects.
+		outboxCtx, stopOutbox := context.WithCancel(ctx)
+		defer stopOutbox()
+		a.goBackground(func() { a.deliverOutboxEvery(outboxCtx, outboxPollInterval) })
 		for {
 			select {
 			case <-ctx.Done():
@@ -214,21 +245,44 @@ func chatKind(chat types.JID) string {
 	if chat.Server == types.GroupServer {
 		return "group"
 	}
+	if chat == types.StatusBroadcastJID {
+		return "status"
+	}
 	if chat.IsBroadcastList() {
 		return "broadcast"
 	}
-	if chat.Server == types.DefaultUserServer {
+	if chat.Server == types.DefaultUserServer || chat.Server == types.HiddenUserServer {
 		return "dm"
 	}
 	return "unknown"
 }
 
 func (a *App) storeParsedMessage(ctx context.Context, pm wa.ParsedMessage) error {
+	pm.Chat = a.resolveLID(ctx, pm.Chat, pm.ChatAlt)
+	if pm.SenderJID != "" {
+		if jid, err := types.ParseJID(pm.SenderJID); err == nil && jid.Server == types.HiddenUserServer {
+			if pn := a.resolveLID(ctx, jid, pm.SenderAlt); pn != jid {
+				pm.SenderJID = pn.String()
+			}
+		}
+	}
+
 	chatJID := pm.Chat.String()
+	if pm.EditOfID != "" {
+		found, err := a.db.EditMessage(chatJID, pm.EditOfID, pm.SenderJID, pm.FromMe, pm.Text, pm.Timestamp)
+		if err != nil || found {
+			return err
+		}
+		// The original isn't stored: keep the edited version in its place.
+		pm.ID = pm.EditOfID
+	}
 	chatName := a.wa.ResolveChatName(ctx, pm.Chat, pm.PushName)
 	if err := a.db.UpsertChat(chatJID, chatKind(pm.Chat), chatName, pm.Timestamp); err != nil {
 		return err
 	}
+	if pm.EphemeralSetting != nil {
+		_ = a.db.SetChatEphemeral(chatJID, *pm.EphemeralSetting)
+	}
 
 	// Best-effort: store contact info for DMs.
 	if pm.Chat.Server == types.DefaultUserServer {
@@ -256,9 +310,13 @@ func (a *App) storeParsedMessage(ctx context.Context, pm wa.ParsedMessage) error
 				if name := wa.BestContactName(info); name != "" {
 					senderName = name
 				}
+				phone := ""
+				if jid.Server == types.DefaultUserServer {
+					phone = jid.User
+				}
 				_ = a.db.UpsertContact(
 					jid.String(),
-					jid.User,
+					phone,
 					info.PushName,
 					info.FullName,
 					info.FirstName,
@@ -272,6 +330,12 @@ func (a *App) storeParsedMessage(ctx context.Context, pm wa.ParsedMessage) error
 	if pm.Chat.Server == types.GroupServer {
 		if gi, err := a.wa.GetGroupInfo(ctx, pm.Chat); err == nil && gi != nil {
 			_ = a.db.UpsertGroup(gi.JID.String(), gi.GroupName.Name, gi.OwnerJID.String(), gi.GroupCreated)
+			_ = a.db.SetGroupCommunity(gi.JID.String(), gi.IsParent, gi.LinkedParentJID.String())
+			var timer uint32
+			if gi.IsEphemeral {
+				timer = gi.DisappearingTimer
+			}
+			_ = a.db.SetChatEphemeral(gi.JID.String(), timer)
 			var ps []store.GroupParticipant
 			for _, p := range gi.Participants {
 				role := "member"
@@ -282,7 +346,7 @@ func (a *App) storeParsedMessage(ctx context.Context, pm wa.ParsedMessage) error
 				}
 				ps = append(ps, store.GroupParticipant{
 					GroupJID: pm.Chat.String(),
-					UserJID:  p.JID.String(),
+					UserJID:  a.ParticipantJID(ctx, p).String(),
 					Role:     role,
 				})
 			}
@@ -293,6 +357,8 @@ func (a *App) storeParsedMessage(ctx context.Context, pm wa.ParsedMessage) error
 	var mediaType, caption, filename, mimeType, directPath string
 	var mediaKey, fileSha, fileEncSha []byte
 	var fileLen uint64
+	var seconds, width, height uint32
+	var ptt bool
 	if pm.Media != nil {
 		mediaType = pm.Media.Type
 		caption = pm.Media.Caption
@@ -303,29 +369,50 @@ func (a *App) storeParsedMessage(ctx context.Context, pm wa.ParsedMessage) error
 		fileSha = pm.Media.FileSHA256
 		fileEncSha = pm.Media.FileEncSHA256
 		fileLen = pm.Media.FileLength
+		seconds = pm.Media.Seconds
+		ptt = pm.Media.PTT
+		width = pm.Media.Width
+		height = pm.Media.Height
+	}
+
+	var link wa.LinkPreview
+	if pm.Link != nil {
+		link = *pm.Link
 	}
 
 	displayText := a.buildDisplayText(ctx, pm)
 
 	return a.db.UpsertMessage(store.UpsertMessageParams{
-		ChatJID:       chatJID,
-		ChatName:      chatName,
-		MsgID:         pm.ID,
-		SenderJID:     pm.SenderJID,
-		SenderName:    senderName,
-		Timestamp:     pm.Timestamp,
-		FromMe:        pm.FromMe,
-		Text:          pm.Text,
-		DisplayText:   displayText,
-		MediaType:     mediaType,
-		MediaCaption:  caption,
-		Filename:      filename,
-		MimeType:      mimeType,
-		DirectPath:    directPath,
-		MediaKey:      mediaKey,
-		FileSHA256:    fileSha,
-		FileEncSHA256: fileEncSha,
-		FileLength:    fileLen,
+		ChatJID:         chatJID,
+		ChatName:        chatName,
+		MsgID:           pm.ID,
+		SenderJID:       pm.SenderJID,
+		SenderName:      senderName,
+		Timestamp:       pm.Timestamp,
+		FromMe:          pm.FromMe,
+		Text:            pm.Text,
+		DisplayText:     displayText,
+		MediaType:       mediaType,
+		MediaCaption:    caption,
+		Filename:        filename,
+		MimeType:        mimeType,
+		DirectPath:      directPath,
+		MediaKey:        mediaKey,
+		FileSHA256:      fileSha,
+		FileEncSHA256:   fileEncSha,
+		FileLength:      fileLen,
+		DurationSeconds: seconds,
+		PTT:             ptt,
+		Width:           int(width),
+		Height:          int(height),
+		LinkURL:         link.URL,
+		LinkTitle:       link.Title,
+		LinkDescription: link.Description,
+		Forwarded:       pm.Forwarded,
+		ForwardingScore: pm.ForwardingScore,
+
+		EphemeralExpiration: pm.Expiration,
+		ViewOnce:            pm.ViewOnce,
 	})
 }
 
@@ -369,7 +456,19 @@ func (a *App) buildDisplayText(ctx context.Context, pm wa.ParsedMessage) string
 }
 
 func baseDisplayText(pm wa.ParsedMessage) string {
+	if pm.EphemeralSetting != nil {
+		if *pm.EphemeralSetting == 0 {
+			return "Turned off disappearing messages"
+		}
+		return "Turned on disappearing messages (" + FormatTimer(*pm.EphemeralSetting) + ")"
+	}
 	if pm.Media != nil {
+		if pm.Media.Type == "audio" && pm.Media.PTT {
+			return "Sent voice note"
+		}
+		if pm.ViewOnce {
+			return "Sent view-once " + mediaLabel(pm.Media.Type)
+		}
 		return "Sent " + mediaLabel(pm.Media.Type)
 	}
 	if text := strings.TrimSpace(pm.Text); text != "" {
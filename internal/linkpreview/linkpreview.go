@@ -0,0 +1,178 @@
+// Package linkpreview builds WhatsApp link previews from a page's OpenGraph
+// tags, falling back to <title> and the description meta tag.
+package linkpreview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/steipete/wacli/internal/mediainfo"
+	"golang.org/x/net/html"
+)
+
+// Fetcher performs HTTP requests; *http.Client satisfies it.
+type Fetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+const (
+	maxPageBytes  = 1 << 20
+	maxImageBytes = 5 << 20
+	userAgent     = "Mozilla/5.0 (compatible; wacli link preview)"
+)
+
+var ErrNoPreview = errors.New("page has no title or description")
+
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	Thumbnail   []byte `json:"-"` // JPEG
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// FindURL returns the first http(s) URL in text, without trailing
+// punctuation, or "" if there is none.
+func FindURL(text string) string {
+	u := urlPattern.FindString(text)
+	return strings.TrimRight(u, ".,;:!?)]}'")
+}
+
+// Fetch downloads pageURL and reads its preview tags. The preview image is
+// optional: if it can't be fetched or decoded, the preview has no thumbnail.
+func Fetch(ctx context.Context, f Fetcher, pageURL string) (Preview, error) {
+	body, contentType, err := get(ctx, f, pageURL, maxPageBytes)
+	if err != nil {
+		return Preview{}, err
+	}
+	if mt, _, _ := mime.ParseMediaType(contentType); mt != "" && mt != "text/html" && mt != "application/xhtml+xml" {
+		return Preview{}, fmt.Errorf("%s: not an HTML page (%s)", pageURL, mt)
+	}
+
+	p := parseHead(body)
+	p.URL = pageURL
+	if p.Title == "" && p.Description == "" {
+		return Preview{}, fmt.Errorf("%s: %w", pageURL, ErrNoPreview)
+	}
+	if p.ImageURL != "" {
+		if base, err := url.Parse(pageURL); err == nil {
+			if ref, err := url.Parse(p.ImageURL); err == nil {
+				p.ImageURL = base.ResolveReference(ref).String()
+			}
+		}
+		if img, _, err := get(ctx, f, p.ImageURL, maxImageBytes); err == nil {
+			if info, err := mediainfo.Image(img); err == nil {
+				p.Thumbnail = info.Thumbnail
+			}
+		}
+	}
+	return p, nil
+}
+
+func get(ctx context.Context, f Fetcher, u string, limit int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := f.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", fmt.Errorf("%s: unexpected status %s", u, resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, "", err
+	}
+	return b, resp.Header.Get("Content-Type"), nil
+}
+
+// parseHead collects preview tags up to the start of <body>. OpenGraph tags
+// win over Twitter card tags, which win over <title>/<meta name=description>.
+func parseHead(page []byte) Preview {
+	var (
+		og, tw, plain Preview
+		inTitle       bool
+		title         strings.Builder
+	)
+	z := html.NewTokenizer(bytes.NewReader(page))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			plain.Title = strings.TrimSpace(title.String())
+			return merge(og, tw, plain)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "body":
+				plain.Title = strings.TrimSpace(title.String())
+				return merge(og, tw, plain)
+			case "title":
+				inTitle = true
+			case "meta":
+				var key, content string
+				for _, a := range tok.Attr {
+					switch strings.ToLower(a.Key) {
+					case "property", "name":
+						key = strings.ToLower(a.Val)
+					case "content":
+						content = strings.TrimSpace(a.Val)
+					}
+				}
+				switch key {
+				case "og:title":
+					og.Title = content
+				case "og:description":
+					og.Description = content
+				case "og:image":
+					og.ImageURL = content
+				case "twitter:title":
+					tw.Title = content
+				case "twitter:description":
+					tw.Description = content
+				case "twitter:image":
+					tw.ImageURL = content
+				case "description":
+					plain.Description = content
+				}
+			}
+		case html.EndTagToken:
+			if z.Token().Data == "title" {
+				inTitle = false
+			}
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		}
+	}
+}
+
+func merge(ps ...Preview) Preview {
+	var out Preview
+	for _, p := range ps {
+		if out.Title == "" {
+			out.Title = p.Title
+		}
+		if out.Description == "" {
+			out.Description = p.Description
+		}
+		if out.ImageURL == "" {
+			out.ImageURL = p.ImageURL
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"see https://example.com/a?b=1.":     "https://example.com/a?b=1",
+		"(http://example.org/x) and more":    "http://example.org/x",
+		"no links here, just example.com":    "",
+		"two: https://a.example https://b.x": "https://a.example",
+	} {
+		if got := FindURL(in); got != want {
+			t.Fatalf("FindURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<!doctype html><html><head>
+			<title>Fallback &amp; title</title>
+			<meta name="description" content="plain description">
+			<meta property="og:title" content="Open Graph Title">
+			<meta property="og:image" content="/cover.png">
+			</head><body><meta property="og:description" content="too late"></body></html>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Fallback &amp; title</title></head></html>`))
+	})
+	mux.HandleFunc("/cover.png", func(w http.ResponseWriter, r *http.Request) {
+		_ = png.Encode(w, image.NewGray(image.Rect(0, 0, 40, 20)))
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><body>hi</body></html>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	ctx := context.Background()
+
+	p, err := Fetch(ctx, srv.Client(), srv.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if p.Title != "Open Graph Title" || p.Description != "plain description" || p.ImageURL != srv.URL+"/cover.png" || len(p.Thumbnail) == 0 {
+		t.Fatalf("unexpected preview: %+v (thumb %d bytes)", p, len(p.Thumbnail))
+	}
+
+	p, err = Fetch(ctx, srv.Client(), srv.URL+"/plain")
+	if err != nil || p.Title != "Fallback & title" {
+		t.Fatalf("expected <title> fallback, got %+v err=%v", p, err)
+	}
+
+	if _, err := Fetch(ctx, srv.Client(), srv.URL+"/data.json"); err == nil {
+		t.Fatalf("expected non-HTML page to fail")
+	}
+	if _, err := Fetch(ctx, srv.Client(), srv.URL+"/empty"); !errors.Is(err, ErrNoPreview) {
+		t.Fatalf("expected ErrNoPreview, got %v", err)
+	}
+	if _, err := Fetch(ctx, srv.Client(), srv.URL+"/missing"); err == nil {
+		t.Fatalf("expected 404 to fail")
+	}
+}
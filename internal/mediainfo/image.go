@@ -0,0 +1,89 @@
+// Package mediainfo extracts dimensions, durations and preview thumbnails
+// from media files using only the standard library.
+package mediainfo
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	_ "image/gif"
+	_ "image/png"
+)
+
+// ThumbnailSize bounds the longer side of generated thumbnails, in pixels.
+// WhatsApp shows the thumbnail blurred while the full image downloads, so it
+// only needs to be tiny.
+const ThumbnailSize = 72
+
+type ImageInfo struct {
+	Width     int
+	Height    int
+	Thumbnail []byte // JPEG
+}
+
+// Image decodes a JPEG, PNG or GIF and returns its size and a JPEG thumbnail.
+func Image(data []byte) (ImageInfo, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	b := img.Bounds()
+	info := ImageInfo{Width: b.Dx(), Height: b.Dy()}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, downscale(img, ThumbnailSize), &jpeg.Options{Quality: 60}); err != nil {
+		return info, err
+	}
+	info.Thumbnail = buf.Bytes()
+	return info, nil
+}
+
+// downscale shrinks img so its longer side is at most max, averaging the
+// source pixels covered by each target pixel. Transparent areas are blended
+// onto white, since JPEG has no alpha.
+func downscale(img image.Image, max int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w >= h && w > max {
+		tw, th = max, h*max/w
+	} else if h > w && h > max {
+		tw, th = w*max/h, max
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for ty := 0; ty < th; ty++ {
+		y0, y1 := b.Min.Y+ty*h/th, b.Min.Y+(ty+1)*h/th
+		if y1 == y0 {
+			y1++
+		}
+		for tx := 0; tx < tw; tx++ {
+			x0, x1 := b.Min.X+tx*w/tw, b.Min.X+(tx+1)*w/tw
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, bl, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := img.At(x, y).RGBA()
+					// Premultiplied: add the white background behind alpha.
+					white := uint64(0xffff - ca)
+					r += uint64(cr) + white
+					g += uint64(cg) + white
+					bl += uint64(cb) + white
+					n++
+				}
+			}
+			dst.Set(tx, ty, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: 0xffff})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,166 @@
+package mediainfo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestImageThumbnail(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	info, err := Image(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	if info.Width != 400 || info.Height != 200 {
+		t.Fatalf("unexpected size %dx%d", info.Width, info.Height)
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(info.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail is not a JPEG: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != ThumbnailSize || b.Dy() != ThumbnailSize/2 {
+		t.Fatalf("unexpected thumbnail size %v", b)
+	}
+}
+
+func TestImageRejectsUnknownFormat(t *testing.T) {
+	if _, err := Image([]byte("definitely not an image")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	return append(append(b, typ...), body...)
+}
+
+func mvhd(timescale, duration uint32) []byte {
+	b := make([]byte, 100)
+	binary.BigEndian.PutUint32(b[12:], timescale)
+	binary.BigEndian.PutUint32(b[16:], duration)
+	return box("mvhd", b)
+}
+
+func trak(handler string, w, h uint32) []byte {
+	return rotatedTrak(handler, w, h, 0)
+}
+
+// rotatedTrak builds a version 0 track whose tkhd matrix rotates the picture
+// clockwise by deg (0, 90, 180 or 270).
+func rotatedTrak(handler string, w, h uint32, deg int) []byte {
+	const one = 0x10000
+	abcd := map[int][4]int32{
+		0:   {one, 0, 0, one},
+		90:  {0, one, -one, 0},
+		180: {-one, 0, 0, -one},
+		270: {0, -one, one, 0},
+	}[deg]
+	tkhd := make([]byte, 84)
+	// Matrix {a b u c d v x y w} at 40; w is 1.0 in 2.30 fixed point.
+	binary.BigEndian.PutUint32(tkhd[40:], uint32(abcd[0]))
+	binary.BigEndian.PutUint32(tkhd[44:], uint32(abcd[1]))
+	binary.BigEndian.PutUint32(tkhd[52:], uint32(abcd[2]))
+	binary.BigEndian.PutUint32(tkhd[56:], uint32(abcd[3]))
+	binary.BigEndian.PutUint32(tkhd[72:], 0x40000000)
+	binary.BigEndian.PutUint32(tkhd[76:], w<<16)
+	binary.BigEndian.PutUint32(tkhd[80:], h<<16)
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:], handler)
+	return box("trak", box("tkhd", tkhd), box("mdia", box("hdlr", hdlr)))
+}
+
+func TestMP4(t *testing.T) {
+	file := bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41")),
+		box("mdat", make([]byte, 1000)),
+		box("moov", mvhd(600, 6300), trak("soun", 0, 0), trak("vide", 1280, 720)),
+	}, nil)
+
+	info, err := MP4(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("MP4: %v", err)
+	}
+	if info.Width != 1280 || info.Height != 720 || info.Duration != 10500*time.Millisecond {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+}
+
+func TestMP4AppliesRotation(t *testing.T) {
+	for deg, want := range map[int][2]int{0: {1920, 1080}, 90: {1080, 1920}, 180: {1920, 1080}, 270: {1080, 1920}} {
+		file := bytes.Join([][]byte{
+			box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41")),
+			box("moov", mvhd(600, 600), rotatedTrak("vide", 1920, 1080, deg)),
+		}, nil)
+		info, err := MP4(bytes.NewReader(file))
+		if err != nil {
+			t.Fatalf("MP4 (%d°): %v", deg, err)
+		}
+		if info.Width != want[0] || info.Height != want[1] {
+			t.Fatalf("%d°: expected %dx%d, got %dx%d", deg, want[0], want[1], info.Width, info.Height)
+		}
+	}
+}
+
+func TestMP4RejectsOtherFiles(t *testing.T) {
+	if _, err := MP4(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00AVI LIST"))); !errors.Is(err, ErrNotMP4) {
+		t.Fatalf("expected ErrNotMP4, got %v", err)
+	}
+}
+
+func riff(chunk string, body []byte) []byte {
+	b := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(12+len(body)))...)
+	b = append(b, "WEBP"+chunk...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(body)))
+	return append(b, body...)
+}
+
+func TestWebP(t *testing.T) {
+	vp8 := []byte{0, 0, 0, 0x9d, 0x01, 0x2a}
+	vp8 = binary.LittleEndian.AppendUint16(vp8, 512)
+	vp8 = binary.LittleEndian.AppendUint16(vp8, 512)
+
+	vp8l := []byte{0x2f}
+	vp8l = binary.LittleEndian.AppendUint32(vp8l, uint32(511)|uint32(255)<<14)
+
+	// VP8X with the animation flag and a 512x512 canvas.
+	vp8x := []byte{0x02, 0, 0, 0, 0xff, 0x01, 0x00, 0xff, 0x01, 0x00}
+
+	for name, tc := range map[string]struct {
+		data []byte
+		want WebPInfo
+	}{
+		"lossy":    {riff("VP8 ", vp8), WebPInfo{Width: 512, Height: 512}},
+		"lossless": {riff("VP8L", vp8l), WebPInfo{Width: 512, Height: 256}},
+		"animated": {riff("VP8X", vp8x), WebPInfo{Width: 512, Height: 512, Animated: true}},
+	} {
+		got, err := WebP(tc.data)
+		if err != nil {
+			t.Fatalf("%s: WebP: %v", name, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %+v, want %+v", name, got, tc.want)
+		}
+	}
+
+	if _, err := WebP([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00")); !errors.Is(err, ErrNotWebP) {
+		t.Fatalf("expected ErrNotWebP, got %v", err)
+	}
+}
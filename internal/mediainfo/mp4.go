@@ -0,0 +1,182 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var ErrNotMP4 = errors.New("not an MP4 file")
+
+type VideoInfo struct {
+	Width    int
+	Height   int
+	Duration time.Duration
+}
+
+// MP4 reads the movie duration and the first video track's display size from
+// an MP4/MOV container. Only box headers and the moov box are read, so large
+// files are not loaded into memory.
+func MP4(r io.ReadSeeker) (VideoInfo, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return VideoInfo{}, err
+	}
+	var hdr [8]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil || string(hdr[4:]) != "ftyp" {
+		return VideoInfo{}, ErrNotMP4
+	}
+
+	var info VideoInfo
+	err = walkBoxes(r, 0, end, func(typ string, start, size int64) error {
+		if typ != "moov" {
+			return nil
+		}
+		if err := readMoov(r, start, start+size, &info); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if errors.Is(err, errStop) {
+		return info, nil
+	}
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	return VideoInfo{}, fmt.Errorf("mp4: no moov box")
+}
+
+var errStop = errors.New("stop")
+
+// walkBoxes calls fn for each box between start and end. start/size passed to
+// fn describe the box payload (after the header).
+func walkBoxes(r io.ReadSeeker, start, end int64, fn func(typ string, start, size int64) error) error {
+	off := start
+	for off+8 <= end {
+		if _, err := r.Seek(off, io.SeekStart); err != nil {
+			return err
+		}
+		var hdr [8]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return fmt.Errorf("mp4: read box header: %w", err)
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		typ := string(hdr[4:8])
+		hdrLen := int64(8)
+		switch size {
+		case 0:
+			size = end - off
+		case 1:
+			var ext [8]byte
+			if _, err := io.ReadFull(r, ext[:]); err != nil {
+				return fmt.Errorf("mp4: read box header: %w", err)
+			}
+			size = int64(binary.BigEndian.Uint64(ext[:]))
+			hdrLen = 16
+		}
+		if size < hdrLen || off+size > end {
+			return fmt.Errorf("mp4: corrupt %q box", typ)
+		}
+		if err := fn(typ, off+hdrLen, size-hdrLen); err != nil {
+			return err
+		}
+		off += size
+	}
+	return nil
+}
+
+func readMoov(r io.ReadSeeker, start, end int64, info *VideoInfo) error {
+	return walkBoxes(r, start, end, func(typ string, s, n int64) error {
+		switch typ {
+		case "mvhd":
+			b, err := readAt(r, s, n)
+			if err != nil {
+				return err
+			}
+			var timescale, duration uint64
+			if len(b) >= 32 && b[0] == 1 {
+				timescale = uint64(binary.BigEndian.Uint32(b[20:24]))
+				duration = binary.BigEndian.Uint64(b[24:32])
+			} else if len(b) >= 20 {
+				timescale = uint64(binary.BigEndian.Uint32(b[12:16]))
+				duration = uint64(binary.BigEndian.Uint32(b[16:20]))
+			}
+			if timescale > 0 {
+				info.Duration = time.Duration(duration * uint64(time.Second) / timescale)
+			}
+		case "trak":
+			if info.Width > 0 {
+				return nil
+			}
+			w, h, video, err := readTrak(r, s, s+n)
+			if err != nil {
+				return err
+			}
+			if video {
+				info.Width, info.Height = w, h
+			}
+		}
+		return nil
+	})
+}
+
+// readTrak returns the tkhd display size, swapped for tracks rotated by ±90°,
+// and whether the track's handler is "vide".
+func readTrak(r io.ReadSeeker, start, end int64) (w, h int, video bool, err error) {
+	err = walkBoxes(r, start, end, func(typ string, s, n int64) error {
+		switch typ {
+		case "tkhd":
+			b, err := readAt(r, s, n)
+			if err != nil {
+				return err
+			}
+			// Width and height are the last 8 bytes, as 16.16 fixed point,
+			// preceded by the 3x3 transformation matrix {a b u c d v x y w}.
+			// Phones record portrait video as landscape plus a rotation; a
+			// quarter turn has a == d == 0.
+			if len(b) >= 84 {
+				w = int(binary.BigEndian.Uint32(b[len(b)-8:]) >> 16)
+				h = int(binary.BigEndian.Uint32(b[len(b)-4:]) >> 16)
+				m := b[len(b)-44 : len(b)-8]
+				a := int32(binary.BigEndian.Uint32(m[0:4]))
+				d := int32(binary.BigEndian.Uint32(m[16:20]))
+				if a == 0 && d == 0 {
+					w, h = h, w
+				}
+			}
+		case "mdia":
+			return walkBoxes(r, s, s+n, func(typ string, s, n int64) error {
+				if typ != "hdlr" {
+					return nil
+				}
+				b, err := readAt(r, s, n)
+				if err != nil {
+					return err
+				}
+				video = len(b) >= 12 && string(b[8:12]) == "vide"
+				return nil
+			})
+		}
+		return nil
+	})
+	return w, h, video, err
+}
+
+func readAt(r io.ReadSeeker, off, n int64) ([]byte, error) {
+	if n > 1<<16 {
+		n = 1 << 16
+	}
+	if _, err := r.Seek(off, io.SeekStart); err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, fmt.Errorf("mp4: read box: %w", err)
+	}
+	return b, nil
+}
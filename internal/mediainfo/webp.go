@@ -0,0 +1,61 @@
+package mediainfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var ErrNotWebP = errors.New("not a WebP file")
+
+type WebPInfo struct {
+	Width    int
+	Height   int
+	Animated bool
+}
+
+// WebP reads the canvas size and animation flag from a WebP header. Simple
+// lossy (VP8) and lossless (VP8L) files as well as extended (VP8X) files are
+// understood; image data is not decoded.
+func WebP(data []byte) (WebPInfo, error) {
+	if len(data) < 20 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return WebPInfo{}, ErrNotWebP
+	}
+	chunk := string(data[12:16])
+	body := data[20:]
+	switch chunk {
+	case "VP8X":
+		if len(body) < 10 {
+			return WebPInfo{}, fmt.Errorf("webp: short VP8X chunk")
+		}
+		return WebPInfo{
+			Width:    int(uint24(body[4:7])) + 1,
+			Height:   int(uint24(body[7:10])) + 1,
+			Animated: body[0]&0x02 != 0,
+		}, nil
+	case "VP8 ":
+		// 3-byte frame tag, then the key frame start code.
+		if len(body) < 10 || body[3] != 0x9d || body[4] != 0x01 || body[5] != 0x2a {
+			return WebPInfo{}, fmt.Errorf("webp: bad VP8 frame header")
+		}
+		return WebPInfo{
+			Width:  int(binary.LittleEndian.Uint16(body[6:8]) & 0x3fff),
+			Height: int(binary.LittleEndian.Uint16(body[8:10]) & 0x3fff),
+		}, nil
+	case "VP8L":
+		if len(body) < 5 || body[0] != 0x2f {
+			return WebPInfo{}, fmt.Errorf("webp: bad VP8L header")
+		}
+		bits := binary.LittleEndian.Uint32(body[1:5])
+		return WebPInfo{
+			Width:  int(bits&0x3fff) + 1,
+			Height: int(bits>>14&0x3fff) + 1,
+		}, nil
+	default:
+		return WebPInfo{}, fmt.Errorf("webp: unknown chunk %q", chunk)
+	}
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
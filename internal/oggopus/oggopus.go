@@ -0,0 +1,202 @@
+// Package oggopus reads the metadata WhatsApp needs for voice notes from an
+// Ogg/Opus file: duration and a coarse waveform, without decoding audio.
+package oggopus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WaveformSamples is the number of waveform values WhatsApp displays.
+const WaveformSamples = 64
+
+var (
+	ErrNotOgg  = errors.New("not an Ogg file")
+	ErrNotOpus = errors.New("Ogg stream does not contain Opus audio")
+)
+
+type Info struct {
+	Channels   int
+	Duration   time.Duration
+	PreSkip    int
+	SampleRate int // original input rate from the header (informational)
+	// Waveform holds WaveformSamples values from 0 to 100. Opus is not
+	// decoded: the level is estimated from the encoded bytes per millisecond,
+	// which tracks loudness well enough for the preview bars.
+	Waveform []byte
+}
+
+type packet struct {
+	data []byte
+	ms   float64
+}
+
+// Parse reads an Ogg/Opus stream. Only the first logical stream is used.
+func Parse(r io.Reader) (Info, error) {
+	br := bufio.NewReader(r)
+	var (
+		info     Info
+		serial   uint32
+		first    = true
+		partial  []byte
+		lastGran int64
+		nPackets int
+		packets  []packet
+	)
+	for {
+		hdr := make([]byte, 27)
+		if _, err := io.ReadFull(br, hdr); err != nil {
+			if err == io.EOF && !first {
+				break
+			}
+			if first {
+				return Info{}, ErrNotOgg
+			}
+			return Info{}, fmt.Errorf("read ogg page: %w", err)
+		}
+		if string(hdr[:4]) != "OggS" {
+			if first {
+				return Info{}, ErrNotOgg
+			}
+			return Info{}, fmt.Errorf("corrupt ogg page")
+		}
+		gran := int64(binary.LittleEndian.Uint64(hdr[6:14]))
+		pageSerial := binary.LittleEndian.Uint32(hdr[14:18])
+		segs := make([]byte, hdr[26])
+		if _, err := io.ReadFull(br, segs); err != nil {
+			return Info{}, fmt.Errorf("read ogg page: %w", err)
+		}
+		size := 0
+		for _, s := range segs {
+			size += int(s)
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(br, body); err != nil {
+			return Info{}, fmt.Errorf("read ogg page: %w", err)
+		}
+		if first {
+			serial = pageSerial
+			first = false
+		}
+		if pageSerial != serial {
+			continue
+		}
+		if gran >= 0 {
+			lastGran = gran
+		}
+
+		off := 0
+		for _, s := range segs {
+			partial = append(partial, body[off:off+int(s)]...)
+			off += int(s)
+			if s == 255 {
+				continue // packet continues in the next segment
+			}
+			pkt := partial
+			partial = nil
+			switch nPackets {
+			case 0:
+				if len(pkt) < 19 || !bytes.HasPrefix(pkt, []byte("OpusHead")) {
+					return Info{}, ErrNotOpus
+				}
+				info.Channels = int(pkt[9])
+				info.PreSkip = int(binary.LittleEndian.Uint16(pkt[10:12]))
+				info.SampleRate = int(binary.LittleEndian.Uint32(pkt[12:16]))
+			case 1:
+				// OpusTags
+			default:
+				packets = append(packets, packet{data: pkt, ms: packetMillis(pkt)})
+			}
+			nPackets++
+		}
+	}
+	if nPackets == 0 {
+		return Info{}, ErrNotOpus
+	}
+
+	samples := lastGran - int64(info.PreSkip)
+	if samples < 0 {
+		samples = 0
+	}
+	// Granule positions are always in 48 kHz samples for Opus.
+	info.Duration = time.Duration(samples) * time.Second / 48000
+	info.Waveform = waveform(packets)
+	return info, nil
+}
+
+// packetMillis returns the audio duration of an Opus packet from its TOC byte
+// (RFC 6716 section 3.1).
+func packetMillis(p []byte) float64 {
+	if len(p) == 0 {
+		return 0
+	}
+	toc := p[0]
+	config := toc >> 3
+	var frame float64
+	switch {
+	case config < 12: // SILK
+		frame = []float64{10, 20, 40, 60}[config%4]
+	case config < 16: // hybrid
+		frame = []float64{10, 20}[config%2]
+	default: // CELT
+		frame = []float64{2.5, 5, 10, 20}[config%4]
+	}
+	frames := 1
+	switch toc & 3 {
+	case 1, 2:
+		frames = 2
+	case 3:
+		if len(p) > 1 {
+			frames = int(p[1] & 0x3f)
+		}
+	}
+	return frame * float64(frames)
+}
+
+func waveform(packets []packet) []byte {
+	out := make([]byte, WaveformSamples)
+	var total float64
+	for _, p := range packets {
+		total += p.ms
+	}
+	if total <= 0 {
+		return out
+	}
+
+	var bytesIn, msIn [WaveformSamples]float64
+	var at float64
+	for _, p := range packets {
+		bin := int(at / total * WaveformSamples)
+		if bin >= WaveformSamples {
+			bin = WaveformSamples - 1
+		}
+		bytesIn[bin] += float64(len(p.data))
+		msIn[bin] += p.ms
+		at += p.ms
+	}
+
+	var level [WaveformSamples]float64
+	var max float64
+	for i := range level {
+		if msIn[i] > 0 {
+			level[i] = bytesIn[i] / msIn[i]
+		} else if i > 0 {
+			level[i] = level[i-1] // fewer packets than bins
+		}
+		if level[i] > max {
+			max = level[i]
+		}
+	}
+	if max == 0 {
+		return out
+	}
+	for i, v := range level {
+		out[i] = byte(v / max * 100)
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package oggopus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// oggPage encodes one page holding the given segments. The CRC is left zero;
+// Parse does not verify it.
+func oggPage(gran int64, seq uint32, segs []byte, body []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("OggS")
+	b.WriteByte(0)
+	b.WriteByte(0)
+	_ = binary.Write(&b, binary.LittleEndian, gran)
+	_ = binary.Write(&b, binary.LittleEndian, uint32(7))
+	_ = binary.Write(&b, binary.LittleEndian, seq)
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+	b.WriteByte(byte(len(segs)))
+	b.Write(segs)
+	b.Write(body)
+	return b.Bytes()
+}
+
+func lacing(n int) []byte {
+	var segs []byte
+	for n >= 255 {
+		segs = append(segs, 255)
+		n -= 255
+	}
+	return append(segs, byte(n))
+}
+
+func opusHead(preSkip uint16) []byte {
+	h := []byte("OpusHead")
+	h = append(h, 1, 1)
+	h = binary.LittleEndian.AppendUint16(h, preSkip)
+	h = binary.LittleEndian.AppendUint32(h, 48000)
+	return append(h, 0, 0, 0)
+}
+
+func TestParseDurationAndWaveform(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(oggPage(0, 0, lacing(19), opusHead(312)))
+	tags := []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00")
+	stream.Write(oggPage(0, 1, lacing(len(tags)), tags))
+
+	// 100 packets of 20 ms CELT: loud first half, near-silent second half.
+	var segs, body []byte
+	for i := 0; i < 100; i++ {
+		n := 60
+		if i >= 50 {
+			n = 3
+		}
+		pkt := make([]byte, n)
+		pkt[0] = 31 << 3
+		segs = append(segs, lacing(n)...)
+		body = append(body, pkt...)
+	}
+	stream.Write(oggPage(100*960+312, 2, segs, body))
+
+	info, err := Parse(&stream)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if info.Duration != 2*time.Second || info.Channels != 1 || info.PreSkip != 312 {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if len(info.Waveform) != WaveformSamples || info.Waveform[0] != 100 || info.Waveform[WaveformSamples-1] > 10 {
+		t.Fatalf("unexpected waveform: %v", info.Waveform)
+	}
+}
+
+func TestParsePacketSpanningPages(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(oggPage(0, 0, lacing(19), opusHead(0)))
+	tags := []byte("OpusTags")
+	stream.Write(oggPage(0, 1, lacing(len(tags)), tags))
+	pkt := make([]byte, 300)
+	pkt[0] = 31 << 3
+	stream.Write(oggPage(-1, 2, []byte{255}, pkt[:255]))
+	stream.Write(oggPage(960, 3, []byte{45}, pkt[255:]))
+
+	info, err := Parse(&stream)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if info.Duration != 20*time.Millisecond {
+		t.Fatalf("unexpected duration %s", info.Duration)
+	}
+}
+
+func TestParseRejectsNonOpus(t *testing.T) {
+	if _, err := Parse(bytes.NewReader([]byte("ID3\x04 not ogg at all, clearly an mp3"))); !errors.Is(err, ErrNotOgg) {
+		t.Fatalf("expected ErrNotOgg, got %v", err)
+	}
+	vorbis := []byte("\x01vorbis\x00\x00\x00\x00\x01\x44\xac\x00\x00")
+	if _, err := Parse(bytes.NewReader(oggPage(0, 0, lacing(len(vorbis)), vorbis))); !errors.Is(err, ErrNotOpus) {
+		t.Fatalf("expected ErrNotOpus, got %v", err)
+	}
+}
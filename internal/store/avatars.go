@@ -0,0 +1,60 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SaveAvatar records the locally downloaded profile picture for a contact or
+// group so an unchanged picture ID can be skipped next time.
+func (d *DB) SaveAvatar(a Avatar) error {
+	if strings.TrimSpace(a.JID) == "" || strings.TrimSpace(a.PictureID) == "" || strings.TrimSpace(a.Path) == "" {
+		return fmt.Errorf("jid, picture id and path are required")
+	}
+	updated := a.UpdatedAt
+	if updated.IsZero() {
+		updated = time.Now().UTC()
+	}
+	_, err := d.sql.Exec(`
+		INSERT INTO avatars(jid, picture_id, path, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET
+			picture_id=excluded.picture_id,
+			path=excluded.path,
+			updated_at=excluded.updated_at
+	`, a.JID, a.PictureID, a.Path, unix(updated))
+	return err
+}
+
+func (d *DB) GetAvatar(jid string) (Avatar, error) {
+	row := d.sql.QueryRow(`SELECT jid, picture_id, path, updated_at FROM avatars WHERE jid = ?`, jid)
+	var a Avatar
+	var updated int64
+	if err := row.Scan(&a.JID, &a.PictureID, &a.Path, &updated); err != nil {
+		return Avatar{}, err
+	}
+	a.UpdatedAt = fromUnix(updated)
+	return a, nil
+}
+
+// DeleteAvatar forgets the cached avatar, e.g. after the picture was removed.
+func (d *DB) DeleteAvatar(jid string) error {
+	_, err := d.sql.Exec(`DELETE FROM avatars WHERE jid = ?`, jid)
+	return err
+}
+
+// SetContactAbout stores the "about" status text, creating the contact row if
+// needed. An empty about is stored as well (the user cleared it).
+func (d *DB) SetContactAbout(jid, about string) error {
+	now := time.Now().UTC().Unix()
+	_, err := d.sql.Exec(`
+		INSERT INTO contacts(jid, about, about_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET
+			about=excluded.about,
+			about_at=excluded.about_at,
+			updated_at=excluded.updated_at
+	`, jid, about, now, now)
+	return err
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+// ReplaceBlocklist makes the local blocklist match jids exactly, keeping the
+// original blocked_at of entries that were already present.
+func (d *DB) ReplaceBlocklist(jids []string) (err error) {
+	tx, err := d.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	keep := map[string]bool{}
+	for _, jid := range jids {
+		if jid = strings.TrimSpace(jid); jid != "" {
+			keep[jid] = true
+		}
+	}
+
+	rows, err := tx.Query(`SELECT jid FROM blocklist`)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for rows.Next() {
+		var jid string
+		if err = rows.Scan(&jid); err != nil {
+			rows.Close()
+			return err
+		}
+		if !keep[jid] {
+			stale = append(stale, jid)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, jid := range stale {
+		if _, err = tx.Exec(`DELETE FROM blocklist WHERE jid = ?`, jid); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC().Unix()
+	for jid := range keep {
+		if _, err = tx.Exec(`INSERT OR IGNORE INTO blocklist(jid, blocked_at) VALUES (?, ?)`, jid, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *DB) SetBlocked(jid string, blocked bool) error {
+	if !blocked {
+		_, err := d.sql.Exec(`DELETE FROM blocklist WHERE jid = ?`, jid)
+		return err
+	}
+	_, err := d.sql.Exec(`INSERT OR IGNORE INTO blocklist(jid, blocked_at) VALUES (?, ?)`, jid, time.Now().UTC().Unix())
+	return err
+}
+
+func (d *DB) IsBlocked(jid string) (bool, error) {
+	row := d.sql.QueryRow(`SELECT COUNT(*) FROM blocklist WHERE jid = ?`, jid)
+	var n int
+	if err := row.Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (d *DB) ListBlocked() ([]BlockedContact, error) {
+	rows, err := d.sql.Query(`
+		SELECT
+			b.jid,
+			COALESCE(c.phone,''),
+			COALESCE(NULLIF(c.full_name,''), NULLIF(c.push_name,''), NULLIF(c.business_name,''), ''),
+			COALESCE(a.alias,''),
+			b.blocked_at
+		FROM blocklist b
+		LEFT JOIN contacts c ON c.jid = b.jid
+		LEFT JOIN contact_aliases a ON a.jid = b.jid
+		ORDER BY b.blocked_at DESC, b.jid
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []BlockedContact
+	for rows.Next() {
+		var b BlockedContact
+		var blockedAt int64
+		if err := rows.Scan(&b.JID, &b.Phone, &b.Name, &b.Alias, &blockedAt); err != nil {
+			return nil, err
+		}
+		b.BlockedAt = fromUnix(blockedAt)
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+// ReplaceBroadcastMembers makes the stored members of a broadcast list match
+// members exactly.
+func (d *DB) ReplaceBroadcastMembers(listJID string, members []string) (err error) {
+	tx, err := d.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`DELETE FROM broadcast_members WHERE list_jid = ?`, listJID); err != nil {
+		return err
+	}
+	now := time.Now().UTC().Unix()
+	for _, m := range members {
+		if m = strings.TrimSpace(m); m == "" {
+			continue
+		}
+		if _, err = tx.Exec(`INSERT OR IGNORE INTO broadcast_members(list_jid, member_jid, updated_at) VALUES (?, ?, ?)`, listJID, m, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// BroadcastMembers returns the known members of a broadcast list.
+func (d *DB) BroadcastMembers(listJID string) ([]string, error) {
+	rows, err := d.sql.Query(`SELECT member_jid FROM broadcast_members WHERE list_jid = ? ORDER BY member_jid`, listJID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			return nil, err
+		}
+		out = append(out, jid)
+	}
+	return out, rows.Err()
+}
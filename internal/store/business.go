@@ -0,0 +1,88 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SaveBusinessProfile caches a business profile and flags the contact as a
+// business account.
+func (d *DB) SaveBusinessProfile(p BusinessProfile) (err error) {
+	if strings.TrimSpace(p.JID) == "" {
+		return fmt.Errorf("jid is required")
+	}
+	fetched := p.FetchedAt
+	if fetched.IsZero() {
+		fetched = time.Now().UTC()
+	}
+	hours, err := json.Marshal(p.Hours)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`
+		INSERT INTO business_profiles(jid, description, address, email, website, categories, hours_tz, hours, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET
+			description=excluded.description,
+			address=excluded.address,
+			email=excluded.email,
+			website=excluded.website,
+			categories=excluded.categories,
+			hours_tz=excluded.hours_tz,
+			hours=excluded.hours,
+			fetched_at=excluded.fetched_at
+	`, p.JID, nullIfEmpty(p.Description), nullIfEmpty(p.Address), nullIfEmpty(p.Email), nullIfEmpty(p.Website),
+		nullIfEmpty(strings.Join(p.Categories, "; ")), nullIfEmpty(p.HoursTimeZone), string(hours), unix(fetched)); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(`
+		INSERT INTO contacts(jid, business_name, is_business, updated_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT(jid) DO UPDATE SET
+			business_name=COALESCE(NULLIF(excluded.business_name,''), contacts.business_name),
+			is_business=1,
+			updated_at=excluded.updated_at
+	`, p.JID, nullIfEmpty(p.Name), time.Now().UTC().Unix()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *DB) GetBusinessProfile(jid string) (BusinessProfile, error) {
+	row := d.sql.QueryRow(`
+		SELECT b.jid, COALESCE(c.business_name,''), COALESCE(b.description,''), COALESCE(b.address,''), COALESCE(b.email,''),
+			COALESCE(b.website,''), COALESCE(b.categories,''), COALESCE(b.hours_tz,''), COALESCE(b.hours,''), b.fetched_at
+		FROM business_profiles b
+		LEFT JOIN contacts c ON c.jid = b.jid
+		WHERE b.jid = ?
+	`, jid)
+	var p BusinessProfile
+	var categories, hours string
+	var fetched int64
+	if err := row.Scan(&p.JID, &p.Name, &p.Description, &p.Address, &p.Email, &p.Website, &categories, &p.HoursTimeZone, &hours, &fetched); err != nil {
+		return BusinessProfile{}, err
+	}
+	if categories != "" {
+		p.Categories = strings.Split(categories, "; ")
+	}
+	if hours != "" && hours != "null" {
+		if err := json.Unmarshal([]byte(hours), &p.Hours); err != nil {
+			return BusinessProfile{}, fmt.Errorf("decode business hours: %w", err)
+		}
+	}
+	p.FetchedAt = fromUnix(fetched)
+	return p, nil
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecordCall stores a new call. Repeated offers for the same call ID (a 1:1
+// offer and its group notice, or a history entry seen twice) keep the first
+// row, but a video flag is never lost.
+func (d *DB) RecordCall(c Call) error {
+	if strings.TrimSpace(c.CallID) == "" {
+		return fmt.Errorf("call id is required")
+	}
+	if strings.TrimSpace(c.PeerJID) == "" {
+		return fmt.Errorf("peer jid is required")
+	}
+	if c.Direction == "" {
+		c.Direction = "incoming"
+	}
+	if c.Outcome == "" {
+		c.Outcome = CallRinging
+	}
+	if c.StartedAt.IsZero() {
+		c.StartedAt = time.Now()
+	}
+	_, err := d.sql.Exec(`
+		INSERT INTO calls(call_id, peer_jid, group_jid, direction, is_video, outcome, started_at, accepted_at, ended_at, end_reason)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(call_id) DO UPDATE SET
+			is_video=MAX(calls.is_video, excluded.is_video),
+			group_jid=COALESCE(calls.group_jid, excluded.group_jid)
+	`, c.CallID, c.PeerJID, nullIfEmpty(c.GroupJID), c.Direction, boolToInt(c.Video), c.Outcome, unix(c.StartedAt),
+		nullIfZero(unix(c.AcceptedAt)), nullIfZero(unix(c.EndedAt)), nullIfEmpty(c.EndReason))
+	return err
+}
+
+// AnswerCall marks a ringing call as answered (on the phone or another
+// linked device).
+func (d *DB) AnswerCall(callID string, at time.Time) error {
+	_, err := d.sql.Exec(`
+		UPDATE calls SET outcome = ?, accepted_at = ? WHERE call_id = ? AND outcome = ?
+	`, CallAnswered, unix(at), callID, CallRinging)
+	return err
+}
+
+// RejectCall marks a call as rejected; outcome is CallRejected or
+// CallAutoRejected. A call already marked missed is rejected too: the
+// server's terminate often arrives before the reject has been confirmed.
+func (d *DB) RejectCall(callID, outcome string, at time.Time) error {
+	_, err := d.sql.Exec(`
+		UPDATE calls SET outcome = ?, ended_at = COALESCE(ended_at, ?) WHERE call_id = ? AND outcome IN (?, ?)
+	`, outcome, unix(at), callID, CallRinging, CallMissed)
+	return err
+}
+
+// EndCall records when a call ended. A call that was still ringing was
+// missed.
+func (d *DB) EndCall(callID, reason string, at time.Time) error {
+	_, err := d.sql.Exec(`
+		UPDATE calls SET
+			outcome = CASE outcome WHEN ? THEN ? ELSE outcome END,
+			ended_at = COALESCE(ended_at, ?),
+			end_reason = COALESCE(end_reason, ?)
+		WHERE call_id = ?
+	`, CallRinging, CallMissed, unix(at), nullIfEmpty(reason), callID)
+	return err
+}
+
+type ListCallsParams struct {
+	Peer      string // peer JID
+	Direction string // "incoming" or "outgoing"
+	Outcome   string
+	Video     *bool
+	Limit     int
+	Before    *time.Time
+	After     *time.Time
+}
+
+// ListCalls lists calls, newest first.
+func (d *DB) ListCalls(p ListCallsParams) ([]Call, error) {
+	if p.Limit <= 0 {
+		p.Limit = 50
+	}
+	query := `
+		SELECT k.call_id, k.peer_jid,
+			COALESCE(NULLIF(a.alias,''), NULLIF(c.full_name,''), NULLIF(c.push_name,''), NULLIF(c.business_name,''), ''),
+			COALESCE(k.group_jid,''), k.direction, k.is_video, k.outcome, k.started_at,
+			COALESCE(k.accepted_at,0), COALESCE(k.ended_at,0), COALESCE(k.end_reason,'')
+		FROM calls k
+		LEFT JOIN contacts c ON c.jid = k.peer_jid
+		LEFT JOIN contact_aliases a ON a.jid = k.peer_jid
+		WHERE 1=1`
+	var args []interface{}
+	if p.Peer = strings.TrimSpace(p.Peer); p.Peer != "" {
+		query += ` AND k.peer_jid = ?`
+		args = append(args, d.ResolveJID(p.Peer))
+	}
+	if p.Direction != "" {
+		query += ` AND k.direction = ?`
+		args = append(args, p.Direction)
+	}
+	if p.Outcome != "" {
+		query += ` AND k.outcome = ?`
+		args = append(args, p.Outcome)
+	}
+	if p.Video != nil {
+		query += ` AND k.is_video = ?`
+		args = append(args, boolToInt(*p.Video))
+	}
+	if p.After != nil {
+		query += ` AND k.started_at > ?`
+		args = append(args, unix(*p.After))
+	}
+	if p.Before != nil {
+		query += ` AND k.started_at < ?`
+		args = append(args, unix(*p.Before))
+	}
+	query += ` ORDER BY k.started_at DESC, k.call_id LIMIT ?`
+	args = append(args, p.Limit)
+
+	rows, err := d.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Call
+	for rows.Next() {
+		var c Call
+		var video int
+		var startedAt, acceptedAt, endedAt int64
+		if err := rows.Scan(&c.CallID, &c.PeerJID, &c.PeerName, &c.GroupJID, &c.Direction, &video, &c.Outcome,
+			&startedAt, &acceptedAt, &endedAt, &c.EndReason); err != nil {
+			return nil, err
+		}
+		c.Video = video != 0
+		c.StartedAt = fromUnix(startedAt)
+		c.AcceptedAt = fromUnix(acceptedAt)
+		c.EndedAt = fromUnix(endedAt)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -25,7 +27,7 @@ func (d *DB) ListChats(query string, limit int) ([]Chat, error) {
 	if limit <= 0 {
 		limit = 50
 	}
-	q := `SELECT jid, kind, COALESCE(name,''), COALESCE(last_message_ts,0) FROM chats WHERE 1=1`
+	q := `SELECT jid, kind, COALESCE(name,''), COALESCE(last_message_ts,0) FROM chats WHERE kind != 'status'`
 	var args []interface{}
 	if strings.TrimSpace(query) != "" {
 		q += ` AND (LOWER(name) LIKE LOWER(?) OR LOWER(jid) LIKE LOWER(?))`
@@ -55,36 +57,98 @@ func (d *DB) ListChats(query string, limit int) ([]Chat, error) {
 }
 
 func (d *DB) GetChat(jid string) (Chat, error) {
-	row := d.sql.QueryRow(`SELECT jid, kind, COALESCE(name,''), COALESCE(last_message_ts,0) FROM chats WHERE jid = ?`, jid)
+	row := d.sql.QueryRow(`SELECT jid, kind, COALESCE(name,''), COALESCE(last_message_ts,0), COALESCE(ephemeral_expiration,0) FROM chats WHERE jid = ?`, jid)
 	var c Chat
 	var ts int64
-	if err := row.Scan(&c.JID, &c.Kind, &c.Name, &ts); err != nil {
+	if err := row.Scan(&c.JID, &c.Kind, &c.Name, &ts, &c.EphemeralExpiration); err != nil {
 		return Chat{}, err
 	}
 	c.LastMessageTS = fromUnix(ts)
 	return c, nil
 }
 
-func (d *DB) SearchContacts(query string, limit int) ([]Contact, error) {
-	if strings.TrimSpace(query) == "" {
+// SetChatEphemeral records a chat's disappearing-message timer (seconds,
+// 0 = off). Chats that aren't stored yet are ignored.
+func (d *DB) SetChatEphemeral(jid string, seconds uint32) error {
+	_, err := d.sql.Exec(`UPDATE chats SET ephemeral_expiration = ? WHERE jid = ?`, nullIfZero(int64(seconds)), jid)
+	return err
+}
+
+// ChatEphemeral returns a chat's disappearing-message timer in seconds, or 0
+// when it is off or the chat isn't stored.
+func (d *DB) ChatEphemeral(jid string) (uint32, error) {
+	var seconds uint32
+	err := d.sql.QueryRow(`SELECT COALESCE(ephemeral_expiration,0) FROM chats WHERE jid = ?`, jid).Scan(&seconds)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return seconds, err
+}
+
+const contactColumns = `
+		c.jid,
+		COALESCE(c.phone,''),
+		COALESCE(NULLIF(a.alias,''), ''),
+		COALESCE(NULLIF(c.full_name,''), NULLIF(c.push_name,''), NULLIF(c.business_name,''), NULLIF(c.first_name,''), ''),
+		COALESCE(c.push_name,''),
+		COALESCE(c.full_name,''),
+		COALESCE(c.first_name,''),
+		COALESCE(c.business_name,''),
+		COALESCE(a.notes,''),
+		COALESCE(c.about,''),
+		EXISTS(SELECT 1 FROM blocklist b WHERE b.jid = c.jid),
+		c.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanContact(row rowScanner) (Contact, error) {
+	var c Contact
+	var updated int64
+	var blocked int
+	if err := row.Scan(&c.JID, &c.Phone, &c.Alias, &c.Name, &c.PushName, &c.FullName, &c.FirstName, &c.BusinessName, &c.Notes, &c.About, &blocked, &updated); err != nil {
+		return Contact{}, err
+	}
+	c.Blocked = blocked != 0
+	c.UpdatedAt = fromUnix(updated)
+	return c, nil
+}
+
+type SearchContactsParams struct {
+	Query string
+	Limit int
+	// BusinessOnly restricts results to business accounts. The query may be
+	// empty in that case to list all of them.
+	BusinessOnly bool
+}
+
+func (d *DB) SearchContacts(p SearchContactsParams) ([]Contact, error) {
+	if strings.TrimSpace(p.Query) == "" && !p.BusinessOnly {
 		return nil, fmt.Errorf("query is required")
 	}
-	if limit <= 0 {
-		limit = 50
+	if p.Limit <= 0 {
+		p.Limit = 50
 	}
 	q := `
-		SELECT c.jid,
-		       COALESCE(c.phone,''),
-		       COALESCE(NULLIF(a.alias,''), ''),
-		       COALESCE(NULLIF(c.full_name,''), NULLIF(c.push_name,''), NULLIF(c.business_name,''), NULLIF(c.first_name,''), ''),
-		       c.updated_at
+		SELECT ` + contactColumns + `
 		FROM contacts c
 		LEFT JOIN contact_aliases a ON a.jid = c.jid
-		WHERE LOWER(COALESCE(a.alias,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.full_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.push_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.phone,'')) LIKE LOWER(?) OR LOWER(c.jid) LIKE LOWER(?)
-		ORDER BY COALESCE(NULLIF(a.alias,''), NULLIF(c.full_name,''), NULLIF(c.push_name,''), c.jid)
+		WHERE 1=1`
+	var args []interface{}
+	if strings.TrimSpace(p.Query) != "" {
+		q += ` AND (LOWER(COALESCE(a.alias,'')) LIKE LOWER(?) OR LOWER(COALESCE(a.notes,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.full_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.push_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.business_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.phone,'')) LIKE LOWER(?) OR LOWER(c.jid) LIKE LOWER(?))`
+		needle := "%" + p.Query + "%"
+		args = append(args, needle, needle, needle, needle, needle, needle, needle)
+	}
+	if p.BusinessOnly {
+		q += ` AND (COALESCE(c.business_name,'') != '' OR COALESCE(c.is_business,0) = 1)`
+	}
+	q += `
+		ORDER BY COALESCE(NULLIF(a.alias,''), NULLIF(c.full_name,''), NULLIF(c.push_name,''), NULLIF(c.business_name,''), c.jid)
 		LIMIT ?`
-	needle := "%" + query + "%"
-	rows, err := d.sql.Query(q, needle, needle, needle, needle, needle, limit)
+	args = append(args, p.Limit)
+	rows, err := d.sql.Query(q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -92,12 +156,10 @@ func (d *DB) SearchContacts(query string, limit int) ([]Contact, error) {
 
 	var out []Contact
 	for rows.Next() {
-		var c Contact
-		var updated int64
-		if err := rows.Scan(&c.JID, &c.Phone, &c.Alias, &c.Name, &updated); err != nil {
+		c, err := scanContact(rows)
+		if err != nil {
 			return nil, err
 		}
-		c.UpdatedAt = fromUnix(updated)
 		out = append(out, c)
 	}
 	return out, rows.Err()
@@ -105,26 +167,96 @@ func (d *DB) SearchContacts(query string, limit int) ([]Contact, error) {
 
 func (d *DB) GetContact(jid string) (Contact, error) {
 	row := d.sql.QueryRow(`
-		SELECT c.jid,
-		       COALESCE(c.phone,''),
-		       COALESCE(NULLIF(a.alias,''), ''),
-		       COALESCE(NULLIF(c.full_name,''), NULLIF(c.push_name,''), NULLIF(c.business_name,''), NULLIF(c.first_name,''), ''),
-		       c.updated_at
+		SELECT `+contactColumns+`
 		FROM contacts c
 		LEFT JOIN contact_aliases a ON a.jid = c.jid
 		WHERE c.jid = ?
 	`, jid)
-	var c Contact
-	var updated int64
-	if err := row.Scan(&c.JID, &c.Phone, &c.Alias, &c.Name, &updated); err != nil {
+	c, err := scanContact(row)
+	if err != nil {
 		return Contact{}, err
 	}
-	c.UpdatedAt = fromUnix(updated)
 	tags, _ := d.ListTags(jid)
 	c.Tags = tags
+	if av, err := d.GetAvatar(jid); err == nil {
+		c.AvatarPath = av.Path
+	}
+	return c, nil
+}
+
+// ListContacts returns every known contact with its alias, notes and tags.
+func (d *DB) ListContacts() ([]Contact, error) {
+	rows, err := d.sql.Query(`
+		SELECT ` + contactColumns + `
+		FROM contacts c
+		LEFT JOIN contact_aliases a ON a.jid = c.jid
+		ORDER BY COALESCE(NULLIF(a.alias,''), NULLIF(c.full_name,''), NULLIF(c.push_name,''), c.jid)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Contact
+	for rows.Next() {
+		c, err := scanContact(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := d.allTags()
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Tags = tags[out[i].JID]
+	}
+	return out, nil
+}
+
+// FindContactByPhone returns the contact whose phone (digits only) matches,
+// preferring phone-number JIDs over LIDs.
+func (d *DB) FindContactByPhone(phone string) (Contact, error) {
+	row := d.sql.QueryRow(`
+		SELECT `+contactColumns+`
+		FROM contacts c
+		LEFT JOIN contact_aliases a ON a.jid = c.jid
+		WHERE c.phone = ? OR c.jid = ?
+		ORDER BY CASE WHEN c.jid LIKE '%@s.whatsapp.net' THEN 0 ELSE 1 END, c.updated_at DESC
+		LIMIT 1
+	`, phone, phone+"@s.whatsapp.net")
+	c, err := scanContact(row)
+	if err != nil {
+		return Contact{}, err
+	}
+	tags, _ := d.ListTags(c.JID)
+	c.Tags = tags
 	return c, nil
 }
 
+func (d *DB) allTags() (map[string][]string, error) {
+	rows, err := d.sql.Query(`SELECT jid, tag FROM contact_tags ORDER BY jid, tag`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string][]string{}
+	for rows.Next() {
+		var jid, tag string
+		if err := rows.Scan(&jid, &tag); err != nil {
+			return nil, err
+		}
+		out[jid] = append(out[jid], tag)
+	}
+	return out, rows.Err()
+}
+
 func (d *DB) ListTags(jid string) ([]string, error) {
 	rows, err := d.sql.Query(`SELECT tag FROM contact_tags WHERE jid = ? ORDER BY tag`, jid)
 	if err != nil {
@@ -143,6 +275,25 @@ func (d *DB) ListTags(jid string) ([]string, error) {
 	return tags, rows.Err()
 }
 
+// ContactsWithTag returns the JIDs of all contacts tagged with tag.
+func (d *DB) ContactsWithTag(tag string) ([]string, error) {
+	rows, err := d.sql.Query(`SELECT jid FROM contact_tags WHERE tag = ? ORDER BY jid`, strings.TrimSpace(tag))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jids []string
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			return nil, err
+		}
+		jids = append(jids, jid)
+	}
+	return jids, rows.Err()
+}
+
 func (d *DB) UpsertContact(jid, phone, pushName, fullName, firstName, businessName string) error {
 	now := time.Now().UTC().Unix()
 	_, err := d.sql.Exec(`
@@ -206,21 +357,81 @@ func (d *DB) ReplaceGroupParticipants(groupJID string, participants []GroupParti
 	return tx.Commit()
 }
 
+// ListGroupMembers returns the stored participants of a group joined with
+// local contact metadata (best name, alias and tags).
+func (d *DB) ListGroupMembers(groupJID string) ([]GroupMember, error) {
+	rows, err := d.sql.Query(`
+		SELECT gp.group_jid,
+		       gp.user_jid,
+		       COALESCE(gp.role,''),
+		       COALESCE(c.phone,''),
+		       COALESCE(NULLIF(c.full_name,''), NULLIF(c.push_name,''), NULLIF(c.business_name,''), NULLIF(c.first_name,''), ''),
+		       COALESCE(a.alias,''),
+		       gp.updated_at
+		FROM group_participants gp
+		LEFT JOIN contacts c ON c.jid = gp.user_jid
+		LEFT JOIN contact_aliases a ON a.jid = gp.user_jid
+		WHERE gp.group_jid = ?
+		ORDER BY CASE gp.role WHEN 'superadmin' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END,
+		         COALESCE(NULLIF(a.alias,''), NULLIF(c.full_name,''), NULLIF(c.push_name,''), gp.user_jid)
+	`, groupJID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []GroupMember
+	for rows.Next() {
+		var m GroupMember
+		var updated int64
+		if err := rows.Scan(&m.GroupJID, &m.UserJID, &m.Role, &m.Phone, &m.Name, &m.Alias, &updated); err != nil {
+			return nil, err
+		}
+		if m.Phone == "" {
+			// Only a phone-number JID carries the number; a LID's user part
+			// is an opaque ID.
+			if user, server, ok := strings.Cut(m.UserJID, "@"); ok && server == "s.whatsapp.net" {
+				m.Phone = user
+			}
+		}
+		m.UpdatedAt = fromUnix(updated)
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		tags, err := d.ListTags(out[i].UserJID)
+		if err != nil {
+			return nil, err
+		}
+		out[i].Tags = tags
+	}
+	return out, nil
+}
+
 func (d *DB) ListGroups(query string, limit int) ([]Group, error) {
 	if limit <= 0 {
 		limit = 50
 	}
-	q := `SELECT jid, COALESCE(name,''), COALESCE(owner_jid,''), COALESCE(created_ts,0), updated_at FROM groups WHERE 1=1`
+	q := `
+		SELECT g.jid, COALESCE(g.name,''), COALESCE(g.owner_jid,''), COALESCE(g.is_parent,0), COALESCE(g.parent_jid,''), COALESCE(p.name,''), COALESCE(g.created_ts,0), g.updated_at
+		FROM groups g
+		LEFT JOIN groups p ON p.jid = g.parent_jid
+		WHERE 1=1`
 	var args []interface{}
 	if strings.TrimSpace(query) != "" {
 		needle := "%" + query + "%"
-		q += ` AND (LOWER(name) LIKE LOWER(?) OR LOWER(jid) LIKE LOWER(?))`
+		q += ` AND (LOWER(g.name) LIKE LOWER(?) OR LOWER(g.jid) LIKE LOWER(?))`
 		args = append(args, needle, needle)
 	}
-	q += ` ORDER BY COALESCE(created_ts,0) DESC LIMIT ?`
+	q += ` ORDER BY COALESCE(g.created_ts,0) DESC LIMIT ?`
 	args = append(args, limit)
+	return d.scanGroups(q, args...)
+}
 
-	rows, err := d.sql.Query(q, args...)
+func (d *DB) scanGroups(query string, args ...interface{}) ([]Group, error) {
+	rows, err := d.sql.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -229,10 +440,12 @@ func (d *DB) ListGroups(query string, limit int) ([]Group, error) {
 	var out []Group
 	for rows.Next() {
 		var g Group
+		var isParent int
 		var created, updated int64
-		if err := rows.Scan(&g.JID, &g.Name, &g.OwnerJID, &created, &updated); err != nil {
+		if err := rows.Scan(&g.JID, &g.Name, &g.OwnerJID, &isParent, &g.ParentJID, &g.ParentName, &created, &updated); err != nil {
 			return nil, err
 		}
+		g.IsParent = isParent != 0
 		g.CreatedAt = fromUnix(created)
 		g.UpdatedAt = fromUnix(updated)
 		out = append(out, g)
@@ -254,8 +467,54 @@ func (d *DB) SetAlias(jid, alias string) error {
 	return err
 }
 
+// RemoveAlias clears the alias but keeps any notes stored for the contact.
 func (d *DB) RemoveAlias(jid string) error {
-	_, err := d.sql.Exec(`DELETE FROM contact_aliases WHERE jid = ?`, jid)
+	now := time.Now().UTC().Unix()
+	if _, err := d.sql.Exec(`UPDATE contact_aliases SET alias = '', updated_at = ? WHERE jid = ?`, now, jid); err != nil {
+		return err
+	}
+	return d.pruneContactAlias(jid)
+}
+
+func (d *DB) SetNotes(jid, notes string) error {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return fmt.Errorf("notes are required")
+	}
+	now := time.Now().UTC().Unix()
+	_, err := d.sql.Exec(`
+		INSERT INTO contact_aliases(jid, alias, notes, updated_at)
+		VALUES (?, '', ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET notes=excluded.notes, updated_at=excluded.updated_at
+	`, jid, notes, now)
+	return err
+}
+
+func (d *DB) GetNotes(jid string) (string, error) {
+	row := d.sql.QueryRow(`SELECT COALESCE(notes,'') FROM contact_aliases WHERE jid = ?`, jid)
+	var notes string
+	if err := row.Scan(&notes); err != nil {
+		if IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return notes, nil
+}
+
+// ClearNotes removes notes but keeps the contact's alias.
+func (d *DB) ClearNotes(jid string) error {
+	now := time.Now().UTC().Unix()
+	if _, err := d.sql.Exec(`UPDATE contact_aliases SET notes = NULL, updated_at = ? WHERE jid = ?`, now, jid); err != nil {
+		return err
+	}
+	return d.pruneContactAlias(jid)
+}
+
+// pruneContactAlias drops the contact_aliases row once neither alias nor
+// notes are left.
+func (d *DB) pruneContactAlias(jid string) error {
+	_, err := d.sql.Exec(`DELETE FROM contact_aliases WHERE jid = ? AND COALESCE(alias,'') = '' AND COALESCE(notes,'') = ''`, jid)
 	return err
 }
 
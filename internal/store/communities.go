@@ -0,0 +1,103 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetGroupCommunity records whether a group is a community parent and which
+// community (if any) it is linked to. Values come from live group info and
+// overwrite whatever was stored before.
+func (d *DB) SetGroupCommunity(jid string, isParent bool, parentJID string) error {
+	now := time.Now().UTC().Unix()
+	_, err := d.sql.Exec(`
+		INSERT INTO groups(jid, is_parent, parent_jid, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET
+			is_parent=excluded.is_parent,
+			parent_jid=excluded.parent_jid,
+			updated_at=excluded.updated_at
+	`, jid, boolToInt(isParent), nullIfEmpty(parentJID), now)
+	return err
+}
+
+// SetGroupParent links a group to a community parent (or unlinks it when
+// parentJID is empty) without touching its other metadata.
+func (d *DB) SetGroupParent(jid, parentJID string) error {
+	if strings.TrimSpace(jid) == "" {
+		return fmt.Errorf("group JID is required")
+	}
+	now := time.Now().UTC().Unix()
+	_, err := d.sql.Exec(`
+		INSERT INTO groups(jid, parent_jid, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET parent_jid=excluded.parent_jid, updated_at=excluded.updated_at
+	`, jid, nullIfEmpty(parentJID), now)
+	return err
+}
+
+func (d *DB) ListCommunities(query string, limit int) ([]Community, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	q := `
+		SELECT p.jid, COALESCE(p.name,''), COALESCE(p.owner_jid,''),
+		       (SELECT COUNT(1) FROM groups s WHERE s.parent_jid = p.jid),
+		       COALESCE(p.created_ts,0)
+		FROM groups p
+		WHERE (p.is_parent = 1 OR EXISTS (SELECT 1 FROM groups s WHERE s.parent_jid = p.jid))`
+	var args []interface{}
+	if strings.TrimSpace(query) != "" {
+		needle := "%" + query + "%"
+		q += ` AND (LOWER(p.name) LIKE LOWER(?) OR LOWER(p.jid) LIKE LOWER(?))`
+		args = append(args, needle, needle)
+	}
+	q += ` ORDER BY COALESCE(p.name, p.jid) LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := d.sql.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Community
+	for rows.Next() {
+		var c Community
+		var created int64
+		if err := rows.Scan(&c.JID, &c.Name, &c.OwnerJID, &c.SubGroups, &created); err != nil {
+			return nil, err
+		}
+		c.CreatedAt = fromUnix(created)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (d *DB) GetGroup(jid string) (Group, error) {
+	gs, err := d.scanGroups(`
+		SELECT g.jid, COALESCE(g.name,''), COALESCE(g.owner_jid,''), COALESCE(g.is_parent,0), COALESCE(g.parent_jid,''), COALESCE(p.name,''), COALESCE(g.created_ts,0), g.updated_at
+		FROM groups g
+		LEFT JOIN groups p ON p.jid = g.parent_jid
+		WHERE g.jid = ?
+	`, jid)
+	if err != nil {
+		return Group{}, err
+	}
+	if len(gs) == 0 {
+		return Group{}, sql.ErrNoRows
+	}
+	return gs[0], nil
+}
+
+func (d *DB) ListSubGroups(parentJID string) ([]Group, error) {
+	return d.scanGroups(`
+		SELECT g.jid, COALESCE(g.name,''), COALESCE(g.owner_jid,''), COALESCE(g.is_parent,0), COALESCE(g.parent_jid,''), COALESCE(p.name,''), COALESCE(g.created_ts,0), g.updated_at
+		FROM groups g
+		LEFT JOIN groups p ON p.jid = g.parent_jid
+		WHERE g.parent_jid = ?
+		ORDER BY COALESCE(g.name, g.jid)
+	`, parentJID)
+}
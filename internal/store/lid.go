@@ -0,0 +1,199 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rows keyed by a LID ("…@lid") are rewritten to the phone-number JID once the
+// mapping is known, so one person is stored under a single JID.
+
+// GetPNForLID returns the phone-number JID mapped to lid, or "" if unknown.
+func (d *DB) GetPNForLID(lid string) (string, error) {
+	row := d.sql.QueryRow(`SELECT pn FROM lid_map WHERE lid = ?`, lid)
+	var pn string
+	if err := row.Scan(&pn); err != nil {
+		if IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return pn, nil
+}
+
+// ResolveJID maps a LID to its phone-number JID when known and returns every
+// other JID unchanged. Device suffixes on LIDs are dropped.
+func (d *DB) ResolveJID(jid string) string {
+	lid := lidBase(jid)
+	if lid == "" {
+		return jid
+	}
+	if pn, err := d.GetPNForLID(lid); err == nil && pn != "" {
+		return pn
+	}
+	return jid
+}
+
+// SaveLIDMapping records lid → pn and rewrites rows stored under the LID.
+func (d *DB) SaveLIDMapping(lid, pn string) (err error) {
+	lid = lidBase(lid)
+	if lid == "" || strings.TrimSpace(pn) == "" {
+		return fmt.Errorf("lid and phone-number JID are required")
+	}
+
+	tx, err := d.sql.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.Exec(`
+		INSERT INTO lid_map(lid, pn, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(lid) DO UPDATE SET pn=excluded.pn, updated_at=excluded.updated_at
+	`, lid, pn, time.Now().UTC().Unix()); err != nil {
+		return err
+	}
+	if err = rewriteLID(tx, lid, pn); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListUnmappedLIDs returns LIDs referenced by stored rows that have no known
+// phone-number JID yet.
+func (d *DB) ListUnmappedLIDs() ([]string, error) {
+	rows, err := d.sql.Query(`
+		SELECT jid FROM chats WHERE jid LIKE '%@lid'
+		UNION SELECT sender_jid FROM messages WHERE sender_jid LIKE '%@lid'
+		UNION SELECT jid FROM contacts WHERE jid LIKE '%@lid'
+		UNION SELECT user_jid FROM group_participants WHERE user_jid LIKE '%@lid'
+		UNION SELECT peer_jid FROM calls WHERE peer_jid LIKE '%@lid'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := map[string]bool{}
+	var out []string
+	for rows.Next() {
+		var jid string
+		if err := rows.Scan(&jid); err != nil {
+			return nil, err
+		}
+		lid := lidBase(jid)
+		if lid == "" || seen[lid] {
+			continue
+		}
+		seen[lid] = true
+		out = append(out, lid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var unmapped []string
+	for _, lid := range out {
+		pn, err := d.GetPNForLID(lid)
+		if err != nil {
+			return nil, err
+		}
+		if pn == "" {
+			unmapped = append(unmapped, lid)
+		}
+	}
+	return unmapped, nil
+}
+
+func rewriteLID(tx *sql.Tx, lid, pn string) error {
+	user := strings.TrimSuffix(lid, "@lid")
+	phone := strings.SplitN(pn, "@", 2)[0]
+	stmts := []struct {
+		q    string
+		args []interface{}
+	}{
+		// Chats: make sure the phone-number chat exists, move messages, then
+		// drop the LID chat (which cascades to duplicate messages left behind).
+		{`INSERT INTO chats(jid, kind, name, last_message_ts)
+			SELECT ?, 'dm', name, last_message_ts FROM chats WHERE jid = ?
+			ON CONFLICT(jid) DO UPDATE SET
+				name=COALESCE(NULLIF(chats.name,''), excluded.name),
+				last_message_ts=MAX(COALESCE(chats.last_message_ts,0), COALESCE(excluded.last_message_ts,0))`, []interface{}{pn, lid}},
+		{`UPDATE OR IGNORE messages SET chat_jid = ? WHERE chat_jid = ?`, []interface{}{pn, lid}},
+		{`DELETE FROM chats WHERE jid = ?`, []interface{}{lid}},
+		{`UPDATE messages SET sender_jid = ? WHERE sender_jid = ? OR sender_jid LIKE ?`, []interface{}{pn, lid, user + ":%@lid"}},
+
+		// Contacts: merge the LID row into the phone-number row, keeping what
+		// the phone-number row already has.
+		{`INSERT INTO contacts(jid, phone, push_name, full_name, first_name, business_name, is_business, about, about_at, updated_at)
+			SELECT ?, ?, push_name, full_name, first_name, business_name, is_business, about, about_at, updated_at FROM contacts WHERE jid = ?
+			ON CONFLICT(jid) DO UPDATE SET
+				phone=COALESCE(NULLIF(contacts.phone,''), excluded.phone),
+				push_name=COALESCE(NULLIF(contacts.push_name,''), excluded.push_name),
+				full_name=COALESCE(NULLIF(contacts.full_name,''), excluded.full_name),
+				first_name=COALESCE(NULLIF(contacts.first_name,''), excluded.first_name),
+				business_name=COALESCE(NULLIF(contacts.business_name,''), excluded.business_name),
+				is_business=MAX(contacts.is_business, excluded.is_business),
+				about_at=CASE WHEN NULLIF(contacts.about,'') IS NULL THEN excluded.about_at ELSE contacts.about_at END,
+				about=COALESCE(NULLIF(contacts.about,''), excluded.about)`, []interface{}{pn, phone, lid}},
+		{`DELETE FROM contacts WHERE jid = ?`, []interface{}{lid}},
+
+		// Local data: merge into the phone-number row before dropping the LID
+		// row, so an alias, notes or tags set on either survive.
+		{`INSERT INTO contact_aliases(jid, alias, notes, updated_at)
+			SELECT ?, alias, notes, updated_at FROM contact_aliases WHERE jid = ?
+			ON CONFLICT(jid) DO UPDATE SET
+				alias=COALESCE(NULLIF(contact_aliases.alias,''), excluded.alias),
+				notes=COALESCE(NULLIF(contact_aliases.notes,''), excluded.notes),
+				updated_at=MAX(contact_aliases.updated_at, excluded.updated_at)`, []interface{}{pn, lid}},
+		{`DELETE FROM contact_aliases WHERE jid = ?`, []interface{}{lid}},
+		{`INSERT OR IGNORE INTO contact_tags(jid, tag, updated_at)
+			SELECT ?, tag, updated_at FROM contact_tags WHERE jid = ?`, []interface{}{pn, lid}},
+		{`DELETE FROM contact_tags WHERE jid = ?`, []interface{}{lid}},
+		{`INSERT OR IGNORE INTO group_participants(group_jid, user_jid, role, updated_at)
+			SELECT group_jid, ?, role, updated_at FROM group_participants WHERE user_jid = ?`, []interface{}{pn, lid}},
+		{`DELETE FROM group_participants WHERE user_jid = ?`, []interface{}{lid}},
+		{`INSERT INTO blocklist(jid, blocked_at)
+			SELECT ?, blocked_at FROM blocklist WHERE jid = ?
+			ON CONFLICT(jid) DO UPDATE SET blocked_at=MIN(blocklist.blocked_at, excluded.blocked_at)`, []interface{}{pn, lid}},
+		{`DELETE FROM blocklist WHERE jid = ?`, []interface{}{lid}},
+		// Avatars: keep whichever picture was fetched last.
+		{`INSERT INTO avatars(jid, picture_id, path, updated_at)
+			SELECT ?, picture_id, path, updated_at FROM avatars WHERE jid = ?
+			ON CONFLICT(jid) DO UPDATE SET
+				picture_id=CASE WHEN excluded.updated_at > avatars.updated_at THEN excluded.picture_id ELSE avatars.picture_id END,
+				path=CASE WHEN excluded.updated_at > avatars.updated_at THEN excluded.path ELSE avatars.path END,
+				updated_at=MAX(avatars.updated_at, excluded.updated_at)`, []interface{}{pn, lid}},
+		{`DELETE FROM avatars WHERE jid = ?`, []interface{}{lid}},
+		{`INSERT OR IGNORE INTO broadcast_members(list_jid, member_jid, updated_at)
+			SELECT list_jid, ?, updated_at FROM broadcast_members WHERE member_jid = ?`, []interface{}{pn, lid}},
+		{`DELETE FROM broadcast_members WHERE member_jid = ?`, []interface{}{lid}},
+		{`UPDATE calls SET peer_jid = ? WHERE peer_jid = ?`, []interface{}{pn, lid}},
+	}
+	for _, st := range stmts {
+		if _, err := tx.Exec(st.q, st.args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lidBase returns "user@lid" for a LID (with or without device suffix) and ""
+// for anything else.
+func lidBase(jid string) string {
+	jid = strings.TrimSpace(jid)
+	user, server, ok := strings.Cut(jid, "@")
+	if !ok || server != "lid" || user == "" {
+		return ""
+	}
+	if i := strings.IndexByte(user, ':'); i >= 0 {
+		user = user[:i]
+	}
+	return user + "@lid"
+}
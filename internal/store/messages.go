@@ -1,6 +1,8 @@
 package store
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -25,6 +27,24 @@ type UpsertMessageParams struct {
 	FileSHA256    []byte
 	FileEncSHA256 []byte
 	FileLength    uint64
+	// DurationSeconds and PTT describe audio/video; PTT marks voice notes.
+	DurationSeconds uint32
+	PTT             bool
+	// Width and Height are the pixel size of images and videos.
+	Width  int
+	Height int
+	// Link preview attached to a text message.
+	LinkURL         string
+	LinkTitle       string
+	LinkDescription string
+	// Forwarded marks messages forwarded from another chat; ForwardingScore
+	// counts how many times (WhatsApp shows "forwarded many times" from 5).
+	Forwarded       bool
+	ForwardingScore uint32
+	// EphemeralExpiration is the disappearing-message timer (seconds) the
+	// message was sent with; ViewOnce marks media that opens only once.
+	EphemeralExpiration uint32
+	ViewOnce            bool
 }
 
 func (d *DB) UpsertMessage(p UpsertMessageParams) error {
@@ -32,8 +52,10 @@ func (d *DB) UpsertMessage(p UpsertMessageParams) error {
 		INSERT INTO messages(
 			chat_jid, chat_name, msg_id, sender_jid, sender_name, ts, from_me, text, display_text,
 			media_type, media_caption, filename, mime_type, direct_path,
-			media_key, file_sha256, file_enc_sha256, file_length
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			media_key, file_sha256, file_enc_sha256, file_length, duration_seconds, is_ptt,
+			width, height, link_url, link_title, link_description, is_forwarded, forwarding_score,
+			ephemeral_expiration, is_view_once
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(chat_jid, msg_id) DO UPDATE SET
 			chat_name=COALESCE(NULLIF(excluded.chat_name,''), messages.chat_name),
 			sender_jid=excluded.sender_jid,
@@ -50,27 +72,100 @@ func (d *DB) UpsertMessage(p UpsertMessageParams) error {
 			media_key=CASE WHEN excluded.media_key IS NOT NULL AND length(excluded.media_key)>0 THEN excluded.media_key ELSE messages.media_key END,
 			file_sha256=CASE WHEN excluded.file_sha256 IS NOT NULL AND length(excluded.file_sha256)>0 THEN excluded.file_sha256 ELSE messages.file_sha256 END,
 			file_enc_sha256=CASE WHEN excluded.file_enc_sha256 IS NOT NULL AND length(excluded.file_enc_sha256)>0 THEN excluded.file_enc_sha256 ELSE messages.file_enc_sha256 END,
-			file_length=CASE WHEN excluded.file_length>0 THEN excluded.file_length ELSE messages.file_length END
+			file_length=CASE WHEN excluded.file_length>0 THEN excluded.file_length ELSE messages.file_length END,
+			duration_seconds=COALESCE(excluded.duration_seconds, messages.duration_seconds),
+			is_ptt=MAX(excluded.is_ptt, messages.is_ptt),
+			width=COALESCE(excluded.width, messages.width),
+			height=COALESCE(excluded.height, messages.height),
+			link_url=COALESCE(excluded.link_url, messages.link_url),
+			link_title=COALESCE(excluded.link_title, messages.link_title),
+			link_description=COALESCE(excluded.link_description, messages.link_description),
+			is_forwarded=MAX(excluded.is_forwarded, messages.is_forwarded),
+			forwarding_score=COALESCE(excluded.forwarding_score, messages.forwarding_score),
+			ephemeral_expiration=COALESCE(excluded.ephemeral_expiration, messages.ephemeral_expiration),
+			is_view_once=MAX(excluded.is_view_once, messages.is_view_once)
 	`, p.ChatJID, nullIfEmpty(p.ChatName), p.MsgID, nullIfEmpty(p.SenderJID), nullIfEmpty(p.SenderName), unix(p.Timestamp), boolToInt(p.FromMe), nullIfEmpty(p.Text), nullIfEmpty(p.DisplayText),
 		nullIfEmpty(p.MediaType), nullIfEmpty(p.MediaCaption), nullIfEmpty(p.Filename), nullIfEmpty(p.MimeType), nullIfEmpty(p.DirectPath),
-		p.MediaKey, p.FileSHA256, p.FileEncSHA256, int64(p.FileLength),
+		p.MediaKey, p.FileSHA256, p.FileEncSHA256, int64(p.FileLength), nullIfZero(int64(p.DurationSeconds)), boolToInt(p.PTT),
+		nullIfZero(int64(p.Width)), nullIfZero(int64(p.Height)), nullIfEmpty(p.LinkURL), nullIfEmpty(p.LinkTitle), nullIfEmpty(p.LinkDescription),
+		boolToInt(p.Forwarded), nullIfZero(int64(p.ForwardingScore)),
+		nullIfZero(int64(p.EphemeralExpiration)), boolToInt(p.ViewOnce),
 	)
 	return err
 }
 
+// ErrNotOriginalSender is returned by EditMessage for an edit sent by someone
+// other than the author of the message it names.
+var ErrNotOriginalSender = errors.New("edit is not from the original sender")
+
+// EditMessage replaces the text of a stored message with an edited version
+// from senderJID (or from us). It reports false when the message isn't
+// stored; only the message's own sender can edit it.
+func (d *DB) EditMessage(chatJID, msgID, senderJID string, fromMe bool, text string, editedAt time.Time) (bool, error) {
+	var origSender string
+	var origFromMe bool
+	err := d.sql.QueryRow(`SELECT COALESCE(sender_jid,''), from_me FROM messages WHERE chat_jid = ? AND msg_id = ?`, chatJID, msgID).
+		Scan(&origSender, &origFromMe)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// Either side may be a LID; compare the phone-number JIDs when known.
+	if fromMe != origFromMe || (!fromMe && origSender != "" && jidUser(d.ResolveJID(origSender)) != jidUser(d.ResolveJID(senderJID))) {
+		return true, ErrNotOriginalSender
+	}
+
+	// The display text follows the edit unless it was derived (media label,
+	// reply quote).
+	_, err = d.sql.Exec(`
+		UPDATE messages SET
+			display_text = CASE WHEN display_text IS text THEN ? ELSE display_text END,
+			media_caption = CASE WHEN media_type IS NOT NULL THEN ? ELSE media_caption END,
+			text = ?,
+			edited_at = ?
+		WHERE chat_jid = ? AND msg_id = ?
+	`, nullIfEmpty(text), nullIfEmpty(text), nullIfEmpty(text), unix(editedAt), chatJID, msgID)
+	return true, err
+}
+
+// jidUser drops the device part of a JID ("123:4@s.whatsapp.net" becomes
+// "123@s.whatsapp.net"), since a sender may edit from another device.
+func jidUser(jid string) string {
+	user, server, ok := strings.Cut(strings.TrimSpace(jid), "@")
+	if !ok {
+		return jid
+	}
+	if i := strings.IndexByte(user, ':'); i >= 0 {
+		user = user[:i]
+	}
+	return user + "@" + server
+}
+
+// StatusJID is the chat status updates (stories) are stored in. They are left
+// out of chat and message listings unless asked for by chat.
+const StatusJID = "status@broadcast"
+
 type ListMessagesParams struct {
 	ChatJID string
-	Limit   int
-	Before  *time.Time
-	After   *time.Time
+	From    string // sender JID
+	Type    string // media type, or "voice" for voice notes
+	// ExcludeForwarded drops messages forwarded from other chats.
+	ExcludeForwarded bool
+	Limit            int
+	Before           *time.Time
+	After            *time.Time
 }
 
 func (d *DB) ListMessages(p ListMessagesParams) ([]Message, error) {
+	p.ChatJID = d.ResolveJID(p.ChatJID)
+	p.From = d.ResolveJID(p.From)
 	if p.Limit <= 0 {
 		p.Limit = 50
 	}
 	query := `
-		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), ''
+		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), '', m.is_forwarded
 		FROM messages m
 		LEFT JOIN chats c ON c.jid = m.chat_jid
 		WHERE 1=1`
@@ -78,6 +173,13 @@ func (d *DB) ListMessages(p ListMessagesParams) ([]Message, error) {
 	if strings.TrimSpace(p.ChatJID) != "" {
 		query += " AND m.chat_jid = ?"
 		args = append(args, p.ChatJID)
+	} else {
+		query += " AND m.chat_jid != ?"
+		args = append(args, StatusJID)
+	}
+	if strings.TrimSpace(p.From) != "" {
+		query += " AND m.sender_jid = ?"
+		args = append(args, p.From)
 	}
 	if p.After != nil {
 		query += " AND m.ts > ?"
@@ -87,26 +189,56 @@ func (d *DB) ListMessages(p ListMessagesParams) ([]Message, error) {
 		query += " AND m.ts < ?"
 		args = append(args, unix(*p.Before))
 	}
+	if strings.TrimSpace(p.Type) != "" {
+		cond, typeArgs := mediaTypeFilter(p.Type)
+		query += " AND " + cond
+		args = append(args, typeArgs...)
+	}
+	if p.ExcludeForwarded {
+		query += " AND m.is_forwarded = 0"
+	}
 	query += " ORDER BY m.ts DESC LIMIT ?"
 	args = append(args, p.Limit)
 	return d.scanMessages(query, args...)
 }
 
+// mediaTypeFilter matches messages of a media type. "voice" selects
+// push-to-talk audio; "audio" keeps matching voice notes too.
+func mediaTypeFilter(t string) (string, []interface{}) {
+	if strings.TrimSpace(t) == "voice" {
+		return "(COALESCE(m.media_type,'') = 'audio' AND m.is_ptt = 1)", nil
+	}
+	return "COALESCE(m.media_type,'') = ?", []interface{}{t}
+}
+
 func (d *DB) GetMessage(chatJID, msgID string) (Message, error) {
+	chatJID = d.ResolveJID(chatJID)
 	row := d.sql.QueryRow(`
-		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), ''
+		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), '', m.is_forwarded,
+			COALESCE(m.forwarding_score,0), COALESCE(m.duration_seconds,0), m.is_ptt, COALESCE(m.width,0), COALESCE(m.height,0),
+			COALESCE(m.link_url,''), COALESCE(m.link_title,''), COALESCE(m.link_description,''),
+			COALESCE(m.ephemeral_expiration,0), m.is_view_once, COALESCE(m.edited_at,0)
 		FROM messages m
 		LEFT JOIN chats c ON c.jid = m.chat_jid
 		WHERE m.chat_jid = ? AND m.msg_id = ?
 	`, chatJID, msgID)
 	var m Message
 	var ts int64
-	var fromMe int
-	if err := row.Scan(&m.ChatJID, &m.ChatName, &m.MsgID, &m.SenderJID, &ts, &fromMe, &m.Text, &m.DisplayText, &m.MediaType, &m.Snippet); err != nil {
+	var fromMe, forwarded, ptt, viewOnce int
+	var editedAt int64
+	if err := row.Scan(&m.ChatJID, &m.ChatName, &m.MsgID, &m.SenderJID, &ts, &fromMe, &m.Text, &m.DisplayText, &m.MediaType, &m.Snippet, &forwarded,
+		&m.ForwardingScore, &m.DurationSeconds, &ptt, &m.Width, &m.Height, &m.LinkURL, &m.LinkTitle, &m.LinkDescription,
+		&m.EphemeralExpiration, &viewOnce, &editedAt); err != nil {
 		return Message{}, err
 	}
+	m.ViewOnce = viewOnce != 0
+	if editedAt > 0 {
+		m.EditedAt = fromUnix(editedAt)
+	}
 	m.Timestamp = fromUnix(ts)
 	m.FromMe = fromMe != 0
+	m.PTT = ptt != 0
+	m.Forwarded = forwarded != 0
 	return m, nil
 }
 
@@ -143,6 +275,7 @@ func (d *DB) GetOldestMessageInfo(chatJID string) (MessageInfo, error) {
 }
 
 func (d *DB) MessageContext(chatJID, msgID string, before, after int) ([]Message, error) {
+	chatJID = d.ResolveJID(chatJID)
 	if before < 0 {
 		before = 0
 	}
@@ -155,7 +288,7 @@ func (d *DB) MessageContext(chatJID, msgID string, before, after int) ([]Message
 	}
 
 	beforeRows, err := d.scanMessages(`
-		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), ''
+		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), '', m.is_forwarded
 		FROM messages m
 		LEFT JOIN chats c ON c.jid = m.chat_jid
 		WHERE m.chat_jid = ? AND m.ts < ?
@@ -167,7 +300,7 @@ func (d *DB) MessageContext(chatJID, msgID string, before, after int) ([]Message
 	}
 
 	afterRows, err := d.scanMessages(`
-		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), ''
+		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), '', m.is_forwarded
 		FROM messages m
 		LEFT JOIN chats c ON c.jid = m.chat_jid
 		WHERE m.chat_jid = ? AND m.ts > ?
@@ -201,12 +334,13 @@ func (d *DB) scanMessages(query string, args ...interface{}) ([]Message, error)
 	for rows.Next() {
 		var m Message
 		var ts int64
-		var fromMe int
-		if err := rows.Scan(&m.ChatJID, &m.ChatName, &m.MsgID, &m.SenderJID, &ts, &fromMe, &m.Text, &m.DisplayText, &m.MediaType, &m.Snippet); err != nil {
+		var fromMe, forwarded int
+		if err := rows.Scan(&m.ChatJID, &m.ChatName, &m.MsgID, &m.SenderJID, &ts, &fromMe, &m.Text, &m.DisplayText, &m.MediaType, &m.Snippet, &forwarded); err != nil {
 			return nil, err
 		}
 		m.Timestamp = fromUnix(ts)
 		m.FromMe = fromMe != 0
+		m.Forwarded = forwarded != 0
 		out = append(out, m)
 	}
 	return out, rows.Err()
@@ -18,6 +18,22 @@ var schemaMigrations = []migration{
 	{version: 1, name: "core schema", up: migrateCoreSchema},
 	{version: 2, name: "messages display_text column", up: migrateMessagesDisplayText},
 	{version: 3, name: "messages fts", up: migrateMessagesFTS},
+	{version: 4, name: "groups community columns", up: migrateGroupsCommunity},
+	{version: 5, name: "number checks", up: migrateNumberChecks},
+	{version: 6, name: "avatars and contact about", up: migrateAvatarsAndAbout},
+	{version: 7, name: "blocklist", up: migrateBlocklist},
+	{version: 8, name: "lid to phone number map", up: migrateLIDMap},
+	{version: 9, name: "business profiles", up: migrateBusinessProfiles},
+	{version: 10, name: "outbox", up: migrateOutbox},
+	{version: 11, name: "send idempotency keys", up: migrateSendKeys},
+	{version: 12, name: "messages duration and ptt columns", up: migrateMessagesDurationPTT},
+	{version: 13, name: "messages width and height columns", up: migrateMessagesDimensions},
+	{version: 14, name: "messages link preview columns", up: migrateMessagesLinkPreview},
+	{version: 15, name: "messages forwarded columns", up: migrateMessagesForwarded},
+	{version: 16, name: "status chat kind", up: migrateChatsStatusKind},
+	{version: 17, name: "disappearing, view-once and edit columns", up: migrateEphemeralColumns},
+	{version: 18, name: "calls", up: migrateCalls},
+	{version: 19, name: "broadcast list members", up: migrateBroadcastMembers},
 }
 
 func (d *DB) ensureSchema() error {
@@ -250,6 +266,376 @@ func migrateMessagesFTS(d *DB) error {
 	return nil
 }
 
+func migrateGroupsCommunity(d *DB) error {
+	hasParent, err := d.tableHasColumn("groups", "is_parent")
+	if err != nil {
+		return err
+	}
+	if !hasParent {
+		if _, err := d.sql.Exec(`ALTER TABLE groups ADD COLUMN is_parent INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add is_parent column: %w", err)
+		}
+	}
+	hasParentJID, err := d.tableHasColumn("groups", "parent_jid")
+	if err != nil {
+		return err
+	}
+	if !hasParentJID {
+		if _, err := d.sql.Exec(`ALTER TABLE groups ADD COLUMN parent_jid TEXT`); err != nil {
+			return fmt.Errorf("add parent_jid column: %w", err)
+		}
+	}
+	if _, err := d.sql.Exec(`CREATE INDEX IF NOT EXISTS idx_groups_parent ON groups(parent_jid)`); err != nil {
+		return fmt.Errorf("create groups parent index: %w", err)
+	}
+	return nil
+}
+
+// migrateNumberChecks adds the cache of "is on WhatsApp" lookups. Checks are
+// kept apart from contacts so a number that isn't on WhatsApp doesn't become a
+// contact; registered numbers are also upserted into contacts, with
+// is_business set when WhatsApp returned a verified business name.
+func migrateNumberChecks(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS number_checks (
+			phone TEXT PRIMARY KEY,
+			jid TEXT NOT NULL,
+			registered INTEGER NOT NULL DEFAULT 0,
+			is_business INTEGER NOT NULL DEFAULT 0,
+			business_name TEXT,
+			checked_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create number_checks table: %w", err)
+	}
+	has, err := d.tableHasColumn("contacts", "is_business")
+	if err != nil {
+		return err
+	}
+	if !has {
+		if _, err := d.sql.Exec(`ALTER TABLE contacts ADD COLUMN is_business INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add is_business column: %w", err)
+		}
+	}
+	if _, err := d.sql.Exec(`CREATE INDEX IF NOT EXISTS idx_contacts_phone ON contacts(phone)`); err != nil {
+		return fmt.Errorf("create contacts phone index: %w", err)
+	}
+	return nil
+}
+
+func migrateAvatarsAndAbout(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS avatars (
+			jid TEXT PRIMARY KEY,
+			picture_id TEXT NOT NULL,
+			path TEXT NOT NULL,
+			updated_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create avatars table: %w", err)
+	}
+	for _, col := range []struct{ name, ddl string }{
+		{"about", `ALTER TABLE contacts ADD COLUMN about TEXT`},
+		{"about_at", `ALTER TABLE contacts ADD COLUMN about_at INTEGER`},
+	} {
+		has, err := d.tableHasColumn("contacts", col.name)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+		if _, err := d.sql.Exec(col.ddl); err != nil {
+			return fmt.Errorf("add %s column: %w", col.name, err)
+		}
+	}
+	return nil
+}
+
+func migrateBlocklist(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS blocklist (
+			jid TEXT PRIMARY KEY,
+			blocked_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create blocklist table: %w", err)
+	}
+	return nil
+}
+
+func migrateLIDMap(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS lid_map (
+			lid TEXT PRIMARY KEY,
+			pn TEXT NOT NULL,
+			updated_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create lid_map table: %w", err)
+	}
+	if _, err := d.sql.Exec(`CREATE INDEX IF NOT EXISTS idx_messages_sender ON messages(sender_jid)`); err != nil {
+		return fmt.Errorf("create messages sender index: %w", err)
+	}
+	return nil
+}
+
+func migrateBusinessProfiles(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS business_profiles (
+			jid TEXT PRIMARY KEY,
+			description TEXT,
+			address TEXT,
+			email TEXT,
+			website TEXT,
+			categories TEXT,
+			hours_tz TEXT,
+			hours TEXT,
+			fetched_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create business_profiles table: %w", err)
+	}
+	return nil
+}
+
+func migrateOutbox(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS outbox (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			chat_jid TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			body TEXT,
+			file_path TEXT,
+			filename TEXT,
+			mime_type TEXT,
+			msg_id TEXT,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			send_at INTEGER NOT NULL,
+			next_attempt_at INTEGER NOT NULL,
+			last_error TEXT,
+			created_at INTEGER NOT NULL,
+			sent_at INTEGER
+		)
+	`); err != nil {
+		return fmt.Errorf("create outbox table: %w", err)
+	}
+	if _, err := d.sql.Exec(`CREATE INDEX IF NOT EXISTS idx_outbox_due ON outbox(status, next_attempt_at)`); err != nil {
+		return fmt.Errorf("create outbox due index: %w", err)
+	}
+	return nil
+}
+
+func migrateSendKeys(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS send_keys (
+			key TEXT PRIMARY KEY,
+			chat_jid TEXT NOT NULL,
+			msg_id TEXT NOT NULL,
+			result TEXT,
+			created_at INTEGER NOT NULL,
+			sent_at INTEGER
+		)
+	`); err != nil {
+		return fmt.Errorf("create send_keys table: %w", err)
+	}
+	return nil
+}
+
+func migrateMessagesDurationPTT(d *DB) error {
+	for _, col := range []struct{ name, ddl string }{
+		{"duration_seconds", `ALTER TABLE messages ADD COLUMN duration_seconds INTEGER`},
+		{"is_ptt", `ALTER TABLE messages ADD COLUMN is_ptt INTEGER NOT NULL DEFAULT 0`},
+	} {
+		has, err := d.tableHasColumn("messages", col.name)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+		if _, err := d.sql.Exec(col.ddl); err != nil {
+			return fmt.Errorf("add %s column: %w", col.name, err)
+		}
+	}
+	return nil
+}
+
+func migrateMessagesDimensions(d *DB) error {
+	for _, col := range []string{"width", "height"} {
+		has, err := d.tableHasColumn("messages", col)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+		if _, err := d.sql.Exec(`ALTER TABLE messages ADD COLUMN ` + col + ` INTEGER`); err != nil {
+			return fmt.Errorf("add %s column: %w", col, err)
+		}
+	}
+	return nil
+}
+
+// migrateMessagesLinkPreview adds link preview columns and rebuilds
+// messages_fts with link_title and link_description so previews are
+// searchable.
+func migrateMessagesLinkPreview(d *DB) error {
+	for _, col := range []string{"link_url", "link_title", "link_description"} {
+		has, err := d.tableHasColumn("messages", col)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+		if _, err := d.sql.Exec(`ALTER TABLE messages ADD COLUMN ` + col + ` TEXT`); err != nil {
+			return fmt.Errorf("add %s column: %w", col, err)
+		}
+	}
+
+	ftsExists, err := d.tableExists("messages_fts")
+	if err != nil || !ftsExists {
+		return err
+	}
+	if _, err := d.sql.Exec(`
+		DROP TRIGGER IF EXISTS messages_ai;
+		DROP TRIGGER IF EXISTS messages_ad;
+		DROP TRIGGER IF EXISTS messages_au;
+		DROP TABLE IF EXISTS messages_fts;
+
+		CREATE VIRTUAL TABLE messages_fts USING fts5(
+			text,
+			media_caption,
+			filename,
+			chat_name,
+			sender_name,
+			display_text,
+			link_title,
+			link_description
+		);
+
+		CREATE TRIGGER messages_ai AFTER INSERT ON messages BEGIN
+			INSERT INTO messages_fts(rowid, text, media_caption, filename, chat_name, sender_name, display_text, link_title, link_description)
+			VALUES (new.rowid, COALESCE(new.text,''), COALESCE(new.media_caption,''), COALESCE(new.filename,''), COALESCE(new.chat_name,''), COALESCE(new.sender_name,''), COALESCE(new.display_text,''), COALESCE(new.link_title,''), COALESCE(new.link_description,''));
+		END;
+
+		CREATE TRIGGER messages_ad AFTER DELETE ON messages BEGIN
+			DELETE FROM messages_fts WHERE rowid = old.rowid;
+		END;
+
+		CREATE TRIGGER messages_au AFTER UPDATE ON messages BEGIN
+			DELETE FROM messages_fts WHERE rowid = old.rowid;
+			INSERT INTO messages_fts(rowid, text, media_caption, filename, chat_name, sender_name, display_text, link_title, link_description)
+			VALUES (new.rowid, COALESCE(new.text,''), COALESCE(new.media_caption,''), COALESCE(new.filename,''), COALESCE(new.chat_name,''), COALESCE(new.sender_name,''), COALESCE(new.display_text,''), COALESCE(new.link_title,''), COALESCE(new.link_description,''));
+		END;
+
+		INSERT INTO messages_fts(rowid, text, media_caption, filename, chat_name, sender_name, display_text, link_title, link_description)
+		SELECT rowid,
+		       COALESCE(text,''),
+		       COALESCE(media_caption,''),
+		       COALESCE(filename,''),
+		       COALESCE(chat_name,''),
+		       COALESCE(sender_name,''),
+		       COALESCE(display_text,''),
+		       COALESCE(link_title,''),
+		       COALESCE(link_description,'')
+		FROM messages;
+	`); err != nil {
+		// Continue without FTS (fallback to LIKE).
+		d.ftsEnabled = false
+		return nil
+	}
+	return nil
+}
+
+func migrateMessagesForwarded(d *DB) error {
+	for _, col := range []struct{ name, ddl string }{
+		{"is_forwarded", `ALTER TABLE messages ADD COLUMN is_forwarded INTEGER NOT NULL DEFAULT 0`},
+		{"forwarding_score", `ALTER TABLE messages ADD COLUMN forwarding_score INTEGER`},
+	} {
+		has, err := d.tableHasColumn("messages", col.name)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+		if _, err := d.sql.Exec(col.ddl); err != nil {
+			return fmt.Errorf("add %s column: %w", col.name, err)
+		}
+	}
+	return nil
+}
+
+// migrateChatsStatusKind gives status updates their own chat kind; they were
+// stored as "unknown".
+func migrateChatsStatusKind(d *DB) error {
+	if _, err := d.sql.Exec(`UPDATE chats SET kind = 'status' WHERE jid = ?`, StatusJID); err != nil {
+		return fmt.Errorf("update status chat kind: %w", err)
+	}
+	return nil
+}
+
+func migrateEphemeralColumns(d *DB) error {
+	for _, col := range []struct{ table, name, ddl string }{
+		{"messages", "ephemeral_expiration", `ALTER TABLE messages ADD COLUMN ephemeral_expiration INTEGER`},
+		{"messages", "is_view_once", `ALTER TABLE messages ADD COLUMN is_view_once INTEGER NOT NULL DEFAULT 0`},
+		{"messages", "edited_at", `ALTER TABLE messages ADD COLUMN edited_at INTEGER`},
+		{"chats", "ephemeral_expiration", `ALTER TABLE chats ADD COLUMN ephemeral_expiration INTEGER`},
+	} {
+		has, err := d.tableHasColumn(col.table, col.name)
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+		if _, err := d.sql.Exec(col.ddl); err != nil {
+			return fmt.Errorf("add %s.%s column: %w", col.table, col.name, err)
+		}
+	}
+	return nil
+}
+
+func migrateCalls(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS calls (
+			call_id TEXT PRIMARY KEY,
+			peer_jid TEXT NOT NULL,
+			group_jid TEXT,
+			direction TEXT NOT NULL,
+			is_video INTEGER NOT NULL DEFAULT 0,
+			outcome TEXT NOT NULL,
+			started_at INTEGER NOT NULL,
+			accepted_at INTEGER,
+			ended_at INTEGER,
+			end_reason TEXT
+		)
+	`); err != nil {
+		return fmt.Errorf("create calls table: %w", err)
+	}
+	if _, err := d.sql.Exec(`CREATE INDEX IF NOT EXISTS idx_calls_started ON calls(started_at)`); err != nil {
+		return fmt.Errorf("create calls started index: %w", err)
+	}
+	return nil
+}
+
+func migrateBroadcastMembers(d *DB) error {
+	if _, err := d.sql.Exec(`
+		CREATE TABLE IF NOT EXISTS broadcast_members (
+			list_jid TEXT NOT NULL,
+			member_jid TEXT NOT NULL,
+			updated_at INTEGER NOT NULL,
+			PRIMARY KEY (list_jid, member_jid)
+		)
+	`); err != nil {
+		return fmt.Errorf("create broadcast_members table: %w", err)
+	}
+	return nil
+}
+
 func (d *DB) tableExists(table string) (bool, error) {
 	row := d.sql.QueryRow(`SELECT 1 FROM sqlite_master WHERE name = ? AND type IN ('table','view')`, table)
 	var one int
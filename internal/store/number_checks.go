@@ -0,0 +1,66 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SaveNumberCheck caches the result of an "is on WhatsApp" lookup. Only a
+// registered number is also recorded as a contact; a number that isn't on
+// WhatsApp stays out of the contact list.
+func (d *DB) SaveNumberCheck(c NumberCheck) error {
+	if strings.TrimSpace(c.JID) == "" || strings.TrimSpace(c.Phone) == "" {
+		return fmt.Errorf("jid and phone are required")
+	}
+	checked := c.CheckedAt
+	if checked.IsZero() {
+		checked = time.Now().UTC()
+	}
+	if _, err := d.sql.Exec(`
+		INSERT INTO number_checks(phone, jid, registered, is_business, business_name, checked_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(phone) DO UPDATE SET
+			jid=excluded.jid,
+			registered=excluded.registered,
+			is_business=excluded.is_business,
+			business_name=excluded.business_name,
+			checked_at=excluded.checked_at
+	`, c.Phone, c.JID, boolToInt(c.Registered), boolToInt(c.Business), nullIfEmpty(c.BusinessName), unix(checked)); err != nil {
+		return err
+	}
+	if !c.Registered {
+		return nil
+	}
+	// is_business only ever goes up here: a business profile seen elsewhere
+	// isn't undone by a lookup that didn't return a verified name.
+	_, err := d.sql.Exec(`
+		INSERT INTO contacts(jid, phone, business_name, is_business, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(jid) DO UPDATE SET
+			phone=COALESCE(NULLIF(excluded.phone,''), contacts.phone),
+			business_name=COALESCE(NULLIF(excluded.business_name,''), contacts.business_name),
+			is_business=MAX(excluded.is_business, contacts.is_business),
+			updated_at=excluded.updated_at
+	`, c.JID, c.Phone, nullIfEmpty(c.BusinessName), boolToInt(c.Business), time.Now().UTC().Unix())
+	return err
+}
+
+// GetNumberCheck returns the cached lookup for phone (digits only).
+func (d *DB) GetNumberCheck(phone string) (NumberCheck, error) {
+	row := d.sql.QueryRow(`
+		SELECT phone, jid, registered, is_business, COALESCE(business_name,''), checked_at
+		FROM number_checks
+		WHERE phone = ?
+	`, phone)
+	var c NumberCheck
+	var registered, business int
+	var checked int64
+	if err := row.Scan(&c.Phone, &c.JID, &registered, &business, &c.BusinessName, &checked); err != nil {
+		return NumberCheck{}, err
+	}
+	c.Registered = registered != 0
+	c.Business = business != 0
+	c.CheckedAt = fromUnix(checked)
+	return c, nil
+}
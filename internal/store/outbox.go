@@ -0,0 +1,176 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const outboxColumns = `id, chat_jid, kind, COALESCE(body,''), COALESCE(file_path,''), COALESCE(filename,''), COALESCE(mime_type,''),
+	COALESCE(msg_id,''), status, attempts, send_at, next_attempt_at, COALESCE(last_error,''), created_at, COALESCE(sent_at,0)`
+
+// EnqueueOutbox stores a message to be sent at it.SendAt and returns its ID.
+func (d *DB) EnqueueOutbox(it OutboxItem) (int64, error) {
+	if strings.TrimSpace(it.ChatJID) == "" {
+		return 0, fmt.Errorf("chat jid is required")
+	}
+	switch it.Kind {
+	case "text", "link":
+		if strings.TrimSpace(it.Text) == "" {
+			return 0, fmt.Errorf("text is required")
+		}
+	case "file":
+		if strings.TrimSpace(it.FilePath) == "" {
+			return 0, fmt.Errorf("file path is required")
+		}
+	default:
+		return 0, fmt.Errorf("unsupported outbox kind %q", it.Kind)
+	}
+	if it.SendAt.IsZero() {
+		return 0, fmt.Errorf("send time is required")
+	}
+	res, err := d.sql.Exec(`
+		INSERT INTO outbox(chat_jid, kind, body, file_path, filename, mime_type, status, attempts, send_at, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?)
+	`, it.ChatJID, it.Kind, nullIfEmpty(it.Text), nullIfEmpty(it.FilePath), nullIfEmpty(it.Filename), nullIfEmpty(it.MimeType),
+		OutboxPending, unix(it.SendAt), unix(it.SendAt), time.Now().UTC().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (d *DB) GetOutboxItem(id int64) (OutboxItem, error) {
+	row := d.sql.QueryRow(`SELECT `+outboxColumns+` FROM outbox WHERE id = ?`, id)
+	return scanOutboxItem(row)
+}
+
+// ListOutbox lists outbox items by send time, optionally filtered by status.
+func (d *DB) ListOutbox(status string, limit int) ([]OutboxItem, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	query := `SELECT ` + outboxColumns + ` FROM outbox`
+	var args []interface{}
+	if status = strings.TrimSpace(status); status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY send_at, id LIMIT ?`
+	args = append(args, limit)
+	return d.queryOutbox(query, args...)
+}
+
+// DueOutbox returns items whose next attempt is at or before now, including
+// items left in OutboxSending by an interrupted run.
+func (d *DB) DueOutbox(now time.Time, limit int) ([]OutboxItem, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	return d.queryOutbox(`SELECT `+outboxColumns+` FROM outbox
+		WHERE status IN (?, ?) AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id
+		LIMIT ?`, OutboxPending, OutboxSending, unix(now), limit)
+}
+
+// BeginOutboxAttempt records the message ID used for delivery and counts the
+// attempt. An ID that was already stored is kept, so every retry reuses it.
+func (d *DB) BeginOutboxAttempt(id int64, msgID string) (OutboxItem, error) {
+	if _, err := d.sql.Exec(`
+		UPDATE outbox SET
+			msg_id = COALESCE(msg_id, ?),
+			status = ?,
+			attempts = attempts + 1
+		WHERE id = ? AND status IN (?, ?)
+	`, nullIfEmpty(msgID), OutboxSending, id, OutboxPending, OutboxSending); err != nil {
+		return OutboxItem{}, err
+	}
+	return d.GetOutboxItem(id)
+}
+
+func (d *DB) MarkOutboxSent(id int64, msgID string, sentAt time.Time) error {
+	_, err := d.sql.Exec(`
+		UPDATE outbox SET msg_id = ?, status = ?, sent_at = ?, last_error = NULL WHERE id = ?
+	`, msgID, OutboxSent, unix(sentAt), id)
+	return err
+}
+
+// MarkOutboxRetry puts an item back in the queue for another attempt at next.
+func (d *DB) MarkOutboxRetry(id int64, errMsg string, next time.Time) error {
+	_, err := d.sql.Exec(`
+		UPDATE outbox SET status = ?, next_attempt_at = ?, last_error = ? WHERE id = ?
+	`, OutboxPending, unix(next), nullIfEmpty(errMsg), id)
+	return err
+}
+
+func (d *DB) MarkOutboxFailed(id int64, errMsg string) error {
+	_, err := d.sql.Exec(`
+		UPDATE outbox SET status = ?, last_error = ? WHERE id = ?
+	`, OutboxFailed, nullIfEmpty(errMsg), id)
+	return err
+}
+
+// CancelOutbox cancels a pending or failed item.
+func (d *DB) CancelOutbox(id int64) error {
+	return d.updateQueuedOutbox(id, `UPDATE outbox SET status = ? WHERE id = ? AND status IN (?, ?)`,
+		OutboxCanceled, id, OutboxPending, OutboxFailed)
+}
+
+// RescheduleOutbox moves a pending, failed or canceled item to at and resets
+// its retry count.
+func (d *DB) RescheduleOutbox(id int64, at time.Time) error {
+	if at.IsZero() {
+		return fmt.Errorf("send time is required")
+	}
+	return d.updateQueuedOutbox(id, `
+		UPDATE outbox SET status = ?, send_at = ?, next_attempt_at = ?, attempts = 0, last_error = NULL
+		WHERE id = ? AND status IN (?, ?, ?)
+	`, OutboxPending, unix(at), unix(at), id, OutboxPending, OutboxFailed, OutboxCanceled)
+}
+
+func (d *DB) updateQueuedOutbox(id int64, query string, args ...interface{}) error {
+	res, err := d.sql.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	it, err := d.GetOutboxItem(id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("outbox item %d is %s", id, it.Status)
+}
+
+func (d *DB) queryOutbox(query string, args ...interface{}) ([]OutboxItem, error) {
+	rows, err := d.sql.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []OutboxItem
+	for rows.Next() {
+		it, err := scanOutboxItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+func scanOutboxItem(row interface{ Scan(...interface{}) error }) (OutboxItem, error) {
+	var it OutboxItem
+	var sendAt, nextAt, createdAt, sentAt int64
+	if err := row.Scan(&it.ID, &it.ChatJID, &it.Kind, &it.Text, &it.FilePath, &it.Filename, &it.MimeType,
+		&it.MsgID, &it.Status, &it.Attempts, &sendAt, &nextAt, &it.LastError, &createdAt, &sentAt); err != nil {
+		return OutboxItem{}, err
+	}
+	it.SendAt = fromUnix(sendAt)
+	it.NextAttemptAt = fromUnix(nextAt)
+	it.CreatedAt = fromUnix(createdAt)
+	it.SentAt = fromUnix(sentAt)
+	return it, nil
+}
@@ -14,9 +14,13 @@ type SearchMessagesParams struct {
 	Before  *time.Time
 	After   *time.Time
 	Type    string
+	// ExcludeForwarded drops messages forwarded from other chats.
+	ExcludeForwarded bool
 }
 
 func (d *DB) SearchMessages(p SearchMessagesParams) ([]Message, error) {
+	p.ChatJID = d.ResolveJID(p.ChatJID)
+	p.From = d.ResolveJID(p.From)
 	if strings.TrimSpace(p.Query) == "" {
 		return nil, fmt.Errorf("query is required")
 	}
@@ -32,12 +36,12 @@ func (d *DB) SearchMessages(p SearchMessagesParams) ([]Message, error) {
 
 func (d *DB) searchLIKE(p SearchMessagesParams) ([]Message, error) {
 	query := `
-		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), ''
+		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''), '', m.is_forwarded
 		FROM messages m
 		LEFT JOIN chats c ON c.jid = m.chat_jid
-		WHERE (LOWER(m.text) LIKE LOWER(?) OR LOWER(m.display_text) LIKE LOWER(?) OR LOWER(m.media_caption) LIKE LOWER(?) OR LOWER(m.filename) LIKE LOWER(?) OR LOWER(COALESCE(m.chat_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(m.sender_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.name,'')) LIKE LOWER(?))`
+		WHERE (LOWER(m.text) LIKE LOWER(?) OR LOWER(m.display_text) LIKE LOWER(?) OR LOWER(m.media_caption) LIKE LOWER(?) OR LOWER(m.filename) LIKE LOWER(?) OR LOWER(COALESCE(m.chat_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(m.sender_name,'')) LIKE LOWER(?) OR LOWER(COALESCE(c.name,'')) LIKE LOWER(?) OR LOWER(COALESCE(m.link_title,'')) LIKE LOWER(?) OR LOWER(COALESCE(m.link_description,'')) LIKE LOWER(?))`
 	needle := "%" + p.Query + "%"
-	args := []interface{}{needle, needle, needle, needle, needle, needle, needle}
+	args := []interface{}{needle, needle, needle, needle, needle, needle, needle, needle, needle}
 	query, args = applyMessageFilters(query, args, p)
 	query += " ORDER BY m.ts DESC LIMIT ?"
 	args = append(args, p.Limit)
@@ -47,7 +51,7 @@ func (d *DB) searchLIKE(p SearchMessagesParams) ([]Message, error) {
 func (d *DB) searchFTS(p SearchMessagesParams) ([]Message, error) {
 	query := `
 		SELECT m.chat_jid, COALESCE(c.name,''), m.msg_id, COALESCE(m.sender_jid,''), m.ts, m.from_me, COALESCE(m.text,''), COALESCE(m.display_text,''), COALESCE(m.media_type,''),
-		       snippet(messages_fts, 0, '[', ']', '…', 12)
+		       snippet(messages_fts, 0, '[', ']', '…', 12), m.is_forwarded
 		FROM messages_fts
 		JOIN messages m ON messages_fts.rowid = m.rowid
 		LEFT JOIN chats c ON c.jid = m.chat_jid
@@ -63,6 +67,9 @@ func applyMessageFilters(query string, args []interface{}, p SearchMessagesParam
 	if strings.TrimSpace(p.ChatJID) != "" {
 		query += " AND m.chat_jid = ?"
 		args = append(args, p.ChatJID)
+	} else {
+		query += " AND m.chat_jid != ?"
+		args = append(args, StatusJID)
 	}
 	if strings.TrimSpace(p.From) != "" {
 		query += " AND m.sender_jid = ?"
@@ -77,8 +84,12 @@ func applyMessageFilters(query string, args []interface{}, p SearchMessagesParam
 		args = append(args, unix(*p.Before))
 	}
 	if strings.TrimSpace(p.Type) != "" {
-		query += " AND COALESCE(m.media_type,'') = ?"
-		args = append(args, p.Type)
+		cond, typeArgs := mediaTypeFilter(p.Type)
+		query += " AND " + cond
+		args = append(args, typeArgs...)
+	}
+	if p.ExcludeForwarded {
+		query += " AND m.is_forwarded = 0"
 	}
 	return query, args
 }
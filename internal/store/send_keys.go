@@ -0,0 +1,45 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (d *DB) GetSendKey(key string) (SendKey, error) {
+	row := d.sql.QueryRow(`
+		SELECT key, chat_jid, msg_id, COALESCE(result,''), created_at, COALESCE(sent_at,0)
+		FROM send_keys
+		WHERE key = ?
+	`, key)
+	var k SendKey
+	var created, sent int64
+	if err := row.Scan(&k.Key, &k.ChatJID, &k.MsgID, &k.Result, &created, &sent); err != nil {
+		return SendKey{}, err
+	}
+	k.CreatedAt = fromUnix(created)
+	k.SentAt = fromUnix(sent)
+	return k, nil
+}
+
+// ReserveSendKey records msgID for key unless the key already exists, and
+// returns the stored row either way.
+func (d *DB) ReserveSendKey(key, chatJID, msgID string) (SendKey, error) {
+	if strings.TrimSpace(key) == "" || strings.TrimSpace(chatJID) == "" || strings.TrimSpace(msgID) == "" {
+		return SendKey{}, fmt.Errorf("key, chat jid and message id are required")
+	}
+	if _, err := d.sql.Exec(`
+		INSERT OR IGNORE INTO send_keys(key, chat_jid, msg_id, created_at) VALUES (?, ?, ?, ?)
+	`, key, chatJID, msgID, time.Now().UTC().Unix()); err != nil {
+		return SendKey{}, err
+	}
+	return d.GetSendKey(key)
+}
+
+// CompleteSendKey stores the result of a successful send for key.
+func (d *DB) CompleteSendKey(key, msgID, result string) error {
+	_, err := d.sql.Exec(`
+		UPDATE send_keys SET msg_id = ?, result = ?, sent_at = ? WHERE key = ?
+	`, msgID, nullIfEmpty(result), time.Now().UTC().Unix(), key)
+	return err
+}
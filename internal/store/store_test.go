@@ -2,6 +2,7 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
@@ -213,7 +214,7 @@ func TestContactsAliasTagsAndSearch(t *testing.T) {
 		t.Fatalf("expected 2 tags, got %v", c.Tags)
 	}
 
-	found, err := db.SearchContacts("Ali", 10)
+	found, err := db.SearchContacts(SearchContactsParams{Query: "Ali", Limit: 10})
 	if err != nil {
 		t.Fatalf("SearchContacts: %v", err)
 	}
@@ -319,3 +320,824 @@ func TestGroupsUpsertListAndParticipantsReplace(t *testing.T) {
 		t.Fatalf("expected roles admin=1 member=1, got admin=%d member=%d", admins, members)
 	}
 }
+
+func TestGroupCommunitiesLinkAndList(t *testing.T) {
+	db := openTestDB(t)
+
+	parent := "100@g.us"
+	child := "200@g.us"
+	other := "300@g.us"
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.UpsertGroup(parent, "Neighbours", "owner@s.whatsapp.net", created); err != nil {
+		t.Fatalf("UpsertGroup parent: %v", err)
+	}
+	if err := db.SetGroupCommunity(parent, true, ""); err != nil {
+		t.Fatalf("SetGroupCommunity parent: %v", err)
+	}
+	if err := db.UpsertGroup(child, "Street A", "owner@s.whatsapp.net", created); err != nil {
+		t.Fatalf("UpsertGroup child: %v", err)
+	}
+	if err := db.SetGroupCommunity(child, false, parent); err != nil {
+		t.Fatalf("SetGroupCommunity child: %v", err)
+	}
+	if err := db.UpsertGroup(other, "Standalone", "owner@s.whatsapp.net", created); err != nil {
+		t.Fatalf("UpsertGroup other: %v", err)
+	}
+
+	cs, err := db.ListCommunities("", 10)
+	if err != nil {
+		t.Fatalf("ListCommunities: %v", err)
+	}
+	if len(cs) != 1 || cs[0].JID != parent || cs[0].SubGroups != 1 {
+		t.Fatalf("expected one community with one group, got %+v", cs)
+	}
+
+	g, err := db.GetGroup(child)
+	if err != nil {
+		t.Fatalf("GetGroup: %v", err)
+	}
+	if g.ParentJID != parent || g.ParentName != "Neighbours" {
+		t.Fatalf("expected parent to resolve, got %+v", g)
+	}
+
+	if err := db.SetGroupParent(other, parent); err != nil {
+		t.Fatalf("SetGroupParent link: %v", err)
+	}
+	subs, err := db.ListSubGroups(parent)
+	if err != nil {
+		t.Fatalf("ListSubGroups: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("expected 2 linked groups, got %+v", subs)
+	}
+
+	if err := db.SetGroupParent(child, ""); err != nil {
+		t.Fatalf("SetGroupParent unlink: %v", err)
+	}
+	g, err = db.GetGroup(child)
+	if err != nil {
+		t.Fatalf("GetGroup: %v", err)
+	}
+	if g.ParentJID != "" || g.Name != "Street A" {
+		t.Fatalf("expected unlink to keep name and clear parent, got %+v", g)
+	}
+}
+
+func TestSaveLIDMappingMergesIntoExistingPNRows(t *testing.T) {
+	db := openTestDB(t)
+
+	lid := "888@lid"
+	pn := "222@s.whatsapp.net"
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, jid := range []string{lid, pn} {
+		if err := db.UpsertContact(jid, "", "", "", "", ""); err != nil {
+			t.Fatalf("UpsertContact: %v", err)
+		}
+	}
+	// Local data split across both rows.
+	if err := db.SetNotes(lid, "met at the conference"); err != nil {
+		t.Fatalf("SetNotes: %v", err)
+	}
+	if err := db.SetAlias(pn, "Bea"); err != nil {
+		t.Fatalf("SetAlias: %v", err)
+	}
+	if err := db.SetAlias(lid, "Beatrice"); err != nil {
+		t.Fatalf("SetAlias(lid): %v", err)
+	}
+	_ = db.AddTag(lid, "work")
+	_ = db.AddTag(pn, "friends")
+	if err := db.SetBlocked(lid, true); err != nil {
+		t.Fatalf("SetBlocked: %v", err)
+	}
+	if err := db.SaveAvatar(Avatar{JID: pn, PictureID: "old", Path: "/tmp/old.jpg", UpdatedAt: old}); err != nil {
+		t.Fatalf("SaveAvatar: %v", err)
+	}
+	if err := db.SaveAvatar(Avatar{JID: lid, PictureID: "new", Path: "/tmp/new.jpg", UpdatedAt: old.Add(time.Hour)}); err != nil {
+		t.Fatalf("SaveAvatar(lid): %v", err)
+	}
+
+	if err := db.SaveLIDMapping(lid, pn); err != nil {
+		t.Fatalf("SaveLIDMapping: %v", err)
+	}
+
+	c, err := db.GetContact(pn)
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	if c.Alias != "Bea" || c.Notes != "met at the conference" || !c.Blocked {
+		t.Fatalf("expected alias, notes and block to be merged, got %+v", c)
+	}
+	if len(c.Tags) != 2 {
+		t.Fatalf("expected tags from both rows, got %v", c.Tags)
+	}
+	if a, err := db.GetAvatar(pn); err != nil || a.PictureID != "new" {
+		t.Fatalf("expected the newer avatar to be kept, got %+v err=%v", a, err)
+	}
+	for _, table := range []string{"contacts", "contact_aliases", "contact_tags", "blocklist", "avatars"} {
+		if got := countRows(t, db.sql, "SELECT COUNT(*) FROM "+table+" WHERE jid = ?", lid); got != 0 {
+			t.Fatalf("expected no LID rows left in %s, got %d", table, got)
+		}
+	}
+}
+
+func TestListGroupMembersJoinsContactMetadata(t *testing.T) {
+	db := openTestDB(t)
+
+	gid := "123@g.us"
+	if err := db.UpsertGroup(gid, "Group", "", time.Time{}); err != nil {
+		t.Fatalf("UpsertGroup: %v", err)
+	}
+	known := "111@s.whatsapp.net"
+	unknown := "222@s.whatsapp.net"
+	if err := db.UpsertContact(known, "111", "Push", "Full Name", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.SetAlias(known, "Ali"); err != nil {
+		t.Fatalf("SetAlias: %v", err)
+	}
+	if err := db.AddTag(known, "vip"); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+	if err := db.ReplaceGroupParticipants(gid, []GroupParticipant{
+		{GroupJID: gid, UserJID: unknown, Role: "member"},
+		{GroupJID: gid, UserJID: known, Role: "admin"},
+		{GroupJID: gid, UserJID: "99887766@lid", Role: "member"},
+	}); err != nil {
+		t.Fatalf("ReplaceGroupParticipants: %v", err)
+	}
+
+	ms, err := db.ListGroupMembers(gid)
+	if err != nil {
+		t.Fatalf("ListGroupMembers: %v", err)
+	}
+	if len(ms) != 3 {
+		t.Fatalf("expected 3 members, got %+v", ms)
+	}
+	if ms[0].UserJID != known || ms[0].Role != "admin" || ms[0].Name != "Full Name" || ms[0].Alias != "Ali" {
+		t.Fatalf("unexpected first member: %+v", ms[0])
+	}
+	if len(ms[0].Tags) != 1 || ms[0].Tags[0] != "vip" {
+		t.Fatalf("expected tags [vip], got %v", ms[0].Tags)
+	}
+	if ms[1].UserJID != unknown || ms[1].Phone != "222" || ms[1].Name != "" {
+		t.Fatalf("unexpected second member: %+v", ms[1])
+	}
+	if ms[2].UserJID != "99887766@lid" || ms[2].Phone != "" {
+		t.Fatalf("expected no phone for an unmapped LID, got %+v", ms[2])
+	}
+}
+
+func TestContactNotesAndNameFields(t *testing.T) {
+	db := openTestDB(t)
+
+	jid := "111@s.whatsapp.net"
+	if err := db.UpsertContact(jid, "111", "Push", "Full Name", "First", "Biz"); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.SetNotes(jid, "met at the trade fair"); err != nil {
+		t.Fatalf("SetNotes: %v", err)
+	}
+	if err := db.SetAlias(jid, "Ali"); err != nil {
+		t.Fatalf("SetAlias: %v", err)
+	}
+
+	c, err := db.GetContact(jid)
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	if c.Notes != "met at the trade fair" || c.Alias != "Ali" {
+		t.Fatalf("expected notes and alias, got %+v", c)
+	}
+	if c.PushName != "Push" || c.FullName != "Full Name" || c.FirstName != "First" || c.BusinessName != "Biz" || c.Name != "Full Name" {
+		t.Fatalf("unexpected name fields: %+v", c)
+	}
+
+	found, err := db.SearchContacts(SearchContactsParams{Query: "trade fair", Limit: 10})
+	if err != nil {
+		t.Fatalf("SearchContacts: %v", err)
+	}
+	if len(found) != 1 || found[0].JID != jid {
+		t.Fatalf("expected to find contact by notes, got %+v", found)
+	}
+
+	// Removing the alias keeps the notes, and vice versa.
+	if err := db.RemoveAlias(jid); err != nil {
+		t.Fatalf("RemoveAlias: %v", err)
+	}
+	if notes, err := db.GetNotes(jid); err != nil || notes != "met at the trade fair" {
+		t.Fatalf("expected notes to survive alias removal, got %q (err=%v)", notes, err)
+	}
+	if err := db.ClearNotes(jid); err != nil {
+		t.Fatalf("ClearNotes: %v", err)
+	}
+	if got := countRows(t, db.sql, "SELECT COUNT(*) FROM contact_aliases WHERE jid = ?", jid); got != 0 {
+		t.Fatalf("expected empty alias row to be pruned, got %d", got)
+	}
+}
+
+func TestListContactsAndFindByPhone(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := db.UpsertContact("111@s.whatsapp.net", "111", "Alice", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.UpsertContact("999@lid", "222", "Bob (lid)", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.UpsertContact("222@s.whatsapp.net", "", "Bob", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.AddTag("111@s.whatsapp.net", "vip"); err != nil {
+		t.Fatalf("AddTag: %v", err)
+	}
+
+	cs, err := db.ListContacts()
+	if err != nil {
+		t.Fatalf("ListContacts: %v", err)
+	}
+	if len(cs) != 3 {
+		t.Fatalf("expected 3 contacts, got %d", len(cs))
+	}
+	for _, c := range cs {
+		if c.JID == "111@s.whatsapp.net" && (len(c.Tags) != 1 || c.Tags[0] != "vip") {
+			t.Fatalf("expected tags on listed contact, got %+v", c)
+		}
+	}
+
+	c, err := db.FindContactByPhone("222")
+	if err != nil {
+		t.Fatalf("FindContactByPhone: %v", err)
+	}
+	if c.JID != "222@s.whatsapp.net" {
+		t.Fatalf("expected phone JID to win over LID, got %s", c.JID)
+	}
+	if _, err := db.FindContactByPhone("333"); !IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestBlocklistReplaceAndContactFlag(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := db.UpsertContact("111@s.whatsapp.net", "111", "Spammer", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.ReplaceBlocklist([]string{"111@s.whatsapp.net", "222@s.whatsapp.net"}); err != nil {
+		t.Fatalf("ReplaceBlocklist: %v", err)
+	}
+	c, err := db.GetContact("111@s.whatsapp.net")
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	if !c.Blocked {
+		t.Fatalf("expected contact to be flagged as blocked")
+	}
+
+	if err := db.ReplaceBlocklist([]string{"222@s.whatsapp.net"}); err != nil {
+		t.Fatalf("ReplaceBlocklist: %v", err)
+	}
+	if blocked, _ := db.IsBlocked("111@s.whatsapp.net"); blocked {
+		t.Fatalf("expected 111 to be unblocked after replace")
+	}
+	if err := db.SetBlocked("333@s.whatsapp.net", true); err != nil {
+		t.Fatalf("SetBlocked: %v", err)
+	}
+	list, err := db.ListBlocked()
+	if err != nil {
+		t.Fatalf("ListBlocked: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 blocked entries, got %+v", list)
+	}
+}
+
+func TestSaveLIDMappingRewritesRows(t *testing.T) {
+	db := openTestDB(t)
+
+	lid := "777@lid"
+	pn := "111@s.whatsapp.net"
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, chat := range []string{lid, pn} {
+		if err := db.UpsertChat(chat, "unknown", "Alice", ts); err != nil {
+			t.Fatalf("UpsertChat: %v", err)
+		}
+	}
+	msgs := []UpsertMessageParams{
+		{ChatJID: lid, MsgID: "m1", SenderJID: "777:3@lid", Timestamp: ts, Text: "via lid"},
+		{ChatJID: pn, MsgID: "m2", SenderJID: pn, Timestamp: ts.Add(time.Second), Text: "via pn"},
+		// Same message stored under both JIDs.
+		{ChatJID: lid, MsgID: "m2", SenderJID: lid, Timestamp: ts.Add(time.Second), Text: "via pn"},
+		{ChatJID: "g@g.us", MsgID: "m3", SenderJID: lid, Timestamp: ts, Text: "in group"},
+	}
+	if err := db.UpsertChat("g@g.us", "group", "G", ts); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	for _, m := range msgs {
+		if err := db.UpsertMessage(m); err != nil {
+			t.Fatalf("UpsertMessage: %v", err)
+		}
+	}
+	if err := db.UpsertContact(lid, "777", "Alice (lid)", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.SetAlias(lid, "Ali"); err != nil {
+		t.Fatalf("SetAlias: %v", err)
+	}
+
+	unmapped, err := db.ListUnmappedLIDs()
+	if err != nil {
+		t.Fatalf("ListUnmappedLIDs: %v", err)
+	}
+	if len(unmapped) != 1 || unmapped[0] != lid {
+		t.Fatalf("expected [%s], got %v", lid, unmapped)
+	}
+
+	if err := db.SaveLIDMapping(lid, pn); err != nil {
+		t.Fatalf("SaveLIDMapping: %v", err)
+	}
+
+	if got := countRows(t, db.sql, "SELECT COUNT(*) FROM messages WHERE chat_jid = ?", pn); got != 2 {
+		t.Fatalf("expected 2 messages in phone chat, got %d", got)
+	}
+	if got := countRows(t, db.sql, "SELECT COUNT(*) FROM messages WHERE chat_jid = ? OR sender_jid LIKE '%@lid'", lid); got != 0 {
+		t.Fatalf("expected no LID rows left in messages, got %d", got)
+	}
+	if got := countRows(t, db.sql, "SELECT COUNT(*) FROM chats WHERE jid = ?", lid); got != 0 {
+		t.Fatalf("expected LID chat to be removed, got %d", got)
+	}
+	c, err := db.GetContact(pn)
+	if err != nil {
+		t.Fatalf("GetContact: %v", err)
+	}
+	if c.Alias != "Ali" || c.PushName != "Alice (lid)" || c.Phone != "111" {
+		t.Fatalf("expected merged contact, got %+v", c)
+	}
+	if got := db.ResolveJID("777:5@lid"); got != pn {
+		t.Fatalf("ResolveJID: got %q", got)
+	}
+	if unmapped, _ := db.ListUnmappedLIDs(); len(unmapped) != 0 {
+		t.Fatalf("expected nothing unmapped, got %v", unmapped)
+	}
+}
+
+func TestBusinessProfileCacheAndSearchFilter(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := db.UpsertContact("111@s.whatsapp.net", "111", "Acme Support", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	if err := db.UpsertContact("222@s.whatsapp.net", "222", "Acme Fan", "", "", ""); err != nil {
+		t.Fatalf("UpsertContact: %v", err)
+	}
+	in := BusinessProfile{
+		JID:        "111@s.whatsapp.net",
+		Name:       "Acme Ltd",
+		Address:    "1 Main St",
+		Website:    "https://acme.example",
+		Categories: []string{"Shopping", "Retail"},
+		Hours:      []BusinessHours{{Day: "mon", Mode: "specific_hours", Open: "540", Close: "1020"}},
+	}
+	if err := db.SaveBusinessProfile(in); err != nil {
+		t.Fatalf("SaveBusinessProfile: %v", err)
+	}
+	got, err := db.GetBusinessProfile(in.JID)
+	if err != nil {
+		t.Fatalf("GetBusinessProfile: %v", err)
+	}
+	if got.Name != "Acme Ltd" || got.Website != in.Website || len(got.Categories) != 2 || len(got.Hours) != 1 || got.Hours[0].Open != "540" {
+		t.Fatalf("unexpected profile: %+v", got)
+	}
+
+	all, err := db.SearchContacts(SearchContactsParams{Query: "Acme"})
+	if err != nil {
+		t.Fatalf("SearchContacts: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(all))
+	}
+	biz, err := db.SearchContacts(SearchContactsParams{Query: "Acme", BusinessOnly: true})
+	if err != nil {
+		t.Fatalf("SearchContacts(business): %v", err)
+	}
+	if len(biz) != 1 || biz[0].JID != in.JID {
+		t.Fatalf("expected only the business account, got %+v", biz)
+	}
+	if _, err := db.SearchContacts(SearchContactsParams{BusinessOnly: true}); err != nil {
+		t.Fatalf("expected empty query to be allowed with BusinessOnly: %v", err)
+	}
+}
+
+func TestNumberCheckOnlyAddsRegisteredContacts(t *testing.T) {
+	db := openTestDB(t)
+
+	if err := db.SaveNumberCheck(NumberCheck{Phone: "111", JID: "111@s.whatsapp.net", Registered: false}); err != nil {
+		t.Fatalf("SaveNumberCheck(unregistered): %v", err)
+	}
+	if n := countRows(t, db.sql, `SELECT COUNT(*) FROM contacts`); n != 0 {
+		t.Fatalf("expected no contact for an unregistered number, got %d", n)
+	}
+	if c, err := db.GetNumberCheck("111"); err != nil || c.Registered || c.CheckedAt.IsZero() {
+		t.Fatalf("expected the unregistered check to be cached, got %+v err=%v", c, err)
+	}
+
+	if err := db.SaveNumberCheck(NumberCheck{Phone: "222", JID: "222@s.whatsapp.net", Registered: true, Business: true, BusinessName: "Acme"}); err != nil {
+		t.Fatalf("SaveNumberCheck(business): %v", err)
+	}
+	// A later lookup without a verified name doesn't clear the business flag.
+	if err := db.SaveNumberCheck(NumberCheck{Phone: "222", JID: "222@s.whatsapp.net", Registered: true}); err != nil {
+		t.Fatalf("SaveNumberCheck(again): %v", err)
+	}
+	if n := countRows(t, db.sql, `SELECT COUNT(*) FROM contacts WHERE jid = ? AND is_business = 1 AND business_name = 'Acme'`, "222@s.whatsapp.net"); n != 1 {
+		t.Fatalf("expected the business contact to keep its flag and name, got %d", n)
+	}
+	if c, err := db.GetNumberCheck("222"); err != nil || !c.Registered || c.Business {
+		t.Fatalf("expected the latest check to be returned, got %+v err=%v", c, err)
+	}
+}
+
+func TestOutboxQueueLifecycle(t *testing.T) {
+	db := openTestDB(t)
+
+	now := time.Now().UTC()
+	due, err := db.EnqueueOutbox(OutboxItem{ChatJID: "111@s.whatsapp.net", Kind: "text", Text: "hi", SendAt: now.Add(-time.Minute)})
+	if err != nil {
+		t.Fatalf("EnqueueOutbox: %v", err)
+	}
+	later, err := db.EnqueueOutbox(OutboxItem{ChatJID: "222@s.whatsapp.net", Kind: "file", FilePath: "/tmp/a.pdf", SendAt: now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("EnqueueOutbox(file): %v", err)
+	}
+	if _, err := db.EnqueueOutbox(OutboxItem{ChatJID: "111@s.whatsapp.net", Kind: "text", SendAt: now}); err == nil {
+		t.Fatalf("expected empty text to be rejected")
+	}
+
+	items, err := db.DueOutbox(now, 0)
+	if err != nil {
+		t.Fatalf("DueOutbox: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != due {
+		t.Fatalf("expected only the due item, got %+v", items)
+	}
+
+	it, err := db.BeginOutboxAttempt(due, "ID1")
+	if err != nil {
+		t.Fatalf("BeginOutboxAttempt: %v", err)
+	}
+	if it.Status != OutboxSending || it.Attempts != 1 || it.MsgID != "ID1" {
+		t.Fatalf("unexpected item after first attempt: %+v", it)
+	}
+	// An interrupted attempt stays due and keeps its message ID.
+	if items, _ := db.DueOutbox(now, 0); len(items) != 1 {
+		t.Fatalf("expected sending item to remain due, got %+v", items)
+	}
+	it, err = db.BeginOutboxAttempt(due, "ID2")
+	if err != nil {
+		t.Fatalf("BeginOutboxAttempt(again): %v", err)
+	}
+	if it.MsgID != "ID1" || it.Attempts != 2 {
+		t.Fatalf("expected message ID to be kept, got %+v", it)
+	}
+	if err := db.MarkOutboxSent(due, "ID1", now); err != nil {
+		t.Fatalf("MarkOutboxSent: %v", err)
+	}
+	if err := db.CancelOutbox(due); err == nil {
+		t.Fatalf("expected cancel of a sent item to fail")
+	}
+
+	if err := db.CancelOutbox(later); err != nil {
+		t.Fatalf("CancelOutbox: %v", err)
+	}
+	at := now.Add(-time.Second).Truncate(time.Second)
+	if err := db.RescheduleOutbox(later, at); err != nil {
+		t.Fatalf("RescheduleOutbox: %v", err)
+	}
+	it, err = db.GetOutboxItem(later)
+	if err != nil {
+		t.Fatalf("GetOutboxItem: %v", err)
+	}
+	if it.Status != OutboxPending || !it.SendAt.Equal(at) || !it.NextAttemptAt.Equal(at) {
+		t.Fatalf("unexpected rescheduled item: %+v", it)
+	}
+	if sent, _ := db.ListOutbox(OutboxSent, 0); len(sent) != 1 || sent[0].ID != due {
+		t.Fatalf("expected one sent item, got %+v", sent)
+	}
+}
+
+func TestSendKeysReserveAndComplete(t *testing.T) {
+	db := openTestDB(t)
+
+	k, err := db.ReserveSendKey("k1", "111@s.whatsapp.net", "ID1")
+	if err != nil {
+		t.Fatalf("ReserveSendKey: %v", err)
+	}
+	if k.MsgID != "ID1" || !k.SentAt.IsZero() {
+		t.Fatalf("unexpected reserved key: %+v", k)
+	}
+	k, err = db.ReserveSendKey("k1", "111@s.whatsapp.net", "ID2")
+	if err != nil || k.MsgID != "ID1" {
+		t.Fatalf("expected first reservation to win, got %+v err=%v", k, err)
+	}
+	if err := db.CompleteSendKey("k1", "ID1", `{"id":"ID1"}`); err != nil {
+		t.Fatalf("CompleteSendKey: %v", err)
+	}
+	k, err = db.GetSendKey("k1")
+	if err != nil || k.SentAt.IsZero() || k.Result != `{"id":"ID1"}` {
+		t.Fatalf("unexpected completed key: %+v err=%v", k, err)
+	}
+	if _, err := db.GetSendKey("missing"); !IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestListMessagesFromResolvesLID(t *testing.T) {
+	db := openTestDB(t)
+	chat := "g@g.us"
+	pn := "111@s.whatsapp.net"
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.UpsertChat(chat, "group", "G", ts); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	if err := db.UpsertMessage(UpsertMessageParams{ChatJID: chat, MsgID: "m1", SenderJID: pn, Timestamp: ts, Text: "hi"}); err != nil {
+		t.Fatalf("UpsertMessage: %v", err)
+	}
+	if err := db.SaveLIDMapping("555@lid", pn); err != nil {
+		t.Fatalf("SaveLIDMapping: %v", err)
+	}
+	msgs, err := db.ListMessages(ListMessagesParams{ChatJID: chat, From: "555@lid"})
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected the sender's message when filtering by LID, got %+v err=%v", msgs, err)
+	}
+}
+
+func TestListMessagesVoiceFilter(t *testing.T) {
+	db := openTestDB(t)
+	chat := "123@s.whatsapp.net"
+	now := time.Now().UTC()
+	if err := db.UpsertChat(chat, "dm", "Alice", now); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	for _, p := range []UpsertMessageParams{
+		{ChatJID: chat, MsgID: "v1", Timestamp: now, MediaType: "audio", DurationSeconds: 7, PTT: true},
+		{ChatJID: chat, MsgID: "a1", Timestamp: now, MediaType: "audio", DurationSeconds: 180},
+		{ChatJID: chat, MsgID: "t1", Timestamp: now, Text: "hi"},
+	} {
+		if err := db.UpsertMessage(p); err != nil {
+			t.Fatalf("UpsertMessage: %v", err)
+		}
+	}
+
+	voice, err := db.ListMessages(ListMessagesParams{ChatJID: chat, Type: "voice"})
+	if err != nil {
+		t.Fatalf("ListMessages(voice): %v", err)
+	}
+	if len(voice) != 1 || voice[0].MsgID != "v1" {
+		t.Fatalf("expected only the voice note, got %+v", voice)
+	}
+	audio, err := db.ListMessages(ListMessagesParams{ChatJID: chat, Type: "audio"})
+	if err != nil {
+		t.Fatalf("ListMessages(audio): %v", err)
+	}
+	if len(audio) != 2 {
+		t.Fatalf("expected both audio messages, got %d", len(audio))
+	}
+	if got := countRows(t, db.sql, "SELECT COUNT(*) FROM messages WHERE msg_id = 'v1' AND duration_seconds = 7 AND is_ptt = 1"); got != 1 {
+		t.Fatalf("expected duration and ptt to be stored")
+	}
+
+	// A later upsert without the flag (e.g. a history re-sync) keeps it.
+	if err := db.UpsertMessage(UpsertMessageParams{ChatJID: chat, MsgID: "v1", Timestamp: now, MediaType: "audio"}); err != nil {
+		t.Fatalf("UpsertMessage: %v", err)
+	}
+	if got := countRows(t, db.sql, "SELECT COUNT(*) FROM messages WHERE msg_id = 'v1' AND duration_seconds = 7 AND is_ptt = 1"); got != 1 {
+		t.Fatalf("expected ptt to survive a re-upsert")
+	}
+}
+
+func TestLinkPreviewIsStoredAndSearchable(t *testing.T) {
+	db := openTestDB(t)
+	chat := "123@s.whatsapp.net"
+	if err := db.UpsertChat(chat, "dm", "Alice", time.Now()); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	if err := db.UpsertMessage(UpsertMessageParams{
+		ChatJID:         chat,
+		MsgID:           "link1",
+		Timestamp:       time.Now(),
+		Text:            "look https://example.com/post",
+		LinkURL:         "https://example.com/post",
+		LinkTitle:       "Quarterly Zeppelin Report",
+		LinkDescription: "Airship numbers are up",
+	}); err != nil {
+		t.Fatalf("UpsertMessage: %v", err)
+	}
+
+	for _, q := range []string{"Zeppelin", "Airship"} {
+		ms, err := db.SearchMessages(SearchMessagesParams{Query: q})
+		if err != nil || len(ms) != 1 {
+			t.Fatalf("search %q: expected 1 result, got %d err=%v", q, len(ms), err)
+		}
+	}
+	m, err := db.GetMessage(chat, "link1")
+	if err != nil || m.LinkTitle != "Quarterly Zeppelin Report" || m.LinkURL != "https://example.com/post" {
+		t.Fatalf("unexpected message: %+v err=%v", m, err)
+	}
+}
+
+func TestStatusUpdatesKeptOutOfChatsAndMessages(t *testing.T) {
+	db := openTestDB(t)
+	chat := "123@s.whatsapp.net"
+	poster := "456@s.whatsapp.net"
+	now := time.Now().UTC()
+	if err := db.UpsertChat(chat, "dm", "Alice", now); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	if err := db.UpsertChat(StatusJID, "status", "", now); err != nil {
+		t.Fatalf("UpsertChat(status): %v", err)
+	}
+	for _, p := range []UpsertMessageParams{
+		{ChatJID: chat, MsgID: "m1", Timestamp: now, Text: "lunch?"},
+		{ChatJID: StatusJID, MsgID: "s1", SenderJID: poster, Timestamp: now, Text: "lunch at the beach"},
+		{ChatJID: StatusJID, MsgID: "s2", SenderJID: "789@s.whatsapp.net", Timestamp: now, MediaType: "image"},
+	} {
+		if err := db.UpsertMessage(p); err != nil {
+			t.Fatalf("UpsertMessage: %v", err)
+		}
+	}
+
+	chats, err := db.ListChats("", 50)
+	if err != nil || len(chats) != 1 || chats[0].JID != chat {
+		t.Fatalf("expected only the dm chat, got %+v err=%v", chats, err)
+	}
+	msgs, err := db.ListMessages(ListMessagesParams{})
+	if err != nil || len(msgs) != 1 || msgs[0].MsgID != "m1" {
+		t.Fatalf("expected statuses to be left out, got %+v err=%v", msgs, err)
+	}
+	found, err := db.SearchMessages(SearchMessagesParams{Query: "lunch"})
+	if err != nil || len(found) != 1 || found[0].MsgID != "m1" {
+		t.Fatalf("expected search to leave out statuses, got %+v err=%v", found, err)
+	}
+	statuses, err := db.ListMessages(ListMessagesParams{ChatJID: StatusJID, From: poster})
+	if err != nil || len(statuses) != 1 || statuses[0].MsgID != "s1" {
+		t.Fatalf("expected the poster's status, got %+v err=%v", statuses, err)
+	}
+}
+
+func TestCallLogLifecycle(t *testing.T) {
+	db := openTestDB(t)
+	alice := "111@s.whatsapp.net"
+	bob := "222@s.whatsapp.net"
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	if err := db.SetAlias(alice, "Alice"); err != nil {
+		t.Fatalf("SetAlias: %v", err)
+	}
+
+	for _, c := range []Call{
+		{CallID: "c1", PeerJID: alice, StartedAt: start},
+		{CallID: "c2", PeerJID: alice, Video: true, StartedAt: start.Add(time.Hour)},
+		{CallID: "c3", PeerJID: bob, StartedAt: start.Add(2 * time.Hour)},
+		{CallID: "c4", PeerJID: bob, Video: true, StartedAt: start.Add(3 * time.Hour)},
+	} {
+		if err := db.RecordCall(c); err != nil {
+			t.Fatalf("RecordCall: %v", err)
+		}
+	}
+	if err := db.RecordCall(Call{CallID: "c1", PeerJID: alice, Video: true, StartedAt: start.Add(time.Minute)}); err != nil {
+		t.Fatalf("RecordCall(repeat): %v", err)
+	}
+	if err := db.AnswerCall("c1", start.Add(5*time.Second)); err != nil {
+		t.Fatalf("AnswerCall: %v", err)
+	}
+	if err := db.EndCall("c1", "", start.Add(65*time.Second)); err != nil {
+		t.Fatalf("EndCall: %v", err)
+	}
+	if err := db.EndCall("c2", "timeout", start.Add(time.Hour+30*time.Second)); err != nil {
+		t.Fatalf("EndCall: %v", err)
+	}
+	if err := db.RejectCall("c3", CallAutoRejected, start.Add(2*time.Hour)); err != nil {
+		t.Fatalf("RejectCall: %v", err)
+	}
+	// The terminate that follows a rejection must not turn it into a miss.
+	if err := db.EndCall("c3", "", start.Add(2*time.Hour+time.Second)); err != nil {
+		t.Fatalf("EndCall: %v", err)
+	}
+
+	// A terminate that lands while the reject is in flight marks the call
+	// missed first; the reject still wins.
+	if err := db.EndCall("c4", "", start.Add(3*time.Hour+time.Second)); err != nil {
+		t.Fatalf("EndCall: %v", err)
+	}
+	if err := db.RejectCall("c4", CallAutoRejected, start.Add(3*time.Hour+2*time.Second)); err != nil {
+		t.Fatalf("RejectCall: %v", err)
+	}
+
+	calls, err := db.ListCalls(ListCallsParams{})
+	if err != nil || len(calls) != 4 {
+		t.Fatalf("expected 4 calls, got %+v err=%v", calls, err)
+	}
+	outcomes := map[string]string{}
+	for _, c := range calls {
+		outcomes[c.CallID] = c.Outcome
+	}
+	if outcomes["c1"] != CallAnswered || outcomes["c2"] != CallMissed || outcomes["c3"] != CallAutoRejected || outcomes["c4"] != CallAutoRejected {
+		t.Fatalf("unexpected outcomes %v", outcomes)
+	}
+	if !calls[0].EndedAt.Equal(start.Add(3*time.Hour + time.Second)) {
+		t.Fatalf("expected the terminate time to be kept, got %+v", calls[0])
+	}
+	c1 := calls[3]
+	if c1.CallID != "c1" || !c1.Video || !c1.StartedAt.Equal(start) || c1.PeerName != "Alice" || c1.EndedAt.Sub(c1.AcceptedAt) != time.Minute {
+		t.Fatalf("unexpected first call %+v", c1)
+	}
+
+	missed, err := db.ListCalls(ListCallsParams{Peer: alice, Outcome: CallMissed})
+	if err != nil || len(missed) != 1 || missed[0].CallID != "c2" || missed[0].EndReason != "timeout" {
+		t.Fatalf("expected alice's missed call, got %+v err=%v", missed, err)
+	}
+	voice := false
+	after := start.Add(30 * time.Minute)
+	got, err := db.ListCalls(ListCallsParams{Video: &voice, After: &after})
+	if err != nil || len(got) != 1 || got[0].CallID != "c3" {
+		t.Fatalf("expected the later voice call, got %+v err=%v", got, err)
+	}
+}
+
+func TestEditMessageOnlyBySender(t *testing.T) {
+	db := openTestDB(t)
+	group := "120363000000000001@g.us"
+	alice := "111@s.whatsapp.net"
+	now := time.Now().UTC()
+	if err := db.UpsertChat(group, "group", "Team", now); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	for _, p := range []UpsertMessageParams{
+		{ChatJID: group, MsgID: "a1", SenderJID: alice, Timestamp: now, Text: "meet at 5"},
+		{ChatJID: group, MsgID: "me1", SenderJID: "999@s.whatsapp.net", FromMe: true, Timestamp: now, Text: "ok"},
+	} {
+		if err := db.UpsertMessage(p); err != nil {
+			t.Fatalf("UpsertMessage: %v", err)
+		}
+	}
+
+	if found, err := db.EditMessage(group, "a1", "222@s.whatsapp.net", false, "meet at 9", now); !found || !errors.Is(err, ErrNotOriginalSender) {
+		t.Fatalf("expected an edit by another member to be refused, got found=%v err=%v", found, err)
+	}
+	if found, err := db.EditMessage(group, "me1", alice, false, "not ok", now); !found || !errors.Is(err, ErrNotOriginalSender) {
+		t.Fatalf("expected an edit of our message by alice to be refused, got found=%v err=%v", found, err)
+	}
+	if found, err := db.EditMessage(group, "a1", "111:3@s.whatsapp.net", false, "meet at 6", now); !found || err != nil {
+		t.Fatalf("EditMessage from alice's other device: found=%v err=%v", found, err)
+	}
+	if found, err := db.EditMessage(group, "missing", alice, false, "x", now); found || err != nil {
+		t.Fatalf("expected a missing message to be reported, got found=%v err=%v", found, err)
+	}
+	m, err := db.GetMessage(group, "a1")
+	if err != nil || m.Text != "meet at 6" || m.EditedAt.IsZero() {
+		t.Fatalf("expected alice's edit to apply, got %+v err=%v", m, err)
+	}
+
+	// The original and the edit may name the sender by LID and by phone number.
+	if err := db.SaveLIDMapping("777@lid", alice); err != nil {
+		t.Fatalf("SaveLIDMapping: %v", err)
+	}
+	if found, err := db.EditMessage(group, "a1", "777:2@lid", false, "meet at 7", now); !found || err != nil {
+		t.Fatalf("EditMessage by alice's LID: found=%v err=%v", found, err)
+	}
+	// A row stored under a LID before its mapping was rewritten.
+	if err := db.UpsertMessage(UpsertMessageParams{ChatJID: group, MsgID: "b1", SenderJID: "888@lid", Timestamp: now, Text: "lunch?"}); err != nil {
+		t.Fatalf("UpsertMessage: %v", err)
+	}
+	if _, err := db.sql.Exec(`INSERT INTO lid_map(lid, pn, updated_at) VALUES ('888@lid', '222@s.whatsapp.net', 0)`); err != nil {
+		t.Fatalf("insert lid_map: %v", err)
+	}
+	if found, err := db.EditMessage(group, "b1", "222@s.whatsapp.net", false, "dinner?", now); !found || err != nil {
+		t.Fatalf("EditMessage by phone number of a LID-stored message: found=%v err=%v", found, err)
+	}
+	if found, err := db.EditMessage(group, "b1", "333@s.whatsapp.net", false, "x", now); !found || !errors.Is(err, ErrNotOriginalSender) {
+		t.Fatalf("expected a different sender to still be refused, got found=%v err=%v", found, err)
+	}
+}
+
+func TestForwardedFlagSurvivesReupsert(t *testing.T) {
+	db := openTestDB(t)
+	chat := "123@s.whatsapp.net"
+	now := time.Now().UTC()
+	if err := db.UpsertChat(chat, "dm", "Alice", now); err != nil {
+		t.Fatalf("UpsertChat: %v", err)
+	}
+	for _, p := range []UpsertMessageParams{
+		{ChatJID: chat, MsgID: "f1", Timestamp: now, Text: "chain letter", Forwarded: true, ForwardingScore: 6},
+		{ChatJID: chat, MsgID: "f1", Timestamp: now, Text: "chain letter"},
+		{ChatJID: chat, MsgID: "t1", Timestamp: now, Text: "hi"},
+	} {
+		if err := db.UpsertMessage(p); err != nil {
+			t.Fatalf("UpsertMessage: %v", err)
+		}
+	}
+	if got := countRows(t, db.sql, "SELECT COUNT(*) FROM messages WHERE msg_id = 'f1' AND is_forwarded = 1 AND forwarding_score = 6"); got != 1 {
+		t.Fatalf("expected forwarded flag and score to survive a re-upsert")
+	}
+	msgs, err := db.ListMessages(ListMessagesParams{ChatJID: chat, ExcludeForwarded: true})
+	if err != nil || len(msgs) != 1 || msgs[0].MsgID != "t1" {
+		t.Fatalf("expected only the original message, got %+v err=%v", msgs, err)
+	}
+}
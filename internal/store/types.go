@@ -12,14 +12,28 @@ type Chat struct {
 	Kind          string
 	Name          string
 	LastMessageTS time.Time
+	// EphemeralExpiration is the chat's disappearing-message timer in
+	// seconds (0 = off). Only filled in by GetChat.
+	EphemeralExpiration uint32
 }
 
 type Group struct {
+	JID        string
+	Name       string
+	OwnerJID   string
+	IsParent   bool
+	ParentJID  string
+	ParentName string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type Community struct {
 	JID       string
 	Name      string
 	OwnerJID  string
+	SubGroups int
 	CreatedAt time.Time
-	UpdatedAt time.Time
 }
 
 type GroupParticipant struct {
@@ -29,6 +43,17 @@ type GroupParticipant struct {
 	UpdatedAt time.Time
 }
 
+type GroupMember struct {
+	GroupJID  string
+	UserJID   string
+	Role      string
+	Phone     string
+	Name      string
+	Alias     string
+	Tags      []string
+	UpdatedAt time.Time
+}
+
 type MediaDownloadInfo struct {
 	ChatJID       string
 	ChatName      string
@@ -56,6 +81,21 @@ type Message struct {
 	DisplayText string
 	MediaType   string
 	Snippet     string
+	Forwarded   bool
+	// Details below are only filled in by GetMessage.
+	ForwardingScore uint32
+	DurationSeconds uint32
+	PTT             bool
+	Width           int
+	Height          int
+	LinkURL         string
+	LinkTitle       string
+	LinkDescription string
+	// EphemeralExpiration is the disappearing timer (seconds) the message was
+	// sent with.
+	EphemeralExpiration uint32
+	ViewOnce            bool
+	EditedAt            time.Time
 }
 
 type MessageInfo struct {
@@ -68,14 +108,129 @@ type MessageInfo struct {
 }
 
 type Contact struct {
+	JID          string
+	Phone        string
+	Name         string
+	PushName     string
+	FullName     string
+	FirstName    string
+	BusinessName string
+	Alias        string
+	Notes        string
+	Tags         []string
+	About        string
+	AvatarPath   string
+	Blocked      bool
+	UpdatedAt    time.Time
+}
+
+type BusinessProfile struct {
+	JID           string
+	Name          string
+	Description   string
+	Address       string
+	Email         string
+	Website       string
+	Categories    []string
+	HoursTimeZone string
+	Hours         []BusinessHours
+	FetchedAt     time.Time
+}
+
+type BusinessHours struct {
+	Day   string `json:"day"`
+	Mode  string `json:"mode"`
+	Open  string `json:"open,omitempty"`
+	Close string `json:"close,omitempty"`
+}
+
+type BlockedContact struct {
 	JID       string
 	Phone     string
 	Name      string
 	Alias     string
-	Tags      []string
+	BlockedAt time.Time
+}
+
+type Avatar struct {
+	JID       string
+	PictureID string
+	Path      string
 	UpdatedAt time.Time
 }
 
+// Outbox item states. Items in OutboxSending were interrupted mid-delivery and
+// are retried with the same message ID.
+const (
+	OutboxPending  = "pending"
+	OutboxSending  = "sending"
+	OutboxSent     = "sent"
+	OutboxFailed   = "failed"
+	OutboxCanceled = "canceled"
+)
+
+type OutboxItem struct {
+	ID            int64
+	ChatJID       string
+	Kind          string // "text", "link" (text with a link preview) or "file"
+	Text          string // message text, or file caption
+	FilePath      string
+	Filename      string
+	MimeType      string
+	MsgID         string
+	Status        string
+	Attempts      int
+	SendAt        time.Time
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	SentAt        time.Time
+}
+
+// Call outcomes. A call stays CallRinging until it is answered, rejected or
+// ends unanswered (CallMissed).
+const (
+	CallRinging      = "ringing"
+	CallAnswered     = "answered"
+	CallMissed       = "missed"
+	CallRejected     = "rejected"
+	CallAutoRejected = "auto-rejected" // rejected by sync --reject-calls
+)
+
+type Call struct {
+	CallID     string
+	PeerJID    string
+	PeerName   string
+	GroupJID   string
+	Direction  string // "incoming" or "outgoing"
+	Video      bool
+	Outcome    string
+	StartedAt  time.Time
+	AcceptedAt time.Time
+	EndedAt    time.Time
+	EndReason  string
+}
+
+// SendKey records the message ID reserved for an idempotency key and, once the
+// send succeeded, its result. SentAt is zero while the outcome is unknown.
+type SendKey struct {
+	Key       string
+	ChatJID   string
+	MsgID     string
+	Result    string
+	CreatedAt time.Time
+	SentAt    time.Time
+}
+
+type NumberCheck struct {
+	Phone        string
+	JID          string
+	Registered   bool
+	Business     bool
+	BusinessName string
+	CheckedAt    time.Time
+}
+
 func unix(t time.Time) int64 {
 	if t.IsZero() {
 		return 0
@@ -105,6 +260,13 @@ func nullIfEmpty(s string) interface{} {
 	return s
 }
 
+func nullIfZero(n int64) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
 func (d *DB) HasFTS() bool { return d.ftsEnabled }
 
 func IsNotFound(err error) bool {
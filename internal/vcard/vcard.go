@@ -0,0 +1,251 @@
+package vcard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Phone struct {
+	Number string
+	// WAID is the WhatsApp user (digits) from the TEL "waid" parameter.
+	WAID string
+	Type string
+}
+
+type Card struct {
+	FullName   string
+	Nickname   string
+	Org        string
+	Phones     []Phone
+	Emails     []string
+	Note       string
+	Categories []string
+}
+
+// Parse reads all BEGIN:VCARD … END:VCARD blocks from r (vCard 2.1/3.0/4.0).
+func Parse(r io.Reader) ([]Card, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Card
+	var cur *Card
+	for _, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+		switch name {
+		case "BEGIN":
+			if strings.EqualFold(value, "VCARD") {
+				cur = &Card{}
+			}
+			continue
+		case "END":
+			if strings.EqualFold(value, "VCARD") && cur != nil {
+				out = append(out, *cur)
+				cur = nil
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		switch name {
+		case "FN":
+			cur.FullName = unescape(value)
+		case "N":
+			if cur.FullName == "" {
+				cur.FullName = nameFromN(value)
+			}
+		case "NICKNAME":
+			cur.Nickname = unescape(firstValue(value))
+		case "ORG":
+			cur.Org = strings.TrimSpace(strings.ReplaceAll(unescape(value), ";", " "))
+		case "TEL":
+			p := Phone{Number: strings.TrimPrefix(strings.TrimSpace(value), "tel:")}
+			p.WAID = params["WAID"]
+			p.Type = strings.ToLower(params["TYPE"])
+			cur.Phones = append(cur.Phones, p)
+		case "EMAIL":
+			cur.Emails = append(cur.Emails, strings.TrimSpace(value))
+		case "NOTE":
+			cur.Note = unescape(value)
+		case "CATEGORIES":
+			for _, c := range splitEscaped(value, ',') {
+				if c = strings.TrimSpace(unescape(c)); c != "" {
+					cur.Categories = append(cur.Categories, c)
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// Write encodes c as a vCard 3.0 block.
+func Write(w io.Writer, c Card) error {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCARD\r\n")
+	b.WriteString("VERSION:3.0\r\n")
+	fn := c.FullName
+	if fn == "" && len(c.Phones) > 0 {
+		fn = c.Phones[0].Number
+	}
+	fmt.Fprintf(&b, "FN:%s\r\n", escape(fn))
+	fmt.Fprintf(&b, "N:%s;;;;\r\n", escape(fn))
+	if c.Nickname != "" {
+		fmt.Fprintf(&b, "NICKNAME:%s\r\n", escape(c.Nickname))
+	}
+	if c.Org != "" {
+		fmt.Fprintf(&b, "ORG:%s\r\n", escape(c.Org))
+	}
+	for _, p := range c.Phones {
+		typ := p.Type
+		if typ == "" {
+			typ = "CELL"
+		}
+		if p.WAID != "" {
+			fmt.Fprintf(&b, "TEL;type=%s;waid=%s:%s\r\n", strings.ToUpper(typ), p.WAID, p.Number)
+		} else {
+			fmt.Fprintf(&b, "TEL;type=%s:%s\r\n", strings.ToUpper(typ), p.Number)
+		}
+	}
+	for _, e := range c.Emails {
+		fmt.Fprintf(&b, "EMAIL:%s\r\n", e)
+	}
+	if c.Note != "" {
+		fmt.Fprintf(&b, "NOTE:%s\r\n", escape(c.Note))
+	}
+	if len(c.Categories) > 0 {
+		escaped := make([]string, 0, len(c.Categories))
+		for _, cat := range c.Categories {
+			escaped = append(escaped, escape(cat))
+		}
+		fmt.Fprintf(&b, "CATEGORIES:%s\r\n", strings.Join(escaped, ","))
+	}
+	b.WriteString("END:VCARD\r\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String returns c encoded as a single vCard.
+func (c Card) String() string {
+	var b strings.Builder
+	_ = Write(&b, c)
+	return b.String()
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+func splitLine(line string) (name string, params map[string]string, value string, ok bool) {
+	idx := strings.IndexByte(line, ':')
+	if idx < 0 {
+		return "", nil, "", false
+	}
+	head := line[:idx]
+	value = line[idx+1:]
+
+	parts := strings.Split(head, ";")
+	name = strings.ToUpper(strings.TrimSpace(parts[0]))
+	// Drop group prefixes such as "item1.TEL".
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	params = map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, found := strings.Cut(p, "=")
+		k = strings.ToUpper(strings.TrimSpace(k))
+		if !found {
+			// vCard 2.1 bare type, e.g. TEL;CELL:...
+			params["TYPE"] = k
+			continue
+		}
+		v = strings.Trim(strings.TrimSpace(v), `"`)
+		if k == "TYPE" && params["TYPE"] != "" {
+			params["TYPE"] += "," + v
+			continue
+		}
+		params[k] = v
+	}
+	return name, params, value, true
+}
+
+func nameFromN(value string) string {
+	fields := splitEscaped(value, ';')
+	var parts []string
+	// N is family;given;additional;prefix;suffix.
+	for _, idx := range []int{3, 1, 2, 0, 4} {
+		if idx < len(fields) {
+			if f := strings.TrimSpace(unescape(fields[idx])); f != "" {
+				parts = append(parts, f)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func firstValue(value string) string {
+	fields := splitEscaped(value, ',')
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(fields[0])
+}
+
+func splitEscaped(s string, sep byte) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "\r\n", `\n`, "\n", `\n`, ",", `\,`, ";", `\;`)
+	return r.Replace(s)
+}
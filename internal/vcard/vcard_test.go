@@ -0,0 +1,71 @@
+package vcard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHandlesFoldingParamsAndEscapes(t *testing.T) {
+	in := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"N:Doe;Jane;;Dr.;",
+		"item1.TEL;type=CELL;waid=15550102030:+1 555-010-2030",
+		"TEL;HOME:+44 20 7946 0000",
+		"NOTE:first line\\nsecond\\, with comma and a long ",
+		" folded tail",
+		"CATEGORIES:vip,supplier\\,eu",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"FN:Bob",
+		"NICKNAME:Bobby,B",
+		"END:VCARD",
+	}, "\r\n")
+
+	cards, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("expected 2 cards, got %d", len(cards))
+	}
+	c := cards[0]
+	if c.FullName != "Dr. Jane Doe" {
+		t.Fatalf("unexpected name from N: %q", c.FullName)
+	}
+	if len(c.Phones) != 2 || c.Phones[0].WAID != "15550102030" || c.Phones[1].Type != "home" {
+		t.Fatalf("unexpected phones: %+v", c.Phones)
+	}
+	if c.Note != "first line\nsecond, with comma and a long folded tail" {
+		t.Fatalf("unexpected note: %q", c.Note)
+	}
+	if len(c.Categories) != 2 || c.Categories[1] != "supplier,eu" {
+		t.Fatalf("unexpected categories: %v", c.Categories)
+	}
+	if cards[1].FullName != "Bob" || cards[1].Nickname != "Bobby" {
+		t.Fatalf("unexpected second card: %+v", cards[1])
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	want := Card{
+		FullName:   "Acme; Sales",
+		Phones:     []Phone{{Number: "+15550102030", WAID: "15550102030"}},
+		Note:       "line one\nline two",
+		Categories: []string{"vip", "b2b"},
+	}
+	cards, err := Parse(strings.NewReader(want.String()))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(cards) != 1 {
+		t.Fatalf("expected 1 card, got %d", len(cards))
+	}
+	got := cards[0]
+	if got.FullName != want.FullName || got.Note != want.Note || len(got.Categories) != 2 {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+	if len(got.Phones) != 1 || got.Phones[0].WAID != "15550102030" || got.Phones[0].Number != "+15550102030" {
+		t.Fatalf("unexpected phones: %+v", got.Phones)
+	}
+}
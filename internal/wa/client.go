@@ -11,6 +11,7 @@ import (
 
 	"github.com/mdp/qrterminal/v3"
 	"go.mau.fi/whatsmeow"
+	waBinary "go.mau.fi/whatsmeow/binary"
 	waProto "go.mau.fi/whatsmeow/binary/proto"
 	"go.mau.fi/whatsmeow/store/sqlstore"
 	"go.mau.fi/whatsmeow/types"
@@ -104,7 +105,7 @@ func (c *Client) Connect(ctx context.Context, opts ConnectOptions) error {
 
 	authed := cli.Store != nil && cli.Store.ID != nil
 	if !authed && !opts.AllowQR {
-		return fmt.Errorf("not authenticated; run `wacli auth`")
+		return ErrNotAuthenticated
 	}
 
 	var qrChan <-chan whatsmeow.QRChannelItem
@@ -173,7 +174,7 @@ func (c *Client) SendText(ctx context.Context, to types.JID, text string) (types
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return "", fmt.Errorf("not connected")
+		return "", ErrNotConnected
 	}
 	msg := &waProto.Message{Conversation: &text}
 	resp, err := cli.SendMessage(ctx, to, msg)
@@ -188,7 +189,7 @@ func (c *Client) SendProtoMessage(ctx context.Context, to types.JID, msg *waProt
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return "", fmt.Errorf("not connected")
+		return "", ErrNotConnected
 	}
 	resp, err := cli.SendMessage(ctx, to, msg)
 	if err != nil {
@@ -197,12 +198,38 @@ func (c *Client) SendProtoMessage(ctx context.Context, to types.JID, msg *waProt
 	return resp.ID, nil
 }
 
+// SendProtoMessageWithID sends msg using a caller-chosen message ID, so a
+// retried send of the same message is recognised as a duplicate.
+func (c *Client) SendProtoMessageWithID(ctx context.Context, to types.JID, msg *waProto.Message, id types.MessageID) (types.MessageID, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return "", ErrNotConnected
+	}
+	resp, err := cli.SendMessage(ctx, to, msg, whatsmeow.SendRequestExtra{ID: id})
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+func (c *Client) GenerateMessageID() (types.MessageID, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return "", ErrNotConnected
+	}
+	return cli.GenerateMessageID(), nil
+}
+
 func (c *Client) Upload(ctx context.Context, data []byte, mediaType whatsmeow.MediaType) (whatsmeow.UploadResponse, error) {
 	c.mu.Lock()
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return whatsmeow.UploadResponse{}, fmt.Errorf("not connected")
+		return whatsmeow.UploadResponse{}, ErrNotConnected
 	}
 	return cli.Upload(ctx, data, mediaType)
 }
@@ -212,7 +239,7 @@ func (c *Client) DecryptReaction(ctx context.Context, reaction *events.Message)
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return nil, fmt.Errorf("not connected")
+		return nil, ErrNotConnected
 	}
 	return cli.DecryptReaction(ctx, reaction)
 }
@@ -222,7 +249,7 @@ func (c *Client) RequestHistorySyncOnDemand(ctx context.Context, lastKnown types
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return "", fmt.Errorf("not connected")
+		return "", ErrNotConnected
 	}
 	if count <= 0 {
 		count = 50
@@ -236,7 +263,7 @@ func (c *Client) RequestHistorySyncOnDemand(ctx context.Context, lastKnown types
 		ownID = cli.Store.ID.ToNonAD()
 	}
 	if ownID.IsEmpty() {
-		return "", fmt.Errorf("not authenticated; run `wacli auth`")
+		return "", ErrNotAuthenticated
 	}
 
 	msg := cli.BuildHistorySyncRequest(&lastKnown, count)
@@ -258,6 +285,207 @@ func ParseUserOrJID(s string) (types.JID, error) {
 	return types.JID{User: s, Server: types.DefaultUserServer}, nil
 }
 
+// NormalizePhone strips formatting from an international phone number and
+// returns its digits (no leading "+").
+func NormalizePhone(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("phone number is required")
+	}
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("invalid phone number %q", s)
+		}
+	}
+	digits := strings.TrimPrefix(b.String(), "00")
+	if len(digits) < 7 || len(digits) > 15 {
+		return "", fmt.Errorf("invalid phone number %q", s)
+	}
+	return digits, nil
+}
+
+func (c *Client) IsOnWhatsApp(ctx context.Context, phones []string) ([]types.IsOnWhatsAppResponse, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.IsOnWhatsApp(ctx, phones)
+}
+
+func (c *Client) GetUserInfo(ctx context.Context, jids []types.JID) (map[types.JID]types.UserInfo, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.GetUserInfo(ctx, jids)
+}
+
+// GetProfilePictureInfo returns the full-size picture of a user or group.
+// With a non-empty existingID it returns (nil, nil) if the picture is unchanged.
+func (c *Client) GetProfilePictureInfo(ctx context.Context, jid types.JID, existingID string) (*types.ProfilePictureInfo, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.GetProfilePictureInfo(ctx, jid, &whatsmeow.GetProfilePictureParams{ExistingID: existingID})
+}
+
+func (c *Client) GetBlocklist(ctx context.Context) (*types.Blocklist, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.GetBlocklist(ctx)
+}
+
+// GetStatusPrivacy returns who status updates are sent to; the first entry is
+// the default the phone uses.
+func (c *Client) GetStatusPrivacy(ctx context.Context) ([]types.StatusPrivacy, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.GetStatusPrivacy(ctx)
+}
+
+// RejectCall declines an incoming call; from is the caller.
+func (c *Client) RejectCall(ctx context.Context, from types.JID, callID string) error {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return ErrNotConnected
+	}
+	return cli.RejectCall(ctx, from, callID)
+}
+
+// OwnJID returns the phone-number JID of the logged-in account (without
+// device), or an empty JID before pairing.
+func (c *Client) OwnJID() types.JID {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || cli.Store == nil || cli.Store.ID == nil {
+		return types.JID{}
+	}
+	return cli.Store.ID.ToNonAD()
+}
+
+// UpdateBlocklist blocks or unblocks jid and returns the resulting blocklist.
+func (c *Client) UpdateBlocklist(ctx context.Context, jid types.JID, block bool) (*types.Blocklist, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	action := events.BlocklistChangeActionUnblock
+	if block {
+		action = events.BlocklistChangeActionBlock
+	}
+	return cli.UpdateBlocklist(ctx, jid, action)
+}
+
+// GetPNForLID looks up the phone-number JID for a LID in the session store.
+// It returns an empty JID if the mapping is unknown.
+func (c *Client) GetPNForLID(ctx context.Context, lid types.JID) (types.JID, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || cli.Store == nil || cli.Store.LIDs == nil {
+		return types.JID{}, fmt.Errorf("lid store not available")
+	}
+	return cli.Store.LIDs.GetPNForLID(ctx, lid.ToNonAD())
+}
+
+// BusinessProfile is whatsmeow's business profile plus the description and
+// websites, which whatsmeow leaves out.
+type BusinessProfile struct {
+	types.BusinessProfile
+	Description string
+	Websites    []string
+}
+
+// GetBusinessProfile returns whatsmeow's business profile, plus the
+// description and websites when they can be read (see businessExtras).
+func (c *Client) GetBusinessProfile(ctx context.Context, jid types.JID) (*BusinessProfile, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	bp, err := cli.GetBusinessProfile(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	out := &BusinessProfile{BusinessProfile: *bp}
+	out.Description, out.Websites = businessExtras(ctx, cli, jid)
+	return out, nil
+}
+
+// businessExtras fetches the description and websites, which whatsmeow's
+// parser drops. It repeats whatsmeow's business_profile query through the
+// deprecated raw IQ API to get at the response node, so it is best-effort:
+// any failure leaves both empty instead of failing the profile lookup. Remove
+// it once whatsmeow parses these fields itself.
+func businessExtras(ctx context.Context, cli *whatsmeow.Client, jid types.JID) (string, []string) {
+	resp, err := cli.DangerousInternals().SendIQ(ctx, whatsmeow.DangerousInfoQuery{
+		Namespace: "w:biz",
+		Type:      "get",
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag:   "business_profile",
+			Attrs: waBinary.Attrs{"v": "244"},
+			Content: []waBinary.Node{{
+				Tag:   "profile",
+				Attrs: waBinary.Attrs{"jid": jid},
+			}},
+		}},
+	})
+	if err != nil {
+		return "", nil
+	}
+	node, ok := resp.GetOptionalChildByTag("business_profile")
+	if !ok {
+		return "", nil
+	}
+	return parseBusinessExtras(&node)
+}
+
+// parseBusinessExtras reads the description and websites of a
+// business_profile node.
+func parseBusinessExtras(node *waBinary.Node) (string, []string) {
+	profile := node.GetChildByTag("profile")
+	desc, _ := profile.GetChildByTag("description").Content.([]byte)
+	var websites []string
+	for _, child := range profile.GetChildren() {
+		if child.Tag != "website" {
+			continue
+		}
+		if site, _ := child.Content.([]byte); strings.TrimSpace(string(site)) != "" {
+			websites = append(websites, strings.TrimSpace(string(site)))
+		}
+	}
+	return strings.TrimSpace(string(desc)), websites
+}
+
 func IsGroupJID(jid types.JID) bool {
 	return jid.Server == types.GroupServer
 }
@@ -334,7 +562,7 @@ func (c *Client) GetGroupInfo(ctx context.Context, jid types.JID) (*types.GroupI
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return nil, fmt.Errorf("not connected")
+		return nil, ErrNotConnected
 	}
 	return cli.GetGroupInfo(ctx, jid)
 }
@@ -3,6 +3,7 @@ package wa
 import (
 	"testing"
 
+	waBinary "go.mau.fi/whatsmeow/binary"
 	"go.mau.fi/whatsmeow/types"
 )
 
@@ -41,3 +42,40 @@ func TestBestContactName(t *testing.T) {
 		t.Fatalf("expected push name")
 	}
 }
+
+func TestNormalizePhone(t *testing.T) {
+	for in, want := range map[string]string{
+		"+1 (555) 010-2030": "15550102030",
+		"0049 30 1234567":   "49301234567",
+		"447700900123":      "447700900123",
+	} {
+		got, err := NormalizePhone(in)
+		if err != nil {
+			t.Fatalf("NormalizePhone(%q): %v", in, err)
+		}
+		if got != want {
+			t.Fatalf("NormalizePhone(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for _, in := range []string{"", "123", "+1555abc0000", "1+5550102030"} {
+		if _, err := NormalizePhone(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestParseBusinessExtras(t *testing.T) {
+	node := waBinary.Node{Tag: "business_profile", Content: []waBinary.Node{{
+		Tag: "profile",
+		Content: []waBinary.Node{
+			{Tag: "address", Content: []byte("1 Main St")},
+			{Tag: "description", Content: []byte(" Hardware and garden supplies ")},
+			{Tag: "website", Content: []byte("https://acme.example")},
+			{Tag: "website", Content: []byte("https://shop.acme.example")},
+		},
+	}}}
+	desc, websites := parseBusinessExtras(&node)
+	if desc != "Hardware and garden supplies" || len(websites) != 2 || websites[1] != "https://shop.acme.example" {
+		t.Fatalf("unexpected extras %q %v", desc, websites)
+	}
+}
@@ -0,0 +1,35 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"go.mau.fi/whatsmeow"
+)
+
+var (
+	ErrNotConnected     = errors.New("not connected")
+	ErrNotAuthenticated = errors.New("not authenticated; run `wacli auth`")
+)
+
+// IsTransient reports whether err is a connectivity problem or timeout after
+// which the same request may succeed if repeated.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrNotConnected) ||
+		errors.Is(err, whatsmeow.ErrNotConnected) ||
+		errors.Is(err, whatsmeow.ErrIQTimedOut) ||
+		errors.Is(err, whatsmeow.ErrMessageTimedOut) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var disconnected *whatsmeow.DisconnectedError
+	if errors.As(err, &disconnected) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,29 @@
+package wa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mau.fi/whatsmeow"
+)
+
+func TestIsTransient(t *testing.T) {
+	for _, err := range []error{
+		ErrNotConnected,
+		whatsmeow.ErrIQTimedOut,
+		fmt.Errorf("send: %w", whatsmeow.ErrMessageTimedOut),
+		context.DeadlineExceeded,
+		whatsmeow.ErrIQDisconnected,
+	} {
+		if !IsTransient(err) {
+			t.Fatalf("expected %v to be transient", err)
+		}
+	}
+	for _, err := range []error{nil, errors.New("boom"), fmt.Errorf("%w 403", whatsmeow.ErrServerReturnedError)} {
+		if IsTransient(err) {
+			t.Fatalf("expected %v to be permanent", err)
+		}
+	}
+}
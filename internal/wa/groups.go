@@ -3,6 +3,7 @@ package wa
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/types"
@@ -13,7 +14,7 @@ func (c *Client) GetJoinedGroups(ctx context.Context) ([]*types.GroupInfo, error
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return nil, fmt.Errorf("not connected")
+		return nil, ErrNotConnected
 	}
 	return cli.GetJoinedGroups(ctx)
 }
@@ -23,7 +24,7 @@ func (c *Client) SetGroupName(ctx context.Context, jid types.JID, name string) e
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return fmt.Errorf("not connected")
+		return ErrNotConnected
 	}
 	return cli.SetGroupName(ctx, jid, name)
 }
@@ -42,7 +43,7 @@ func (c *Client) UpdateGroupParticipants(ctx context.Context, group types.JID, u
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return nil, fmt.Errorf("not connected")
+		return nil, ErrNotConnected
 	}
 
 	var a whatsmeow.ParticipantChange
@@ -67,17 +68,62 @@ func (c *Client) GetGroupInviteLink(ctx context.Context, group types.JID, reset
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return "", fmt.Errorf("not connected")
+		return "", ErrNotConnected
 	}
 	return cli.GetGroupInviteLink(ctx, group, reset)
 }
 
+func (c *Client) GetGroupInfoFromLink(ctx context.Context, code string) (*types.GroupInfo, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.GetGroupInfoFromLink(ctx, code)
+}
+
+// ParseInviteCode accepts a bare invite code or a full
+// https://chat.whatsapp.com/... link and returns the code.
+func ParseInviteCode(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("invite code is required")
+	}
+	lower := strings.ToLower(s)
+	for _, prefix := range []string{"https://", "http://"} {
+		if strings.HasPrefix(lower, prefix) {
+			s = s[len(prefix):]
+			lower = lower[len(prefix):]
+			break
+		}
+	}
+	if strings.HasPrefix(lower, "www.") {
+		s = s[len("www."):]
+		lower = lower[len("www."):]
+	}
+	if strings.HasPrefix(lower, "chat.whatsapp.com/") {
+		s = s[len("chat.whatsapp.com/"):]
+		s = strings.TrimPrefix(s, "invite/")
+	} else if strings.Contains(s, "/") {
+		return "", fmt.Errorf("not a WhatsApp invite link: %s", s)
+	}
+	if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.Trim(s, "/")
+	if s == "" || strings.Contains(s, "/") {
+		return "", fmt.Errorf("invalid invite code")
+	}
+	return s, nil
+}
+
 func (c *Client) JoinGroupWithLink(ctx context.Context, code string) (types.JID, error) {
 	c.mu.Lock()
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return types.JID{}, fmt.Errorf("not connected")
+		return types.JID{}, ErrNotConnected
 	}
 	return cli.JoinGroupWithLink(ctx, code)
 }
@@ -87,7 +133,47 @@ func (c *Client) LeaveGroup(ctx context.Context, group types.JID) error {
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return fmt.Errorf("not connected")
+		return ErrNotConnected
 	}
 	return cli.LeaveGroup(ctx, group)
 }
+
+func (c *Client) CreateGroup(ctx context.Context, req whatsmeow.ReqCreateGroup) (*types.GroupInfo, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.CreateGroup(ctx, req)
+}
+
+func (c *Client) LinkGroup(ctx context.Context, parent, child types.JID) error {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return ErrNotConnected
+	}
+	return cli.LinkGroup(ctx, parent, child)
+}
+
+func (c *Client) UnlinkGroup(ctx context.Context, parent, child types.JID) error {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return ErrNotConnected
+	}
+	return cli.UnlinkGroup(ctx, parent, child)
+}
+
+func (c *Client) GetSubGroups(ctx context.Context, community types.JID) ([]*types.GroupLinkTarget, error) {
+	c.mu.Lock()
+	cli := c.client
+	c.mu.Unlock()
+	if cli == nil || !cli.IsConnected() {
+		return nil, ErrNotConnected
+	}
+	return cli.GetSubGroups(ctx, community)
+}
@@ -0,0 +1,28 @@
+package wa
+
+import "testing"
+
+func TestParseInviteCode(t *testing.T) {
+	for _, in := range []string{
+		"AbCdEf123",
+		" AbCdEf123 ",
+		"https://chat.whatsapp.com/AbCdEf123",
+		"http://chat.whatsapp.com/AbCdEf123/",
+		"chat.whatsapp.com/AbCdEf123",
+		"https://www.chat.whatsapp.com/invite/AbCdEf123?utm=x",
+	} {
+		code, err := ParseInviteCode(in)
+		if err != nil {
+			t.Fatalf("ParseInviteCode(%q): %v", in, err)
+		}
+		if code != "AbCdEf123" {
+			t.Fatalf("ParseInviteCode(%q) = %q", in, code)
+		}
+	}
+
+	for _, in := range []string{"", "https://example.com/AbCdEf123", "https://chat.whatsapp.com/"} {
+		if _, err := ParseInviteCode(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
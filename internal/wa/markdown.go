@@ -0,0 +1,83 @@
+package wa
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTextLength is the longest text message WhatsApp accepts, in characters.
+const MaxTextLength = 65536
+
+// CheckTextLength rejects text longer than MaxTextLength.
+func CheckTextLength(text string) error {
+	if n := utf8.RuneCountInString(text); n > MaxTextLength {
+		return fmt.Errorf("message is %d characters; WhatsApp allows at most %d", n, MaxTextLength)
+	}
+	return nil
+}
+
+var (
+	mdFence      = regexp.MustCompile("^\\s*```")
+	mdHeading    = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+	mdStarBullet = regexp.MustCompile(`^(\s*)[*+]\s+`)
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\((\S+?)\)`)
+	mdBold       = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*|__(\S(?:.*?\S)?)__`)
+	mdItalic     = regexp.MustCompile(`(^|[^\w*])\*(\S(?:[^*]*?\S)?)\*`)
+	mdStrike     = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+)
+
+// boldMark stands in for WhatsApp's "*" while single-star Markdown italics
+// are rewritten, so converted bold text isn't mistaken for italics.
+const boldMark = "\x01"
+
+// MarkdownToWhatsApp rewrites common Markdown to WhatsApp formatting:
+// **bold**/__bold__ and headings become *bold*, *italic* becomes _italic_,
+// ~~strike~~ becomes ~strike~, `code` becomes ```code```, and [text](url)
+// becomes "text (url)". Fenced code blocks are left as they are.
+func MarkdownToWhatsApp(s string) string {
+	lines := strings.Split(s, "\n")
+	inFence := false
+	for i, line := range lines {
+		if mdFence.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[i] = markdownLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func markdownLine(line string) string {
+	if m := mdHeading.FindStringSubmatch(line); m != nil {
+		line = boldMark + m[1] + boldMark
+	}
+	line = mdStarBullet.ReplaceAllString(line, "$1- ")
+
+	// Convert the text between inline code spans; the spans themselves are
+	// only re-fenced.
+	var b strings.Builder
+	last := 0
+	for _, loc := range mdInlineCode.FindAllStringSubmatchIndex(line, -1) {
+		b.WriteString(markdownInline(line[last:loc[0]]))
+		b.WriteString("```" + line[loc[2]:loc[3]] + "```")
+		last = loc[1]
+	}
+	b.WriteString(markdownInline(line[last:]))
+	return strings.ReplaceAll(b.String(), boldMark, "*")
+}
+
+func markdownInline(s string) string {
+	s = mdLink.ReplaceAllString(s, "$1 ($2)")
+	s = mdBold.ReplaceAllStringFunc(s, func(m string) string {
+		return boldMark + m[2:len(m)-2] + boldMark
+	})
+	s = mdItalic.ReplaceAllString(s, "${1}_${2}_")
+	s = mdStrike.ReplaceAllString(s, "~$1~")
+	return s
+}
@@ -0,0 +1,35 @@
+package wa
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToWhatsApp(t *testing.T) {
+	for in, want := range map[string]string{
+		"**bold** and __also__":              "*bold* and *also*",
+		"*italic* text":                      "_italic_ text",
+		"~~gone~~":                           "~gone~",
+		"run `make test` now":                "run ```make test``` now",
+		"keep `**raw**` as is":               "keep ```**raw**``` as is",
+		"see [docs](https://x.example/a)":    "see docs (https://x.example/a)",
+		"## Notice ##":                       "*Notice*",
+		"* first\n* second":                  "- first\n- second",
+		"2 * 3 * 4":                          "2 * 3 * 4",
+		"```\n**not touched**\n```\n**yes**": "```\n**not touched**\n```\n*yes*",
+		"_already whatsapp_":                 "_already whatsapp_",
+	} {
+		if got := MarkdownToWhatsApp(in); got != want {
+			t.Fatalf("MarkdownToWhatsApp(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheckTextLength(t *testing.T) {
+	if err := CheckTextLength(strings.Repeat("é", MaxTextLength)); err != nil {
+		t.Fatalf("expected %d characters to be allowed: %v", MaxTextLength, err)
+	}
+	if err := CheckTextLength(strings.Repeat("a", MaxTextLength+1)); err == nil {
+		t.Fatalf("expected overlong text to be rejected")
+	}
+}
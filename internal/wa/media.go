@@ -33,7 +33,7 @@ func (c *Client) DownloadMediaToFile(ctx context.Context, directPath string, enc
 	cli := c.client
 	c.mu.Unlock()
 	if cli == nil || !cli.IsConnected() {
-		return 0, fmt.Errorf("not connected")
+		return 0, ErrNotConnected
 	}
 	if strings.TrimSpace(directPath) == "" {
 		return 0, fmt.Errorf("direct path is required")
@@ -19,6 +19,17 @@ type Media struct {
 	FileSHA256    []byte
 	FileEncSHA256 []byte
 	FileLength    uint64
+	Seconds       uint32 // audio/video duration
+	PTT           bool   // audio recorded as a voice note
+	Width         uint32 // image/video pixel size
+	Height        uint32
+}
+
+// LinkPreview is the preview card attached to an extended text message.
+type LinkPreview struct {
+	URL         string
+	Title       string
+	Description string
 }
 
 type ParsedMessage struct {
@@ -34,6 +45,26 @@ type ParsedMessage struct {
 	ReplyToDisplay string
 	ReactionToID   string
 	ReactionEmoji  string
+	Link           *LinkPreview
+	// Forwarded is set for messages forwarded from another chat;
+	// ForwardingScore counts how often the content has been forwarded.
+	Forwarded       bool
+	ForwardingScore uint32
+	// Expiration is the chat's disappearing-message timer (seconds) the
+	// message was sent with; 0 for messages that don't disappear.
+	Expiration uint32
+	// ViewOnce marks media that can be opened only once.
+	ViewOnce bool
+	// EditOfID is set when the message edits an earlier one; the parsed
+	// content is the new version.
+	EditOfID string
+	// EphemeralSetting is set when the message changes the chat's
+	// disappearing-message timer (seconds, 0 = off).
+	EphemeralSetting *uint32
+	// SenderAlt and ChatAlt carry the alternate (LID or phone-number) address
+	// WhatsApp sent alongside the sender and DM chat, when present.
+	SenderAlt types.JID
+	ChatAlt   types.JID
 }
 
 func ParseLiveMessage(evt *events.Message) ParsedMessage {
@@ -47,7 +78,17 @@ func ParseLiveMessage(evt *events.Message) ParsedMessage {
 	if s := evt.Info.Sender.String(); s != "" {
 		msg.SenderJID = s
 	}
+	msg.SenderAlt = evt.Info.SenderAlt
+	if !evt.Info.IsGroup {
+		if evt.Info.IsFromMe {
+			msg.ChatAlt = evt.Info.RecipientAlt
+		} else {
+			msg.ChatAlt = evt.Info.SenderAlt
+		}
+	}
 
+	// whatsmeow has already unwrapped the containers; keep what they said.
+	msg.ViewOnce = evt.IsViewOnce
 	extractWAProto(evt.Message, &msg)
 	return msg
 }
@@ -77,10 +118,56 @@ func ParseHistoryMessage(chatJID string, hist *waProto.WebMessageInfo) ParsedMes
 	return pm
 }
 
+// unwrapMessage strips the containers WhatsApp wraps message content in
+// (device-sent, ephemeral, view-once, document-with-caption, edit), noting
+// view-once on pm.
+func unwrapMessage(m *waProto.Message, pm *ParsedMessage) *waProto.Message {
+	for m != nil {
+		switch {
+		case m.GetDeviceSentMessage().GetMessage() != nil:
+			m = m.GetDeviceSentMessage().GetMessage()
+		case m.GetEphemeralMessage().GetMessage() != nil:
+			m = m.GetEphemeralMessage().GetMessage()
+		case m.GetViewOnceMessage().GetMessage() != nil:
+			m = m.GetViewOnceMessage().GetMessage()
+			pm.ViewOnce = true
+		case m.GetViewOnceMessageV2().GetMessage() != nil:
+			m = m.GetViewOnceMessageV2().GetMessage()
+			pm.ViewOnce = true
+		case m.GetViewOnceMessageV2Extension().GetMessage() != nil:
+			m = m.GetViewOnceMessageV2Extension().GetMessage()
+			pm.ViewOnce = true
+		case m.GetDocumentWithCaptionMessage().GetMessage() != nil:
+			m = m.GetDocumentWithCaptionMessage().GetMessage()
+		case m.GetEditedMessage().GetMessage() != nil:
+			m = m.GetEditedMessage().GetMessage()
+		default:
+			return m
+		}
+	}
+	return nil
+}
+
 func extractWAProto(m *waProto.Message, pm *ParsedMessage) {
 	if m == nil || pm == nil {
 		return
 	}
+	m = unwrapMessage(m, pm)
+
+	if pmsg := m.GetProtocolMessage(); pmsg != nil {
+		switch pmsg.GetType() {
+		case waProto.ProtocolMessage_MESSAGE_EDIT:
+			pm.EditOfID = pmsg.GetKey().GetID()
+			m = unwrapMessage(pmsg.GetEditedMessage(), pm)
+		case waProto.ProtocolMessage_EPHEMERAL_SETTING:
+			timer := pmsg.GetEphemeralExpiration()
+			pm.EphemeralSetting = &timer
+			return
+		}
+		if m == nil {
+			return
+		}
+	}
 
 	if reaction := m.GetReactionMessage(); reaction != nil {
 		pm.ReactionEmoji = reaction.GetText()
@@ -97,7 +184,15 @@ func extractWAProto(m *waProto.Message, pm *ParsedMessage) {
 	case m.GetConversation() != "":
 		pm.Text = m.GetConversation()
 	case m.GetExtendedTextMessage() != nil:
-		pm.Text = m.GetExtendedTextMessage().GetText()
+		ext := m.GetExtendedTextMessage()
+		pm.Text = ext.GetText()
+		if ext.GetTitle() != "" || ext.GetDescription() != "" {
+			pm.Link = &LinkPreview{
+				URL:         ext.GetMatchedText(),
+				Title:       ext.GetTitle(),
+				Description: ext.GetDescription(),
+			}
+		}
 	}
 
 	if img := m.GetImageMessage(); img != nil {
@@ -113,7 +208,10 @@ func extractWAProto(m *waProto.Message, pm *ParsedMessage) {
 			FileSHA256:    clone(img.GetFileSHA256()),
 			FileEncSHA256: clone(img.GetFileEncSHA256()),
 			FileLength:    img.GetFileLength(),
+			Width:         img.GetWidth(),
+			Height:        img.GetHeight(),
 		}
+		pm.ViewOnce = pm.ViewOnce || img.GetViewOnce()
 	}
 
 	if vid := m.GetVideoMessage(); vid != nil {
@@ -133,7 +231,11 @@ func extractWAProto(m *waProto.Message, pm *ParsedMessage) {
 			FileSHA256:    clone(vid.GetFileSHA256()),
 			FileEncSHA256: clone(vid.GetFileEncSHA256()),
 			FileLength:    vid.GetFileLength(),
+			Seconds:       vid.GetSeconds(),
+			Width:         vid.GetWidth(),
+			Height:        vid.GetHeight(),
 		}
+		pm.ViewOnce = pm.ViewOnce || vid.GetViewOnce()
 	}
 
 	if aud := m.GetAudioMessage(); aud != nil {
@@ -149,7 +251,10 @@ func extractWAProto(m *waProto.Message, pm *ParsedMessage) {
 			FileSHA256:    clone(aud.GetFileSHA256()),
 			FileEncSHA256: clone(aud.GetFileEncSHA256()),
 			FileLength:    aud.GetFileLength(),
+			Seconds:       aud.GetSeconds(),
+			PTT:           aud.GetPTT(),
 		}
+		pm.ViewOnce = pm.ViewOnce || aud.GetViewOnce()
 	}
 
 	if doc := m.GetDocumentMessage(); doc != nil {
@@ -178,6 +283,8 @@ func extractWAProto(m *waProto.Message, pm *ParsedMessage) {
 			FileSHA256:    clone(sticker.GetFileSHA256()),
 			FileEncSHA256: clone(sticker.GetFileEncSHA256()),
 			FileLength:    sticker.GetFileLength(),
+			Width:         sticker.GetWidth(),
+			Height:        sticker.GetHeight(),
 		}
 	}
 
@@ -188,6 +295,9 @@ func extractWAProto(m *waProto.Message, pm *ParsedMessage) {
 		if quoted := ctx.GetQuotedMessage(); quoted != nil {
 			pm.ReplyToDisplay = strings.TrimSpace(displayTextForProto(quoted))
 		}
+		pm.Forwarded = ctx.GetIsForwarded()
+		pm.ForwardingScore = ctx.GetForwardingScore()
+		pm.Expiration = ctx.GetExpiration()
 	}
 }
 
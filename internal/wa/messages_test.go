@@ -140,3 +140,121 @@ func TestParseLiveMessageReply(t *testing.T) {
 		t.Fatalf("expected ReplyToDisplay to be quoted, got %q", pm.ReplyToDisplay)
 	}
 }
+
+func TestParseHistoryMessageVoiceNote(t *testing.T) {
+	h := &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("voice1"), FromMe: proto.Bool(true)},
+		Message: &waProto.Message{AudioMessage: &waProto.AudioMessage{
+			Mimetype: proto.String("audio/ogg; codecs=opus"),
+			Seconds:  proto.Uint32(7),
+			PTT:      proto.Bool(true),
+		}},
+	}
+	pm := ParseHistoryMessage("123@s.whatsapp.net", h)
+	if pm.Media == nil || pm.Media.Type != "audio" || pm.Media.Seconds != 7 || !pm.Media.PTT {
+		t.Fatalf("unexpected parsed voice note: %+v", pm.Media)
+	}
+}
+
+func TestParseHistoryMessageVideoDimensions(t *testing.T) {
+	h := &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("vid1"), FromMe: proto.Bool(true)},
+		Message: &waProto.Message{VideoMessage: &waProto.VideoMessage{
+			Mimetype: proto.String("video/mp4"),
+			Seconds:  proto.Uint32(12),
+			Width:    proto.Uint32(1920),
+			Height:   proto.Uint32(1080),
+		}},
+	}
+	pm := ParseHistoryMessage("123@s.whatsapp.net", h)
+	if pm.Media == nil || pm.Media.Seconds != 12 || pm.Media.Width != 1920 || pm.Media.Height != 1080 {
+		t.Fatalf("unexpected parsed video: %+v", pm.Media)
+	}
+}
+
+func TestParseHistoryMessageLinkPreview(t *testing.T) {
+	h := &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("link1")},
+		Message: &waProto.Message{ExtendedTextMessage: &waProto.ExtendedTextMessage{
+			Text:        proto.String("read this https://example.com/a"),
+			MatchedText: proto.String("https://example.com/a"),
+			Title:       proto.String("A title"),
+			Description: proto.String("A description"),
+		}},
+	}
+	pm := ParseHistoryMessage("123@s.whatsapp.net", h)
+	if pm.Link == nil || pm.Link.URL != "https://example.com/a" || pm.Link.Title != "A title" || pm.Link.Description != "A description" {
+		t.Fatalf("unexpected link preview: %+v", pm.Link)
+	}
+}
+
+func TestParseHistoryMessageForwarded(t *testing.T) {
+	h := &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("fwd1")},
+		Message: &waProto.Message{ExtendedTextMessage: &waProto.ExtendedTextMessage{
+			Text:        proto.String("chain letter"),
+			ContextInfo: &waProto.ContextInfo{IsForwarded: proto.Bool(true), ForwardingScore: proto.Uint32(6)},
+		}},
+	}
+	pm := ParseHistoryMessage("123@s.whatsapp.net", h)
+	if !pm.Forwarded || pm.ForwardingScore != 6 {
+		t.Fatalf("expected forwarded message with score 6, got forwarded=%v score=%d", pm.Forwarded, pm.ForwardingScore)
+	}
+}
+
+func TestParseHistoryMessageUnwrapsContainers(t *testing.T) {
+	h := &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("vo1")},
+		Message: &waProto.Message{EphemeralMessage: &waProto.FutureProofMessage{Message: &waProto.Message{
+			ViewOnceMessageV2: &waProto.FutureProofMessage{Message: &waProto.Message{
+				ImageMessage: &waProto.ImageMessage{
+					Caption:     proto.String("just once"),
+					DirectPath:  proto.String("/v/once"),
+					ContextInfo: &waProto.ContextInfo{Expiration: proto.Uint32(604800)},
+				},
+			}},
+		}}},
+	}
+	pm := ParseHistoryMessage("123@s.whatsapp.net", h)
+	if pm.Media == nil || pm.Media.Type != "image" || pm.Text != "just once" {
+		t.Fatalf("expected unwrapped image, got %+v", pm)
+	}
+	if !pm.ViewOnce || pm.Expiration != 604800 {
+		t.Fatalf("expected view-once with 7d expiration, got viewOnce=%v expiration=%d", pm.ViewOnce, pm.Expiration)
+	}
+
+	doc := ParseHistoryMessage("123@s.whatsapp.net", &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("doc1")},
+		Message: &waProto.Message{DocumentWithCaptionMessage: &waProto.FutureProofMessage{Message: &waProto.Message{
+			DocumentMessage: &waProto.DocumentMessage{FileName: proto.String("a.pdf"), Caption: proto.String("the contract")},
+		}}},
+	})
+	if doc.Media == nil || doc.Media.Filename != "a.pdf" || doc.Text != "the contract" {
+		t.Fatalf("expected unwrapped document, got %+v", doc)
+	}
+
+	edit := ParseHistoryMessage("123@s.whatsapp.net", &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("edit1")},
+		Message: &waProto.Message{EditedMessage: &waProto.FutureProofMessage{Message: &waProto.Message{
+			ProtocolMessage: &waProto.ProtocolMessage{
+				Type:          waProto.ProtocolMessage_MESSAGE_EDIT.Enum(),
+				Key:           &waProto.MessageKey{ID: proto.String("orig1")},
+				EditedMessage: &waProto.Message{Conversation: proto.String("fixed typo")},
+			},
+		}}},
+	})
+	if edit.EditOfID != "orig1" || edit.Text != "fixed typo" {
+		t.Fatalf("expected edit of orig1, got %+v", edit)
+	}
+
+	setting := ParseHistoryMessage("123@s.whatsapp.net", &waProto.WebMessageInfo{
+		Key: &waProto.MessageKey{ID: proto.String("eph1")},
+		Message: &waProto.Message{ProtocolMessage: &waProto.ProtocolMessage{
+			Type:                waProto.ProtocolMessage_EPHEMERAL_SETTING.Enum(),
+			EphemeralExpiration: proto.Uint32(86400),
+		}},
+	})
+	if setting.EphemeralSetting == nil || *setting.EphemeralSetting != 86400 {
+		t.Fatalf("expected ephemeral setting of 24h, got %+v", setting.EphemeralSetting)
+	}
+}